@@ -19,10 +19,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -30,6 +33,7 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
@@ -37,6 +41,7 @@ import (
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/stagepodautoscaler"
 
 	netcfg "knative.dev/networking/pkg/config"
+	endpointsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints"
 	filteredpodinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/filtered"
 	filteredinformerfactory "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
 	configmap "knative.dev/pkg/configmap/informer"
@@ -49,6 +54,7 @@ import (
 	"knative.dev/pkg/signals"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/version"
+	"knative.dev/serving-progressive-rollout/pkg/autoscaler/revisionstats"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/autoscaling/kpa"
 	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
@@ -65,10 +71,19 @@ import (
 )
 
 const (
-	statsServerAddr = ":8080"
-	statsBufferLen  = 1000
-	component       = "autoscaler"
-	controllerNum   = 2
+	statsServerAddr         = ":8080"
+	revisionStatsServerPort = "8090"
+	statsBufferLen          = 1000
+	component               = "autoscaler"
+	controllerNum           = 2
+
+	// controllerServiceAccount is the service account of the controller, allowed to read the revision stats. The
+	// autoscaler runs with the same service account, so the autoscalers are allowed to read the stats as well.
+	controllerServiceAccount = "controller"
+
+	// revisionStatsServiceName is the name of the service of the revision stats, whose DNS name in the system
+	// namespace the certificate of the revision stats is issued for.
+	revisionStatsServiceName = "autoscaler-revision-stats"
 )
 
 func main() {
@@ -168,9 +183,13 @@ func main() {
 		logger.Fatalw("Failed to start informers", zap.Error(err))
 	}
 
+	// revisionStats keeps the stats of the revisions owned by this pod, served to the rollout controller.
+	revisionStats := revisionstats.NewStore()
+
 	// accept is the func to call when this pod owns the Revision for this StatMessage.
 	accept := func(sm asmetrics.StatMessage) {
 		collector.Record(sm.Key, time.Unix(sm.Stat.Timestamp, 0), sm.Stat)
+		revisionStats.Record(sm.Key, time.Unix(sm.Stat.Timestamp, 0), sm.Stat)
 		multiScaler.Poke(sm.Key, sm.Stat)
 	}
 
@@ -181,6 +200,8 @@ func main() {
 	var electorCtx context.Context
 
 	var f *statforwarder.Forwarder
+	// revisionStatsOwner finds the autoscaler owning the stats of a revision, the same way as the stats are forwarded.
+	var revisionStatsOwner revisionstats.OwnerFunc
 	if b, bs, err := leaderelection.NewStatefulSetBucketAndSet(int(cc.Buckets)); err == nil {
 		logger.Info("Running with StatefulSet leader election")
 		electorCtx = leaderelection.WithStatefulSetElectorBuilder(ctx, cc, b)
@@ -188,13 +209,17 @@ func main() {
 		if err := statforwarder.StatefulSetBasedProcessor(ctx, f, accept); err != nil {
 			logger.Fatalw("Failed to set up statefulset processors", zap.Error(err))
 		}
+		revisionStatsOwner = revisionstats.StatefulSetOwner(bs, f.IsBucketOwner, revisionStatsServerPort)
 	} else {
 		logger.Info("Running with Standard leader election")
 		electorCtx = leaderelection.WithStandardLeaderElectorBuilder(ctx, kubeClient, cc)
-		f = statforwarder.New(ctx, bucket.AutoscalerBucketSet(cc.Buckets))
+		bs := bucket.AutoscalerBucketSet(cc.Buckets)
+		f = statforwarder.New(ctx, bs)
 		if err := statforwarder.LeaseBasedProcessor(ctx, f, accept); err != nil {
 			logger.Fatalw("Failed to set up lease tracking", zap.Error(err))
 		}
+		revisionStatsOwner = revisionstats.LeaseOwner(bs, f.IsBucketOwner,
+			endpointsinformer.Get(ctx).Lister().Endpoints(system.Namespace()), revisionStatsServerPort)
 	}
 
 	elector, err := setupSharedElector(electorCtx, controllers)
@@ -206,6 +231,27 @@ func main() {
 	statsServer := statserver.New(statsServerAddr, statsCh, logger, f.IsBucketOwner)
 	defer f.Cancel()
 
+	// Set up the server of the revision stats.
+	revisionStatsHandler := revisionstats.NewHandler(revisionStats, kubeClient,
+		sets.New(fmt.Sprintf("system:serviceaccount:%s:%s", system.Namespace(), controllerServiceAccount)),
+		logger.Named("revisionstats"))
+	revisionStatsHandler.SetOwner(revisionStatsOwner)
+	revisionStatsTransport, err := newRevisionStatsTransport(os.Getenv(revisionstats.TLSCAFileEnvKey))
+	if err != nil {
+		logger.Fatalw("Failed to set up the transport of the revision stats", zap.Error(err))
+	}
+	revisionStatsHandler.SetTransport(revisionStatsTransport)
+	revisionStatsServer := &http.Server{
+		Addr:              ":" + revisionStatsServerPort,
+		Handler:           revisionStatsHandler,
+		ReadHeaderTimeout: time.Minute,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	certFile, keyFile := os.Getenv(revisionstats.TLSCertFileEnvKey), os.Getenv(revisionstats.TLSKeyFileEnvKey)
+	go wait.Until(func() {
+		revisionStats.Prune(time.Now())
+	}, time.Minute, ctx.Done())
+
 	go func() {
 		for sm := range statsCh {
 			// Set the timestamp when first receiving the stat.
@@ -222,6 +268,14 @@ func main() {
 		return nil
 	})
 	eg.Go(statsServer.ListenAndServe)
+	if certFile != "" && keyFile != "" {
+		eg.Go(func() error {
+			return revisionStatsServer.ListenAndServeTLS(certFile, keyFile)
+		})
+	} else {
+		logger.Warn("The revision stats are not served, because the certificate and the key to serve them over TLS " +
+			"are not set")
+	}
 	eg.Go(pprof.ListenAndServe)
 	eg.Go(func() error {
 		return controller.StartAll(egCtx, controllers...)
@@ -232,6 +286,7 @@ func main() {
 	<-egCtx.Done()
 
 	statsServer.Shutdown(5 * time.Second)
+	revisionStatsServer.Shutdown(context.Background())
 	pprof.Shutdown(context.Background())
 	// Don't forward ErrServerClosed as that indicates we're already shutting down.
 	if err := eg.Wait(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -239,6 +294,29 @@ func main() {
 	}
 }
 
+// newRevisionStatsTransport returns the transport to forward the requests for the revision stats to the other
+// autoscalers at their pod IPs. They serve the same certificate, issued for the service of the revision stats, which
+// is verified with the CA in caFile.
+func newRevisionStatsTransport(caFile string) (http.RoundTripper, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: fmt.Sprintf("%s.%s.svc", revisionStatsServiceName, system.Namespace()),
+	}
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no CA certificate was found in %s", caFile)
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
 func uniScalerFactoryFunc(
 	mp metric.MeterProvider,
 	podLister corev1listers.PodLister,
@@ -22,6 +22,8 @@ import (
 
 	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/signals"
+	"knative.dev/serving-progressive-rollout/pkg/adminapi"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutgroup"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator"
 	"knative.dev/serving/pkg/reconciler/configuration"
 	"knative.dev/serving/pkg/reconciler/gc"
@@ -52,10 +54,13 @@ var ctors = []injection.ControllerConstructor{
 	route.NewController,
 	serverlessservice.NewController,
 	service.NewController,
+	service.NewRouteController,
 	gc.NewController,
 	nscert.NewController,
 	domainmapping.NewController,
 	rolloutorchestrator.NewController,
+	rolloutgroup.NewController,
+	adminapi.NewController,
 }
 
 func main() {
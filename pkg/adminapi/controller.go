@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adminapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	configmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/client/injection/client"
+	roinformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/rolloutorchestrator"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	routeinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/route"
+	kserviceinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/service"
+)
+
+const (
+	// AddressEnvKey is the environment variable of the controller, that sets the address the admin API listens
+	// on. The admin API is disabled, if it is empty.
+	AddressEnvKey = "ADMIN_API_ADDRESS"
+
+	// TLSCertFileEnvKey and TLSKeyFileEnvKey are the environment variables of the controller, that set the files of
+	// the certificate and the key the admin API is served with over TLS. Without them, the admin API only listens
+	// on the loopback interface, since the bearer tokens of the requests must not be sent in plain text.
+	TLSCertFileEnvKey = "ADMIN_API_TLS_CERT_FILE"
+	TLSKeyFileEnvKey  = "ADMIN_API_TLS_KEY_FILE"
+
+	// leaderKey is the key, whose bucket is led by the controller serving the admin API.
+	leaderKey = "admin-api"
+)
+
+// reconciler does not reconcile anything. It only follows the leader election of the admin API.
+type reconciler struct {
+	pkgreconciler.LeaderAwareFuncs
+}
+
+// Reconcile implements controller.Reconciler.
+func (r *reconciler) Reconcile(context.Context, string) error {
+	return nil
+}
+
+// NewController starts the admin API server, if the address is set in AddressEnvKey, and returns the controller,
+// that lets it serve the requests, while this controller leads the bucket of the admin API.
+func NewController(
+	ctx context.Context,
+	_ configmap.Watcher,
+) *controller.Impl {
+	logger := logging.FromContext(ctx)
+	roInformer := roinformer.Get(ctx)
+	serviceInformer := kserviceinformer.Get(ctx)
+	routeInformer := routeinformer.Get(ctx)
+	configmapInformer := configmapinformer.Get(ctx)
+
+	configFunc := func(ro *v1.RolloutOrchestrator) (*service.RolloutConfig, error) {
+		cm, err := configmapInformer.Lister().ConfigMaps(system.Namespace()).Get(resources.ConfigMapName)
+		if err != nil && !apierrs.IsNotFound(err) {
+			return nil, err
+		}
+		cmN, err := configmapInformer.Lister().ConfigMaps(system.Namespace()).Get(resources.ConfigMapNetworkName)
+		if err != nil && !apierrs.IsNotFound(err) {
+			return nil, err
+		}
+		config, err := service.NewConfigFromConfigMapFunc(cm, cmN)
+		if err != nil {
+			return nil, err
+		}
+		// The RolloutOrchestrator has the same name as its service, or its standalone route.
+		if ksvc, err := serviceInformer.Lister().Services(ro.Namespace).Get(ro.Name); err == nil {
+			service.LoadConfigFromService(ksvc.Spec.Template.Annotations, ksvc.Annotations, config)
+		} else if route, err := routeInformer.Lister().Routes(ro.Namespace).Get(ro.Name); err == nil {
+			service.LoadConfigFromService(route.Annotations, route.Annotations, config)
+		}
+		return config, nil
+	}
+	handler := NewHandler(roInformer.Lister(), client.Get(ctx), kubeclient.Get(ctx), configFunc,
+		logger.Named("adminapi"))
+
+	key := types.NamespacedName{Name: leaderKey}
+	r := &reconciler{
+		LeaderAwareFuncs: pkgreconciler.LeaderAwareFuncs{
+			PromoteFunc: func(bkt pkgreconciler.Bucket, _ func(pkgreconciler.Bucket, types.NamespacedName)) error {
+				if bkt.Has(key) {
+					handler.SetLeader(true)
+				}
+				return nil
+			},
+			DemoteFunc: func(bkt pkgreconciler.Bucket) {
+				if bkt.Has(key) {
+					handler.SetLeader(false)
+				}
+			},
+		},
+	}
+	impl := controller.NewContext(ctx, r, controller.ControllerOptions{WorkQueueName: "AdminAPI", Logger: logger})
+
+	if address := os.Getenv(AddressEnvKey); address != "" {
+		certFile, keyFile := os.Getenv(TLSCertFileEnvKey), os.Getenv(TLSKeyFileEnvKey)
+		tlsEnabled := certFile != "" && keyFile != ""
+		if !tlsEnabled && !isLoopback(address) {
+			logger.Errorf("The admin API is not served at %s, because it is neither served over TLS nor "+
+				"on the loopback interface", address)
+			return impl
+		}
+		server := &http.Server{
+			Addr:              address,
+			Handler:           handler,
+			ReadHeaderTimeout: time.Minute,
+			TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+		}
+		go func() {
+			logger.Infof("Serving the admin API at %s, with TLS %v", address, tlsEnabled)
+			var err error
+			if tlsEnabled {
+				err = server.ListenAndServeTLS(certFile, keyFile)
+			} else {
+				err = server.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Errorw("Failed to serve the admin API", zap.Error(err))
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			server.Shutdown(context.Background())
+		}()
+	}
+	return impl
+}
+
+// isLoopback returns true, if the address only listens on the loopback interface.
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
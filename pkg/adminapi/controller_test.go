@@ -0,0 +1,59 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adminapi
+
+import "testing"
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		name           string
+		address        string
+		ExpectedResult bool
+	}{{
+		name:           "Test the loopback IPv4 address",
+		address:        "127.0.0.1:8090",
+		ExpectedResult: true,
+	}, {
+		name:           "Test the loopback IPv6 address",
+		address:        "[::1]:8090",
+		ExpectedResult: true,
+	}, {
+		name:           "Test the localhost",
+		address:        "localhost:8090",
+		ExpectedResult: true,
+	}, {
+		name:           "Test all the interfaces",
+		address:        ":8090",
+		ExpectedResult: false,
+	}, {
+		name:           "Test the pod IP",
+		address:        "10.0.0.2:8090",
+		ExpectedResult: false,
+	}, {
+		name:           "Test the invalid address",
+		address:        "8090",
+		ExpectedResult: false,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := isLoopback(test.address)
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of isLoopback() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,464 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	authv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	clientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned"
+	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator/strategies"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+)
+
+const (
+	// Path is the path prefix of the rollouts served by the Handler.
+	Path = "/rollouts"
+
+	// tokenReviewTTL is how long the user of a successfully reviewed token is cached.
+	tokenReviewTTL = time.Minute
+
+	// resourceName is the resource the users are authorized for.
+	resourceName = "rolloutorchestrators"
+)
+
+var (
+	errTokenReview     = errors.New("failed to review the token")
+	errUnauthenticated = errors.New("the token is not authenticated")
+	errAccessReview    = errors.New("failed to review the access")
+	errForbidden       = errors.New("the user is not allowed to access the rollouts")
+	errNotLeader       = errors.New("this controller is not the leader, retry with another replica")
+	errNotInProgress   = errors.New("the rollout is not in progress")
+	errNotOnStandby    = errors.New("the initial revisions of the rollout are not on standby")
+)
+
+// Rollout is the summary of an active rollout.
+type Rollout struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// Stage is the traffic split of the current stage.
+	Stage []v1.TargetRevision `json:"stage"`
+
+	// StageFinishTime is the deadline of the current stage.
+	StageFinishTime *metav1.Time `json:"stageFinishTime,omitempty"`
+
+	// ETA is the estimated time the last stage completes.
+	ETA *metav1.Time `json:"eta,omitempty"`
+
+	// PausedBy and PromotedBy are the users who paused or promoted the rollout. Aborted and Reverted are set,
+	// when the rollout has been aborted or reverted.
+	PausedBy   string `json:"pausedBy,omitempty"`
+	PromotedBy string `json:"promotedBy,omitempty"`
+	Aborted    bool   `json:"aborted,omitempty"`
+	Reverted   bool   `json:"reverted,omitempty"`
+
+	// PromotedToFinalBy and PromotedToFinalTime are the user who promoted the rollout to its final stage, and
+	// the time the promotion was accepted. The time is not set, while the promotion is not accepted yet.
+	PromotedToFinalBy   string       `json:"promotedToFinalBy,omitempty"`
+	PromotedToFinalTime *metav1.Time `json:"promotedToFinalTime,omitempty"`
+}
+
+// Plan is the plan of an active rollout.
+type Plan struct {
+	Rollout `json:",inline"`
+
+	// InitialRevisions and TargetRevisions are the traffic splits the rollout starts from and ends with.
+	InitialRevisions []v1.TargetRevision `json:"initialRevisions"`
+	TargetRevisions  []v1.TargetRevision `json:"targetRevisions"`
+
+	// RemainingStages are the estimated percentages of the traffic of the target revision in the stages after
+	// the current one. They are only estimated for the rollout from one revision to another.
+	RemainingStages []int64 `json:"remainingStages,omitempty"`
+}
+
+// ConfigFunc returns the RolloutConfig, that applies to the RolloutOrchestrator.
+type ConfigFunc func(ro *v1.RolloutOrchestrator) (*service.RolloutConfig, error)
+
+// Handler serves the active rollouts at Path, and steers them by setting the annotations of the
+// RolloutOrchestrators honored by the service reconciler. The requests are authenticated with the bearer tokens
+// reviewed by the Kubernetes API server, and authorized with the SubjectAccessReviews for the
+// RolloutOrchestrators. Only the leader serves the requests.
+type Handler struct {
+	roLister   listers.RolloutOrchestratorLister
+	client     clientset.Interface
+	kubeClient kubernetes.Interface
+	config     ConfigFunc
+	logger     *zap.SugaredLogger
+	leader     atomic.Bool
+
+	// now returns the current time, replaced in the tests.
+	now func() time.Time
+
+	mu    sync.Mutex
+	users map[string]cachedUser
+}
+
+type cachedUser struct {
+	user   authv1.UserInfo
+	expiry time.Time
+}
+
+// NewHandler creates a Handler serving the rollouts of the RolloutOrchestrators in the lister.
+func NewHandler(roLister listers.RolloutOrchestratorLister, client clientset.Interface,
+	kubeClient kubernetes.Interface, config ConfigFunc, logger *zap.SugaredLogger) *Handler {
+	return &Handler{
+		roLister:   roLister,
+		client:     client,
+		kubeClient: kubeClient,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+		users:      make(map[string]cachedUser),
+	}
+}
+
+// SetLeader sets whether this controller is the leader serving the requests.
+func (h *Handler) SetLeader(leader bool) {
+	h.leader.Store(leader)
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.leader.Load() {
+		http.Error(w, errNotLeader.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// The path is either Path, Path/{namespace}/{name}/plan, or Path/{namespace}/{name}/{action}.
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, Path), "/")
+	if !strings.HasPrefix(r.URL.Path, Path) || (len(parts) != 1 && len(parts) != 4) || parts[0] != "" {
+		http.NotFound(w, r)
+		return
+	}
+	var namespace, name, action string
+	if len(parts) == 4 {
+		namespace, name, action = parts[1], parts[2], parts[3]
+		if namespace == "" || name == "" {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	verb, method := "list", http.MethodGet
+	switch action {
+	case "":
+	case "plan":
+		verb = "get"
+	case "promote", "promote-to-final", "pause", "resume", "abort", "revert":
+		verb, method = "patch", http.MethodPost
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != method {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	user, status, err := h.authorize(r.Context(), token, namespace, verb)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	switch action {
+	case "":
+		h.serveList(w)
+	case "plan":
+		h.servePlan(w, r, namespace, name)
+	default:
+		h.serveAction(w, r, namespace, name, action, user.Username)
+	}
+}
+
+func (h *Handler) serveList(w http.ResponseWriter) {
+	ros, err := h.roLister.List(labels.Everything())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rollouts := make([]Rollout, 0, len(ros))
+	for _, ro := range ros {
+		if !isInProgress(ro) {
+			continue
+		}
+		plan, err := h.plan(ro)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rollouts = append(rollouts, plan.Rollout)
+	}
+	h.writeJSON(w, rollouts)
+}
+
+func (h *Handler) servePlan(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	ro, err := h.roLister.RolloutOrchestrators(namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plan, err := h.plan(ro)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, plan)
+}
+
+// serveAction patches the annotation of the RolloutOrchestrator, that the service reconciler honors for the action.
+func (h *Handler) serveAction(w http.ResponseWriter, r *http.Request, namespace, name, action, username string) {
+	ro, err := h.roLister.RolloutOrchestrators(namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// The completed rollout can only be reverted, while its initial revisions are on standby.
+	if action == "revert" && !isInProgress(ro) && !isOnStandby(ro) {
+		http.Error(w, errNotOnStandby.Error(), http.StatusConflict)
+		return
+	}
+	if action != "resume" && action != "revert" && !isInProgress(ro) {
+		http.Error(w, errNotInProgress.Error(), http.StatusConflict)
+		return
+	}
+
+	var annotations map[string]interface{}
+	switch action {
+	case "promote":
+		annotations = map[string]interface{}{resources.RolloutPromoted: username}
+	case "promote-to-final":
+		annotations = map[string]interface{}{resources.RolloutPromotedToFinal: username}
+	case "pause":
+		annotations = map[string]interface{}{resources.RolloutPaused: username}
+	case "resume":
+		annotations = map[string]interface{}{resources.RolloutPaused: nil}
+	case "abort":
+		annotations = map[string]interface{}{resources.RolloutAborted: resources.TargetRevisionNames(ro)}
+	case "revert":
+		annotations = map[string]interface{}{resources.RolloutReverted: resources.TargetRevisionNames(ro)}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	patched, err := h.client.ServingV1().RolloutOrchestrators(namespace).Patch(r.Context(), name,
+		types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		h.logger.Errorw("Failed to patch the RolloutOrchestrator", zap.String("action", action), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.logger.Infow("Steered the rollout", zap.String("namespace", namespace), zap.String("name", name),
+		zap.String("action", action), zap.String("user", username))
+	plan, err := h.plan(patched)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, plan)
+}
+
+// plan estimates the remaining stages of the rollout of the RolloutOrchestrator, and when the last of them
+// completes, assuming every stage takes the stage timeout.
+func (h *Handler) plan(ro *v1.RolloutOrchestrator) (*Plan, error) {
+	plan := &Plan{
+		Rollout: Rollout{
+			Namespace:  ro.Namespace,
+			Name:       ro.Name,
+			Stage:      ro.Spec.StageTargetRevisions,
+			PausedBy:   ro.Annotations[resources.RolloutPaused],
+			PromotedBy: ro.Annotations[resources.RolloutPromoted],
+			Aborted:    resources.IsRolloutAborted(ro),
+			Reverted:   resources.IsRolloutReverted(ro),
+		},
+		InitialRevisions: ro.Spec.InitialRevisions,
+		TargetRevisions:  ro.Spec.TargetRevisions,
+	}
+	if ro.Spec.Promotion != nil {
+		plan.PromotedToFinalBy = ro.Spec.Promotion.PromotedBy
+		plan.PromotedToFinalTime = ro.Spec.Promotion.PromotedTime.DeepCopy()
+	} else if promotedBy, found := ro.Annotations[resources.RolloutPromotedToFinal]; found {
+		plan.PromotedToFinalBy = promotedBy
+	}
+	if !ro.Spec.TargetFinishTime.Inner.IsZero() {
+		plan.StageFinishTime = ro.Spec.TargetFinishTime.Inner.DeepCopy()
+	}
+	if !isInProgress(ro) || plan.Aborted || plan.Reverted || len(ro.Spec.TargetRevisions) != 1 {
+		return plan, nil
+	}
+
+	config, err := h.config(ro)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the configuration of the rollout: %w", err)
+	}
+	ratio := int64(config.GetStageRatio(ro))
+	if plan.PromotedToFinalBy != "" {
+		// The rollout promoted to its final stage moves all the remaining traffic at once.
+		ratio = 100
+	}
+	plan.RemainingStages = remainingStages(currentPercent(ro), ratio)
+	if plan.StageFinishTime != nil {
+		eta := plan.StageFinishTime.Add(time.Duration(len(plan.RemainingStages)) * config.GetStageRolloutTimeout(ro))
+		plan.ETA = &metav1.Time{Time: eta}
+	}
+	return plan, nil
+}
+
+// currentPercent returns the percentage of the traffic of the target revision in the current stage.
+func currentPercent(ro *v1.RolloutOrchestrator) int64 {
+	for _, rev := range ro.Spec.StageTargetRevisions {
+		if rev.RevisionName == ro.Spec.TargetRevisions[0].RevisionName && rev.Percent != nil {
+			return *rev.Percent
+		}
+	}
+	return 0
+}
+
+// remainingStages returns the percentages of the traffic of the target revision in the stages after the current
+// one, shifting ratio percent of the traffic in each stage.
+func remainingStages(current, ratio int64) []int64 {
+	if current >= 100 || ratio <= 0 {
+		return nil
+	}
+	stages := make([]int64, 0, int(math.Ceil(float64(100-current)/float64(ratio))))
+	for percent := current + ratio; percent < 100; percent += ratio {
+		stages = append(stages, percent)
+	}
+	return append(stages, 100)
+}
+
+// isInProgress returns true, if the RolloutOrchestrator has not reached its last stage.
+func isInProgress(ro *v1.RolloutOrchestrator) bool {
+	return len(ro.Spec.StageTargetRevisions) != 0 &&
+		!rolloutorchestrator.LastStageComplete(ro.Spec.StageTargetRevisions, ro.Spec.TargetRevisions)
+}
+
+// isOnStandby returns true, if the last stage of the RolloutOrchestrator is complete, and its initial revisions
+// are still kept on standby.
+func isOnStandby(ro *v1.RolloutOrchestrator) bool {
+	return ro.IsLastStageComplete() && len(ro.Spec.InitialRevisions) != 0 && strategies.StandbyReplicas(ro) > 0
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Errorw("Failed to write the rollouts", zap.Error(err))
+	}
+}
+
+// authorize reviews the token, and checks whether its user is allowed to apply the verb to the
+// RolloutOrchestrators in the namespace. It returns the HTTP status to respond with the error.
+func (h *Handler) authorize(ctx context.Context, token, namespace, verb string) (authv1.UserInfo, int, error) {
+	user, status, err := h.authenticate(ctx, token)
+	if err != nil {
+		return user, status, err
+	}
+
+	extra := make(map[string]authzv1.ExtraValue, len(user.Extra))
+	for key, val := range user.Extra {
+		extra[key] = authzv1.ExtraValue(val)
+	}
+	review, err := h.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authzv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     v1.SchemeGroupVersion.Group,
+				Resource:  resourceName,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		h.logger.Errorw("Failed to review the access", zap.Error(err))
+		return user, http.StatusInternalServerError, errAccessReview
+	}
+	if !review.Status.Allowed {
+		return user, http.StatusForbidden, errForbidden
+	}
+	return user, http.StatusOK, nil
+}
+
+// authenticate reviews the token, and returns its user.
+func (h *Handler) authenticate(ctx context.Context, token string) (authv1.UserInfo, int, error) {
+	now := h.now()
+	h.mu.Lock()
+	cached, ok := h.users[token]
+	h.mu.Unlock()
+	if ok && now.Before(cached.expiry) {
+		return cached.user, http.StatusOK, nil
+	}
+
+	review, err := h.kubeClient.AuthenticationV1().TokenReviews().Create(ctx,
+		&authv1.TokenReview{Spec: authv1.TokenReviewSpec{Token: token}}, metav1.CreateOptions{})
+	if err != nil {
+		h.logger.Errorw("Failed to review the token", zap.Error(err))
+		return authv1.UserInfo{}, http.StatusInternalServerError, errTokenReview
+	}
+	if !review.Status.Authenticated {
+		return authv1.UserInfo{}, http.StatusUnauthorized, errUnauthenticated
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for cachedToken, cached := range h.users {
+		if !now.Before(cached.expiry) {
+			delete(h.users, cachedToken)
+		}
+	}
+	h.users[token] = cachedUser{user: review.Status.User, expiry: now.Add(tokenReviewTTL)}
+	return review.Status.User, http.StatusOK, nil
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adminapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	fakeclient "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned/fake"
+	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+var finishTime = metav1.NewTime(time.Unix(10000, 0))
+
+func newRO(name string, percent int64) *v1.RolloutOrchestrator {
+	return &v1.RolloutOrchestrator{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: name + "-00001", Percent: ptr.Int64(100)},
+			}},
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: name + "-00002", Percent: ptr.Int64(100)},
+			}},
+			StageTarget: v1.StageTarget{
+				StageTargetRevisions: []v1.TargetRevision{{
+					TrafficTarget: servingv1.TrafficTarget{RevisionName: name + "-00001",
+						Percent: ptr.Int64(100 - percent)},
+				}, {
+					TrafficTarget: servingv1.TrafficTarget{RevisionName: name + "-00002", Percent: ptr.Int64(percent)},
+				}},
+				TargetFinishTime: apis.VolatileTime{Inner: finishTime},
+			},
+		},
+	}
+}
+
+func newTestHandler(t *testing.T, ros ...*v1.RolloutOrchestrator) (*Handler, *fakeclient.Clientset) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	objects := make([]runtime.Object, 0, len(ros))
+	for _, ro := range ros {
+		indexer.Add(ro)
+		objects = append(objects, ro)
+	}
+	client := fakeclient.NewSimpleClientset(objects...)
+
+	users := map[string]string{"admin-token": "admin", "viewer-token": "viewer"}
+	kubeClient := fakekubeclient.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authv1.TokenReview)
+		if user, ok := users[review.Spec.Token]; ok {
+			review.Status.Authenticated = true
+			review.Status.User.Username = user
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews",
+		func(action ktesting.Action) (bool, runtime.Object, error) {
+			review := action.(ktesting.CreateAction).GetObject().(*authzv1.SubjectAccessReview)
+			review.Status.Allowed = review.Spec.User == "admin" || review.Spec.ResourceAttributes.Verb != "patch"
+			return true, review, nil
+		})
+
+	config := &service.RolloutConfig{OverConsumptionRatio: 30, StageRolloutTimeoutMinutes: 2}
+	handler := NewHandler(listers.NewRolloutOrchestratorLister(indexer), client, kubeClient,
+		func(*v1.RolloutOrchestrator) (*service.RolloutConfig, error) { return config, nil },
+		logtesting.TestLogger(t))
+	handler.SetLeader(true)
+	return handler, client
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		ExpectedStatus int
+	}{{
+		name:           "Test the list of the rollouts",
+		method:         http.MethodGet,
+		path:           "/rollouts",
+		token:          "viewer-token",
+		ExpectedStatus: http.StatusOK,
+	}, {
+		name:           "Test the plan of the rollout",
+		method:         http.MethodGet,
+		path:           "/rollouts/default/test/plan",
+		token:          "viewer-token",
+		ExpectedStatus: http.StatusOK,
+	}, {
+		name:           "Test the plan of the unknown rollout",
+		method:         http.MethodGet,
+		path:           "/rollouts/default/unknown/plan",
+		token:          "viewer-token",
+		ExpectedStatus: http.StatusNotFound,
+	}, {
+		name:           "Test the unknown action",
+		method:         http.MethodPost,
+		path:           "/rollouts/default/test/restart",
+		token:          "admin-token",
+		ExpectedStatus: http.StatusNotFound,
+	}, {
+		name:           "Test the pause of the rollout",
+		method:         http.MethodPost,
+		path:           "/rollouts/default/test/pause",
+		token:          "admin-token",
+		ExpectedStatus: http.StatusOK,
+	}, {
+		name:           "Test the promotion of the complete rollout",
+		method:         http.MethodPost,
+		path:           "/rollouts/default/complete/promote",
+		token:          "admin-token",
+		ExpectedStatus: http.StatusConflict,
+	}, {
+		name:           "Test the promotion to the final stage of the complete rollout",
+		method:         http.MethodPost,
+		path:           "/rollouts/default/complete/promote-to-final",
+		token:          "admin-token",
+		ExpectedStatus: http.StatusConflict,
+	}, {
+		name:           "Test the revert of the complete rollout without standby",
+		method:         http.MethodPost,
+		path:           "/rollouts/default/complete/revert",
+		token:          "admin-token",
+		ExpectedStatus: http.StatusConflict,
+	}, {
+		name:           "Test the revert of the complete rollout on standby",
+		method:         http.MethodPost,
+		path:           "/rollouts/default/standby/revert",
+		token:          "admin-token",
+		ExpectedStatus: http.StatusOK,
+	}, {
+		name:           "Test the pause by the user not allowed",
+		method:         http.MethodPost,
+		path:           "/rollouts/default/test/pause",
+		token:          "viewer-token",
+		ExpectedStatus: http.StatusForbidden,
+	}, {
+		name:           "Test the pause with the method not allowed",
+		method:         http.MethodGet,
+		path:           "/rollouts/default/test/pause",
+		token:          "admin-token",
+		ExpectedStatus: http.StatusMethodNotAllowed,
+	}, {
+		name:           "Test the request without token",
+		method:         http.MethodGet,
+		path:           "/rollouts",
+		ExpectedStatus: http.StatusUnauthorized,
+	}, {
+		name:           "Test the unauthenticated token",
+		method:         http.MethodGet,
+		path:           "/rollouts",
+		token:          "invalid-token",
+		ExpectedStatus: http.StatusUnauthorized,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			standby := newRO("standby", 100)
+			standby.Spec.PostRolloutStandby = &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600}
+			standby.Status.MarkLastStageRevisionComplete()
+			handler, _ := newTestHandler(t, newRO("test", 10), newRO("complete", 100), standby)
+			req := httptest.NewRequest(test.method, test.path, nil)
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != test.ExpectedStatus {
+				t.Fatalf("Status of ServeHTTP() = %v, want %v", rec.Code, test.ExpectedStatus)
+			}
+		})
+	}
+}
+
+func TestHandlerNotLeader(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	handler.SetLeader(false)
+	req := httptest.NewRequest(http.MethodGet, "/rollouts", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Status of ServeHTTP() = %v, want %v", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHandlerList(t *testing.T) {
+	handler, _ := newTestHandler(t, newRO("test", 10), newRO("complete", 100))
+	req := httptest.NewRequest(http.MethodGet, "/rollouts", nil)
+	req.Header.Set("Authorization", "Bearer viewer-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var rollouts []Rollout
+	if err := json.NewDecoder(rec.Body).Decode(&rollouts); err != nil {
+		t.Fatalf("Failed to decode the rollouts: %v", err)
+	}
+	// The remaining stages are at 40%, 70% and 100%, and each of them takes 2 minutes.
+	eta := metav1.NewTime(finishTime.Add(6 * time.Minute))
+	if len(rollouts) != 1 || rollouts[0].Name != "test" || !rollouts[0].ETA.Equal(&eta) {
+		t.Fatalf("Result of ServeHTTP() = %v, want the rollout test with the ETA %v", rollouts, eta)
+	}
+}
+
+func TestHandlerActions(t *testing.T) {
+	tests := []struct {
+		name           string
+		action         string
+		annotations    map[string]string
+		ExpectedResult map[string]string
+	}{{
+		name:           "Test the pause",
+		action:         "pause",
+		ExpectedResult: map[string]string{resources.RolloutPaused: "admin"},
+	}, {
+		name:           "Test the resume",
+		action:         "resume",
+		annotations:    map[string]string{resources.RolloutPaused: "admin", "other": "value"},
+		ExpectedResult: map[string]string{"other": "value"},
+	}, {
+		name:           "Test the promotion",
+		action:         "promote",
+		ExpectedResult: map[string]string{resources.RolloutPromoted: "admin"},
+	}, {
+		name:           "Test the promotion to the final stage",
+		action:         "promote-to-final",
+		ExpectedResult: map[string]string{resources.RolloutPromotedToFinal: "admin"},
+	}, {
+		name:           "Test the abort",
+		action:         "abort",
+		ExpectedResult: map[string]string{resources.RolloutAborted: "test-00002"},
+	}, {
+		name:           "Test the revert",
+		action:         "revert",
+		ExpectedResult: map[string]string{resources.RolloutReverted: "test-00002"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := newRO("test", 10)
+			ro.Annotations = test.annotations
+			handler, client := newTestHandler(t, ro)
+			req := httptest.NewRequest(http.MethodPost, "/rollouts/default/test/"+test.action, nil)
+			req.Header.Set("Authorization", "Bearer admin-token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status of ServeHTTP() = %v, want %v", rec.Code, http.StatusOK)
+			}
+			patched, err := client.ServingV1().RolloutOrchestrators("default").Get(req.Context(), "test",
+				metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get the RolloutOrchestrator: %v", err)
+			}
+			if !reflect.DeepEqual(patched.Annotations, test.ExpectedResult) {
+				t.Fatalf("Result of ServeHTTP() = %v, want %v", patched.Annotations, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestRemainingStages(t *testing.T) {
+	tests := []struct {
+		name           string
+		current        int64
+		ratio          int64
+		ExpectedResult []int64
+	}{{
+		name:           "Test the stages from the start",
+		current:        0,
+		ratio:          30,
+		ExpectedResult: []int64{30, 60, 90, 100},
+	}, {
+		name:           "Test the last stage",
+		current:        90,
+		ratio:          30,
+		ExpectedResult: []int64{100},
+	}, {
+		name:    "Test the complete rollout",
+		current: 100,
+		ratio:   30,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := remainingStages(test.current, test.ratio); !reflect.DeepEqual(result, test.ExpectedResult) {
+				t.Fatalf("Result of remainingStages() = %v, want %v", result, test.ExpectedResult)
+			}
+		})
+	}
+}
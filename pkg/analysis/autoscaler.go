@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// StatsPath is the path of the per-revision stats served by the autoscaler. The full path is
+	// /stats/{namespace}/{revision}, with the window of the stats as the query parameter window.
+	StatsPath = "/stats/"
+
+	// StatRequestCount is the number of the requests received by the revision in the window.
+	StatRequestCount = "request-count"
+	// StatProxiedRequestCount is the number of the requests proxied by the activator to the revision in the window.
+	StatProxiedRequestCount = "proxied-request-count"
+	// StatRequestsPerSecond is the average number of the requests per second received by the revision in the window.
+	StatRequestsPerSecond = "requests-per-second"
+	// StatConcurrency is the average concurrency of the revision in the window.
+	StatConcurrency = "concurrency"
+	// StatProxiedConcurrency is the average concurrency proxied by the activator in the window.
+	StatProxiedConcurrency = "proxied-concurrency"
+	// StatPods is the number of the pods reporting the stats in the window.
+	StatPods = "pods"
+)
+
+// errTokenNotTLS is returned, when the token would be sent to the autoscaler in plain text.
+var errTokenNotTLS = errors.New("the token is only sent to the autoscaler over TLS")
+
+// RevisionStats is the per-revision stats served by the autoscaler, aggregated over a window.
+type RevisionStats struct {
+	Namespace string `json:"namespace"`
+	Revision  string `json:"revision"`
+
+	// WindowSeconds is the length of the window of the stats.
+	WindowSeconds float64 `json:"windowSeconds"`
+
+	// Pods is the number of the pods reporting the stats in the window.
+	Pods int `json:"pods"`
+
+	// RequestCount is the number of the requests received by the revision in the window.
+	RequestCount float64 `json:"requestCount"`
+
+	// ProxiedRequestCount is the number of the requests proxied by the activator in the window.
+	ProxiedRequestCount float64 `json:"proxiedRequestCount"`
+
+	// AverageConcurrency is the sum of the average concurrency of the pods in the window.
+	AverageConcurrency float64 `json:"averageConcurrency"`
+
+	// AverageProxiedConcurrency is the sum of the average concurrency proxied by the activator to the pods
+	// in the window.
+	AverageProxiedConcurrency float64 `json:"averageProxiedConcurrency"`
+}
+
+// Value returns the value of the stat with the name.
+func (s *RevisionStats) Value(name string) (float64, error) {
+	switch name {
+	case StatRequestCount:
+		return s.RequestCount, nil
+	case StatProxiedRequestCount:
+		return s.ProxiedRequestCount, nil
+	case StatRequestsPerSecond:
+		if s.WindowSeconds <= 0 {
+			return 0, ErrNoData
+		}
+		return s.RequestCount / s.WindowSeconds, nil
+	case StatConcurrency:
+		return s.AverageConcurrency, nil
+	case StatProxiedConcurrency:
+		return s.AverageProxiedConcurrency, nil
+	case StatPods:
+		return float64(s.Pods), nil
+	}
+	return 0, fmt.Errorf("unknown stat %q", name)
+}
+
+// AutoscalerProvider queries the per-revision stats of the Knative autoscaler. The expressions of the queries are
+// the names of the stats, e.g. requests-per-second.
+type AutoscalerProvider struct {
+	// Address is the base URL of the stats endpoint of the autoscaler.
+	Address string
+
+	// Window is the window of the stats.
+	Window time.Duration
+
+	// TokenFile is the file of the bearer token to authenticate to the autoscaler. It is read for every query,
+	// since the projected service account tokens are rotated. The token is only sent over TLS.
+	TokenFile string
+
+	// Client is the HTTP client to send the queries.
+	Client *http.Client
+}
+
+// Query returns the stat named by the expression of the query for the target revision.
+func (p *AutoscalerProvider) Query(ctx context.Context, query Query, target Target) (float64, error) {
+	stats, err := p.Stats(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+	return stats.Value(strings.TrimSpace(query.Expression))
+}
+
+// newCAClient returns the HTTP client verifying the server with the CA certificates in caFile. The timeout of the
+// client is kept.
+func newCAClient(client *http.Client, caFile string) (*http.Client, error) {
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no CA certificate was found in %s", caFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}
+	return &http.Client{Transport: transport, Timeout: client.Timeout}, nil
+}
+
+// Stats fetches the stats of the target revision from the autoscaler.
+func (p *AutoscalerProvider) Stats(ctx context.Context, target Target) (*RevisionStats, error) {
+	u := strings.TrimSuffix(p.Address, "/") + StatsPath + target.Namespace + "/" + target.Revision +
+		"?window=" + p.Window.String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.TokenFile != "" {
+		if req.URL.Scheme != "https" {
+			return nil, errTokenNotTLS
+		}
+		token, err := os.ReadFile(p.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the autoscaler: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNoData
+	default:
+		return nil, fmt.Errorf("the autoscaler responded with the status %d", resp.StatusCode)
+	}
+	stats := &RevisionStats{}
+	if err := json.NewDecoder(resp.Body).Decode(stats); err != nil {
+		return nil, fmt.Errorf("failed to decode the stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAutoscalerProviderQuery(t *testing.T) {
+	stats := &RevisionStats{
+		Namespace:          "default",
+		Revision:           "test-00002",
+		WindowSeconds:      60,
+		Pods:               2,
+		RequestCount:       120,
+		AverageConcurrency: 1.5,
+	}
+	tests := []struct {
+		name          string
+		expression    string
+		revision      string
+		ExpectedValue float64
+		ExpectedError error
+	}{{
+		name:          "Test the request count",
+		expression:    StatRequestCount,
+		revision:      "test-00002",
+		ExpectedValue: 120,
+	}, {
+		name:          "Test the requests per second",
+		expression:    StatRequestsPerSecond,
+		revision:      "test-00002",
+		ExpectedValue: 2,
+	}, {
+		name:          "Test the concurrency",
+		expression:    StatConcurrency,
+		revision:      "test-00002",
+		ExpectedValue: 1.5,
+	}, {
+		name:          "Test the unknown stat",
+		expression:    "errors",
+		revision:      "test-00002",
+		ExpectedError: errors.New("unknown stat \"errors\""),
+	}, {
+		name:          "Test the revision without stats",
+		expression:    StatRequestCount,
+		revision:      "test-00003",
+		ExpectedError: ErrNoData,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			if err := os.WriteFile(tokenFile, []byte("secret\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+					t.Errorf("Authorization = %v, want %v", got, "Bearer secret")
+				}
+				if got := r.URL.Query().Get("window"); got != "1m0s" {
+					t.Errorf("Window = %v, want %v", got, "1m0s")
+				}
+				if r.URL.Path != StatsPath+"default/test-00002" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(stats)
+			}))
+			defer server.Close()
+
+			caFile := filepath.Join(t.TempDir(), "ca.crt")
+			if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+				Bytes: server.Certificate().Raw}), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			provider, err := NewProvider(&Config{Provider: AutoscalerProviderName, AutoscalerAddress: server.URL,
+				AutoscalerWindow: time.Minute, AutoscalerTokenFile: tokenFile, AutoscalerCAFile: caFile}, nil)
+			if err != nil {
+				t.Fatalf("NewProvider() = %v", err)
+			}
+			value, err := provider.Query(context.Background(), Query{Name: "test", Expression: test.expression},
+				Target{Namespace: "default", Service: "test", Revision: test.revision})
+			if value != test.ExpectedValue {
+				t.Fatalf("Result of Query() = %v, want %v", value, test.ExpectedValue)
+			}
+			if (err == nil) != (test.ExpectedError == nil) ||
+				(err != nil && err.Error() != test.ExpectedError.Error()) {
+				t.Fatalf("Error of Query() = %v, want %v", err, test.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestAutoscalerProviderPlainHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("The query was sent over HTTP with the Authorization %q", r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	provider := &AutoscalerProvider{Address: server.URL, Window: time.Minute, TokenFile: "/token",
+		Client: server.Client()}
+	_, err := provider.Stats(context.Background(), Target{Namespace: "default", Revision: "test-00002"})
+	if !errors.Is(err, errTokenNotTLS) {
+		t.Fatalf("Error of Stats() = %v, want %v", err, errTokenNotTLS)
+	}
+}
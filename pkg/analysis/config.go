@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	cm "knative.dev/pkg/configmap"
+)
+
+const (
+	// PrometheusProviderName is the name of the provider querying the Prometheus HTTP API.
+	PrometheusProviderName = "prometheus"
+
+	// AutoscalerProviderName is the name of the provider querying the per-revision stats of the Knative autoscaler.
+	AutoscalerProviderName = "autoscaler"
+
+	// StaticProviderName is the name of the provider reading the values from a file, mainly used for tests.
+	StaticProviderName = "static"
+
+	// QueryKeyPrefix is the prefix of the keys in config-rolloutorchestrator defining the queries,
+	// e.g. analysis-query.error-rate.
+	QueryKeyPrefix = "analysis-query"
+
+	// DefaultAutoscalerWindow is the default window of the per-revision stats queried from the autoscaler.
+	DefaultAutoscalerWindow = 60 * time.Second
+
+	// MaxAutoscalerWindow is the longest window of the per-revision stats kept by the autoscaler.
+	MaxAutoscalerWindow = 10 * time.Minute
+
+	// DefaultAutoscalerTokenFile is the default file of the token to authenticate to the autoscaler.
+	DefaultAutoscalerTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// Config includes the configuration options of the metric provider and the queries for the rollout analysis.
+type Config struct {
+	// Provider is the name of the metric provider: prometheus, autoscaler or static. The analysis is disabled,
+	// if it is empty.
+	Provider string
+
+	// PrometheusAddress is the base URL of the Prometheus HTTP API.
+	PrometheusAddress string
+
+	// AutoscalerAddress is the base URL of the stats endpoint of the autoscaler.
+	AutoscalerAddress string
+
+	// AutoscalerWindow is the window of the per-revision stats queried from the autoscaler.
+	AutoscalerWindow time.Duration
+
+	// AutoscalerTokenFile is the file of the bearer token to authenticate to the autoscaler.
+	AutoscalerTokenFile string
+
+	// AutoscalerCAFile is the file of the CA certificate to verify the autoscaler. The system roots are used, if it
+	// is empty.
+	AutoscalerCAFile string
+
+	// StaticFile is the file read by the static provider.
+	StaticFile string
+
+	// Queries contains the expressions of the queries keyed by their names.
+	Queries map[string]string
+}
+
+// NewConfigFromMap reads the configuration of the rollout analysis from the data of config-rolloutorchestrator.
+func NewConfigFromMap(data map[string]string) (*Config, error) {
+	cfg := &Config{
+		AutoscalerWindow:    DefaultAutoscalerWindow,
+		AutoscalerTokenFile: DefaultAutoscalerTokenFile,
+	}
+	if err := cm.Parse(data,
+		cm.AsString("analysis-provider", &cfg.Provider),
+		cm.AsString("analysis-prometheus-address", &cfg.PrometheusAddress),
+		cm.AsString("analysis-autoscaler-address", &cfg.AutoscalerAddress),
+		cm.AsDuration("analysis-autoscaler-window", &cfg.AutoscalerWindow),
+		cm.AsString("analysis-autoscaler-token-file", &cfg.AutoscalerTokenFile),
+		cm.AsString("analysis-autoscaler-ca-file", &cfg.AutoscalerCAFile),
+		cm.AsString("analysis-static-file", &cfg.StaticFile),
+		cm.CollectMapEntriesWithPrefix(QueryKeyPrefix, &cfg.Queries),
+	); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Provider {
+	case "":
+	case PrometheusProviderName:
+		if cfg.PrometheusAddress == "" {
+			return nil, fmt.Errorf("analysis-prometheus-address is required for the provider %s", cfg.Provider)
+		}
+	case AutoscalerProviderName:
+		if cfg.AutoscalerAddress == "" {
+			return nil, fmt.Errorf("analysis-autoscaler-address is required for the provider %s", cfg.Provider)
+		}
+		if u, err := url.Parse(cfg.AutoscalerAddress); err != nil || u.Scheme != "https" {
+			return nil, fmt.Errorf("analysis-autoscaler-address must be an https URL, was %q", cfg.AutoscalerAddress)
+		}
+		if cfg.AutoscalerWindow <= 0 || cfg.AutoscalerWindow > MaxAutoscalerWindow {
+			return nil, fmt.Errorf("analysis-autoscaler-window must be positive and at most %v, was %v",
+				MaxAutoscalerWindow, cfg.AutoscalerWindow)
+		}
+	case StaticProviderName:
+		if cfg.StaticFile == "" {
+			return nil, fmt.Errorf("analysis-static-file is required for the provider %s", cfg.Provider)
+		}
+	default:
+		return nil, fmt.Errorf("unknown analysis-provider %q", cfg.Provider)
+	}
+	return cfg, nil
+}
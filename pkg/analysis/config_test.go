@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewConfigFromMap(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           map[string]string
+		ExpectedResult *Config
+		ExpectedError  error
+	}{{
+		name: "Test the config without provider",
+		data: map[string]string{},
+		ExpectedResult: &Config{
+			AutoscalerWindow:    DefaultAutoscalerWindow,
+			AutoscalerTokenFile: DefaultAutoscalerTokenFile,
+		},
+	}, {
+		name: "Test the config of the autoscaler provider",
+		data: map[string]string{
+			"analysis-provider":              "autoscaler",
+			"analysis-autoscaler-address":    "https://autoscaler:8090",
+			"analysis-autoscaler-window":     "30s",
+			"analysis-query.request-rate":    "requests-per-second",
+			"analysis-query.concurrency":     "concurrency",
+			"analysis-autoscaler-token-file": "/token",
+			"analysis-autoscaler-ca-file":    "/ca.crt",
+			"progressive-rollout-strategy":   "availability",
+			"analysis-query":                 "ignored",
+		},
+		ExpectedResult: &Config{
+			Provider:            AutoscalerProviderName,
+			AutoscalerAddress:   "https://autoscaler:8090",
+			AutoscalerWindow:    30 * time.Second,
+			AutoscalerTokenFile: "/token",
+			AutoscalerCAFile:    "/ca.crt",
+			Queries: map[string]string{
+				"request-rate": "requests-per-second",
+				"concurrency":  "concurrency",
+			},
+		},
+	}, {
+		name: "Test the prometheus provider without address",
+		data: map[string]string{
+			"analysis-provider": "prometheus",
+		},
+		ExpectedError: errors.New("analysis-prometheus-address is required for the provider prometheus"),
+	}, {
+		name: "Test the autoscaler provider with invalid window",
+		data: map[string]string{
+			"analysis-provider":           "autoscaler",
+			"analysis-autoscaler-address": "https://autoscaler:8090",
+			"analysis-autoscaler-window":  "0s",
+		},
+		ExpectedError: errors.New("analysis-autoscaler-window must be positive and at most 10m0s, was 0s"),
+	}, {
+		name: "Test the autoscaler provider with the window longer than the stats kept",
+		data: map[string]string{
+			"analysis-provider":           "autoscaler",
+			"analysis-autoscaler-address": "https://autoscaler:8090",
+			"analysis-autoscaler-window":  "15m",
+		},
+		ExpectedError: errors.New("analysis-autoscaler-window must be positive and at most 10m0s, was 15m0s"),
+	}, {
+		name: "Test the autoscaler provider over plain HTTP",
+		data: map[string]string{
+			"analysis-provider":           "autoscaler",
+			"analysis-autoscaler-address": "http://autoscaler:8090",
+		},
+		ExpectedError: errors.New("analysis-autoscaler-address must be an https URL, was \"http://autoscaler:8090\""),
+	}, {
+		name: "Test the static provider without file",
+		data: map[string]string{
+			"analysis-provider": "static",
+		},
+		ExpectedError: errors.New("analysis-static-file is required for the provider static"),
+	}, {
+		name: "Test the unknown provider",
+		data: map[string]string{
+			"analysis-provider": "datadog",
+		},
+		ExpectedError: errors.New("unknown analysis-provider \"datadog\""),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewConfigFromMap(test.data)
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of NewConfigFromMap() = %v, want %v", r, test.ExpectedResult)
+			}
+			if (err == nil) != (test.ExpectedError == nil) ||
+				(err != nil && err.Error() != test.ExpectedError.Error()) {
+				t.Fatalf("Error of NewConfigFromMap() = %v, want %v", err, test.ExpectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PrometheusProvider queries the metrics with the Prometheus HTTP query API. The expressions of the queries are
+// PromQL, rendered with the target, e.g. sum(rate(revision_request_count{revision_name="{{.Revision}}"}[1m])).
+type PrometheusProvider struct {
+	// Address is the base URL of the Prometheus server.
+	Address string
+
+	// Client is the HTTP client to send the queries.
+	Client *http.Client
+}
+
+// prometheusResponse is the response of the Prometheus HTTP query API.
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// Query runs the query against /api/v1/query. The result must be a scalar or a vector, whose first sample is
+// returned.
+func (p *PrometheusProvider) Query(ctx context.Context, query Query, target Target) (float64, error) {
+	expression, err := renderQuery(query, target)
+	if err != nil {
+		return 0, err
+	}
+
+	u := strings.TrimSuffix(p.Address, "/") + "/api/v1/query?" + url.Values{"query": {expression}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the response of prometheus: %w", err)
+	}
+	result := &prometheusResponse{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return 0, fmt.Errorf("failed to decode the response of prometheus with the status %d: %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return 0, fmt.Errorf("prometheus query %q failed: %s: %s", query.Name, result.ErrorType, result.Error)
+	}
+
+	var sample []interface{}
+	switch result.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(result.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("failed to decode the scalar of the query %q: %w", query.Name, err)
+		}
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(result.Data.Result, &vector); err != nil {
+			return 0, fmt.Errorf("failed to decode the vector of the query %q: %w", query.Name, err)
+		}
+		if len(vector) == 0 {
+			return 0, ErrNoData
+		}
+		sample = vector[0].Value
+	default:
+		return 0, fmt.Errorf("unsupported result type %q of the query %q", result.Data.ResultType, query.Name)
+	}
+
+	// A sample is a pair of the timestamp and the value as a string.
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("invalid sample %v of the query %q", sample, query.Name)
+	}
+	value, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid value %v of the query %q", sample[1], query.Name)
+	}
+	return strconv.ParseFloat(value, 64)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPrometheusProviderQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		ExpectedValue float64
+		ExpectedError error
+	}{{
+		name:          "Test the vector result",
+		response:      `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000.1,"0.25"]}]}}`,
+		ExpectedValue: 0.25,
+	}, {
+		name:          "Test the scalar result",
+		response:      `{"status":"success","data":{"resultType":"scalar","result":[1700000000.1,"3"]}}`,
+		ExpectedValue: 3,
+	}, {
+		name:          "Test the empty vector",
+		response:      `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		ExpectedError: ErrNoData,
+	}, {
+		name:          "Test the failed query",
+		response:      `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+		ExpectedError: errors.New("prometheus query \"error-rate\" failed: bad_data: parse error"),
+	}, {
+		name:          "Test the unsupported result type",
+		response:      `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		ExpectedError: errors.New("unsupported result type \"matrix\" of the query \"error-rate\""),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/query" {
+					t.Errorf("Path = %v, want %v", r.URL.Path, "/api/v1/query")
+				}
+				query = r.URL.Query().Get("query")
+				w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			provider := &PrometheusProvider{Address: server.URL + "/", Client: server.Client()}
+			value, err := provider.Query(context.Background(),
+				Query{Name: "error-rate", Expression: `errors{revision_name="{{.Revision}}"}`},
+				Target{Namespace: "default", Service: "test", Revision: "test-00002"})
+			if want := `errors{revision_name="test-00002"}`; query != want {
+				t.Fatalf("Query = %v, want %v", query, want)
+			}
+			if value != test.ExpectedValue {
+				t.Fatalf("Result of Query() = %v, want %v", value, test.ExpectedValue)
+			}
+			if (err == nil) != (test.ExpectedError == nil) ||
+				(err != nil && err.Error() != test.ExpectedError.Error()) {
+				t.Fatalf("Error of Query() = %v, want %v", err, test.ExpectedError)
+			}
+		})
+	}
+}
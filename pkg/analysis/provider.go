@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package analysis provides the metric providers to query the metrics of the revisions during the progressive
+// rollout, and the analyzer to cache the results per revision per stage.
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+)
+
+// ErrNoData is returned, when the metric provider has no data for the query.
+var ErrNoData = errors.New("no data for the query")
+
+// Query is the metric query configured in config-rolloutorchestrator.
+type Query struct {
+	// Name is the name of the query, after the prefix analysis-query. in the key of the configmap.
+	Name string
+
+	// Expression is the query interpreted by the provider. It is a Go template, rendered with the Target.
+	Expression string
+}
+
+// Target identifies the revision, whose metric is queried.
+type Target struct {
+	Namespace string
+	Service   string
+	Revision  string
+}
+
+// MetricProvider queries the value of a metric for a revision.
+type MetricProvider interface {
+	// Query returns the value of the query for the target revision, or ErrNoData if there is no value.
+	Query(ctx context.Context, query Query, target Target) (float64, error)
+}
+
+// NewProvider creates the MetricProvider configured in the config. It returns nil, if no provider is configured.
+func NewProvider(cfg *Config, client *http.Client) (MetricProvider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case PrometheusProviderName:
+		return &PrometheusProvider{Address: cfg.PrometheusAddress, Client: client}, nil
+	case AutoscalerProviderName:
+		if cfg.AutoscalerCAFile != "" {
+			var err error
+			if client, err = newCAClient(client, cfg.AutoscalerCAFile); err != nil {
+				return nil, err
+			}
+		}
+		return &AutoscalerProvider{Address: cfg.AutoscalerAddress, Window: cfg.AutoscalerWindow,
+			TokenFile: cfg.AutoscalerTokenFile, Client: client}, nil
+	case StaticProviderName:
+		return NewStaticProvider(cfg.StaticFile)
+	}
+	return nil, fmt.Errorf("unknown metric provider %q", cfg.Provider)
+}
+
+type cacheKey struct {
+	target Target
+	stage  string
+	query  string
+}
+
+// Analyzer queries the metrics configured in config-rolloutorchestrator with the MetricProvider. The results are
+// cached per revision per stage, so the metrics are queried only once for each stage of the rollout.
+type Analyzer struct {
+	provider MetricProvider
+	queries  map[string]string
+
+	mu    sync.Mutex
+	cache map[cacheKey]float64
+}
+
+// NewAnalyzer creates an Analyzer with the provider and the queries, keyed by their names.
+func NewAnalyzer(provider MetricProvider, queries map[string]string) *Analyzer {
+	return &Analyzer{
+		provider: provider,
+		queries:  queries,
+		cache:    make(map[cacheKey]float64),
+	}
+}
+
+// Query returns the value of the query with the name for the target revision in the stage. The failed queries
+// are not cached.
+func (a *Analyzer) Query(ctx context.Context, name string, target Target, stage string) (float64, error) {
+	expression, ok := a.queries[name]
+	if !ok {
+		return 0, fmt.Errorf("the query %q is not configured", name)
+	}
+
+	key := cacheKey{target: target, stage: stage, query: name}
+	a.mu.Lock()
+	value, ok := a.cache[key]
+	a.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := a.provider.Query(ctx, Query{Name: name, Expression: expression}, target)
+	if err != nil {
+		return 0, err
+	}
+	a.mu.Lock()
+	a.cache[key] = value
+	a.mu.Unlock()
+	return value, nil
+}
+
+// Forget drops the cached results of all the revisions of the service in all the stages, once its rollout is over.
+func (a *Analyzer) Forget(namespace, service string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key := range a.cache {
+		if key.target.Namespace == namespace && key.target.Service == service {
+			delete(a.cache, key)
+		}
+	}
+}
+
+// StageKey returns the key of the current stage of the RolloutOrchestrator, which is the traffic split of the
+// stage, e.g. "rev-00001=80,rev-00002=20".
+func StageKey(ro *v1.RolloutOrchestrator) string {
+	targets := make([]string, 0, len(ro.Spec.StageTargetRevisions))
+	for _, target := range ro.Spec.StageTargetRevisions {
+		percent := int64(0)
+		if target.Percent != nil {
+			percent = *target.Percent
+		}
+		targets = append(targets, target.RevisionName+"="+strconv.FormatInt(percent, 10))
+	}
+	sort.Strings(targets)
+	return strings.Join(targets, ",")
+}
+
+// renderQuery renders the expression of the query as a Go template with the target.
+func renderQuery(query Query, target Target) (string, error) {
+	tmpl, err := template.New(query.Name).Option("missingkey=error").Parse(query.Expression)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the query %q: %w", query.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, target); err != nil {
+		return "", fmt.Errorf("failed to render the query %q: %w", query.Name, err)
+	}
+	return buf.String(), nil
+}
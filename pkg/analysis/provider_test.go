@@ -0,0 +1,184 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/utils/ptr"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// countingProvider returns the values keyed by the revisions, and counts the queries.
+type countingProvider struct {
+	values  map[string]float64
+	queries int
+}
+
+func (p *countingProvider) Query(_ context.Context, _ Query, target Target) (float64, error) {
+	p.queries++
+	value, ok := p.values[target.Revision]
+	if !ok {
+		return 0, ErrNoData
+	}
+	return value, nil
+}
+
+func TestAnalyzerQuery(t *testing.T) {
+	target := Target{Namespace: "default", Service: "test", Revision: "test-00002"}
+	tests := []struct {
+		name            string
+		query           string
+		stages          []string
+		target          Target
+		ExpectedValue   float64
+		ExpectedQueries int
+		ExpectedError   error
+	}{{
+		name:            "Test the query cached in the same stage",
+		query:           "error-rate",
+		stages:          []string{"a", "a", "a"},
+		target:          target,
+		ExpectedValue:   0.5,
+		ExpectedQueries: 1,
+	}, {
+		name:            "Test the query repeated in the next stages",
+		query:           "error-rate",
+		stages:          []string{"a", "b", "b", "c"},
+		target:          target,
+		ExpectedValue:   0.5,
+		ExpectedQueries: 3,
+	}, {
+		name:            "Test the failed query not cached",
+		query:           "error-rate",
+		stages:          []string{"a", "a"},
+		target:          Target{Namespace: "default", Service: "test", Revision: "test-00003"},
+		ExpectedQueries: 2,
+		ExpectedError:   ErrNoData,
+	}, {
+		name:          "Test the query not configured",
+		query:         "latency",
+		stages:        []string{"a"},
+		target:        target,
+		ExpectedError: errors.New("the query \"latency\" is not configured"),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &countingProvider{values: map[string]float64{"test-00002": 0.5}}
+			analyzer := NewAnalyzer(provider, map[string]string{"error-rate": "errors"})
+			var value float64
+			var err error
+			for _, stage := range test.stages {
+				value, err = analyzer.Query(context.Background(), test.query, test.target, stage)
+			}
+			if value != test.ExpectedValue {
+				t.Fatalf("Result of Query() = %v, want %v", value, test.ExpectedValue)
+			}
+			if provider.queries != test.ExpectedQueries {
+				t.Fatalf("Number of the queries = %v, want %v", provider.queries, test.ExpectedQueries)
+			}
+			if (err == nil) != (test.ExpectedError == nil) ||
+				(err != nil && err.Error() != test.ExpectedError.Error()) {
+				t.Fatalf("Error of Query() = %v, want %v", err, test.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestAnalyzerForget(t *testing.T) {
+	provider := &countingProvider{values: map[string]float64{"test-00001": 1, "test-00002": 2}}
+	analyzer := NewAnalyzer(provider, map[string]string{"error-rate": "errors"})
+	old := Target{Namespace: "default", Service: "test", Revision: "test-00001"}
+	target := Target{Namespace: "default", Service: "test", Revision: "test-00002"}
+	other := Target{Namespace: "default", Service: "other", Revision: "test-00001"}
+	for _, tgt := range []Target{old, target, other} {
+		if _, err := analyzer.Query(context.Background(), "error-rate", tgt, "a"); err != nil {
+			t.Fatalf("Error of Query() = %v", err)
+		}
+	}
+
+	analyzer.Forget("default", "test")
+	for _, tgt := range []Target{old, target, other} {
+		if _, err := analyzer.Query(context.Background(), "error-rate", tgt, "a"); err != nil {
+			t.Fatalf("Error of Query() = %v", err)
+		}
+	}
+	if provider.queries != 5 {
+		t.Fatalf("Number of the queries = %v, want %v", provider.queries, 5)
+	}
+}
+
+func TestStageKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		targets        []v1.TargetRevision
+		ExpectedResult string
+	}{{
+		name:           "Test the stage without targets",
+		ExpectedResult: "",
+	}, {
+		name: "Test the stage with the targets sorted",
+		targets: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "test-00002", Percent: ptr.To(int64(20))},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "test-00001", Percent: ptr.To(int64(80))},
+		}},
+		ExpectedResult: "test-00001=80,test-00002=20",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{}
+			ro.Spec.StageTargetRevisions = test.targets
+			if r := StageKey(ro); !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of StageKey() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestRenderQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		expression     string
+		ExpectedResult string
+		ExpectedError  bool
+	}{{
+		name:           "Test the query with the target",
+		expression:     `sum(rate(revision_request_count{namespace_name="{{.Namespace}}", revision_name="{{.Revision}}"}[1m]))`,
+		ExpectedResult: `sum(rate(revision_request_count{namespace_name="default", revision_name="test-00002"}[1m]))`,
+	}, {
+		name:          "Test the query with an unknown field",
+		expression:    "{{.Route}}",
+		ExpectedError: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := renderQuery(Query{Name: "test", Expression: test.expression},
+				Target{Namespace: "default", Service: "test", Revision: "test-00002"})
+			if (err != nil) != test.ExpectedError {
+				t.Fatalf("Error of renderQuery() = %v, want error %v", err, test.ExpectedError)
+			}
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of renderQuery() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// StaticProvider returns the values read from a file, mainly used for tests. The file is YAML or JSON, mapping
+// namespace/revision to the values keyed by the names of the queries, e.g.
+//
+//	default/hello-00002:
+//	  error-rate: 0.01
+type StaticProvider struct {
+	values map[string]map[string]float64
+}
+
+// NewStaticProvider reads the values of the StaticProvider from the file.
+func NewStaticProvider(file string) (*StaticProvider, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the static metrics: %w", err)
+	}
+	values := map[string]map[string]float64{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse the static metrics %s: %w", file, err)
+	}
+	return &StaticProvider{values: values}, nil
+}
+
+// Query returns the value of the query with the name for the target revision.
+func (p *StaticProvider) Query(_ context.Context, query Query, target Target) (float64, error) {
+	value, ok := p.values[target.Namespace+"/"+target.Revision][query.Name]
+	if !ok {
+		return 0, ErrNoData
+	}
+	return value, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticProviderQuery(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "metrics.yaml")
+	if err := os.WriteFile(file, []byte("default/test-00002:\n  error-rate: 0.01\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	provider, err := NewProvider(&Config{Provider: StaticProviderName, StaticFile: file}, nil)
+	if err != nil {
+		t.Fatalf("Error of NewProvider() = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		revision      string
+		ExpectedValue float64
+		ExpectedError error
+	}{{
+		name:          "Test the value of the revision",
+		query:         "error-rate",
+		revision:      "test-00002",
+		ExpectedValue: 0.01,
+	}, {
+		name:          "Test the query without value",
+		query:         "latency",
+		revision:      "test-00002",
+		ExpectedError: ErrNoData,
+	}, {
+		name:          "Test the revision without values",
+		query:         "error-rate",
+		revision:      "test-00001",
+		ExpectedError: ErrNoData,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := provider.Query(context.Background(), Query{Name: test.query},
+				Target{Namespace: "default", Service: "test", Revision: test.revision})
+			if value != test.ExpectedValue {
+				t.Fatalf("Result of Query() = %v, want %v", value, test.ExpectedValue)
+			}
+			if err != test.ExpectedError {
+				t.Fatalf("Error of Query() = %v, want %v", err, test.ExpectedError)
+			}
+		})
+	}
+}
@@ -51,6 +51,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&RolloutOrchestratorList{},
 		&StagePodAutoscaler{},
 		&StagePodAutoscalerList{},
+		&RolloutGroup{},
+		&RolloutGroupList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/apis"
+)
+
+var rolloutGroupCondSet = apis.NewLivingConditionSet(
+	RolloutGroupConditionMembersHealthy,
+	RolloutGroupConditionRolloutComplete,
+)
+
+// GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
+func (*RolloutGroup) GetConditionSet() apis.ConditionSet {
+	return rolloutGroupCondSet
+}
+
+// GetGroupVersionKind returns the GroupVersionKind.
+func (*RolloutGroup) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("RolloutGroup")
+}
+
+// GetOrdering returns the ordering of the members, falling back to lockstep.
+func (rg *RolloutGroup) GetOrdering() string {
+	if strings.EqualFold(rg.Spec.Ordering, RolloutGroupOrderingDAG) {
+		return RolloutGroupOrderingDAG
+	}
+	return RolloutGroupOrderingLockstep
+}
+
+// GetFailurePolicy returns the policy applied when any member fails, falling back to hold.
+func (rg *RolloutGroup) GetFailurePolicy() string {
+	if strings.EqualFold(rg.Spec.FailurePolicy, RolloutGroupFailurePolicyRollback) {
+		return RolloutGroupFailurePolicyRollback
+	}
+	return RolloutGroupFailurePolicyHold
+}
+
+// IsReady returns true if the Status condition RolloutGroupConditionReady is true.
+func (rg *RolloutGroup) IsReady() bool {
+	rgs := rg.Status
+	return rgs.GetCondition(RolloutGroupConditionReady).IsTrue()
+}
+
+// IsFailed returns true if the Status condition RolloutGroupConditionMembersHealthy is false.
+func (rg *RolloutGroup) IsFailed() bool {
+	rgs := rg.Status
+	return rgs.GetCondition(RolloutGroupConditionMembersHealthy).IsFalse()
+}
+
+// InitializeConditions sets the initial values to the conditions.
+func (rgs *RolloutGroupStatus) InitializeConditions() {
+	rolloutGroupCondSet.Manage(rgs).InitializeConditions()
+}
+
+// MarkMembersHealthy marks the MembersHealthy condition to indicate that no member has failed.
+func (rgs *RolloutGroupStatus) MarkMembersHealthy() {
+	rolloutGroupCondSet.Manage(rgs).MarkTrue(RolloutGroupConditionMembersHealthy)
+}
+
+// MarkMemberFailed marks the MembersHealthy condition to indicate that the rollout of the member failed,
+// and the failure policy has been applied to the group.
+func (rgs *RolloutGroupStatus) MarkMemberFailed(serviceName, policy string) {
+	rolloutGroupCondSet.Manage(rgs).MarkFalse(
+		RolloutGroupConditionMembersHealthy,
+		"MemberRolloutFailed",
+		"The rollout of the member %s failed, and the policy %s is applied to the group.", serviceName, policy)
+}
+
+// MarkInvalidMembers marks the MembersHealthy condition to indicate that the members cannot be ordered,
+// e.g. because of a dependency cycle.
+func (rgs *RolloutGroupStatus) MarkInvalidMembers(message string) {
+	rolloutGroupCondSet.Manage(rgs).MarkFalse(
+		RolloutGroupConditionMembersHealthy,
+		"InvalidMembers",
+		"The members cannot be ordered: %s.", message)
+}
+
+// MarkRolloutComplete marks the RolloutComplete condition to indicate that all the members have completed
+// their rollouts.
+func (rgs *RolloutGroupStatus) MarkRolloutComplete() {
+	rolloutGroupCondSet.Manage(rgs).MarkTrue(RolloutGroupConditionRolloutComplete)
+}
+
+// MarkRolloutInProgress marks the RolloutComplete condition to indicate that some members are still
+// rolling out.
+func (rgs *RolloutGroupStatus) MarkRolloutInProgress(message string) {
+	rolloutGroupCondSet.Manage(rgs).MarkUnknown(
+		RolloutGroupConditionRolloutComplete,
+		"RolloutInProgress",
+		"Still in the progress of rolling out the members: %s.", message)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRolloutGroupGetGroupVersionKind(t *testing.T) {
+	rolloutGroup := &RolloutGroup{}
+
+	if got, want := rolloutGroup.GetGroupVersionKind(), SchemeGroupVersion.WithKind("RolloutGroup"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetGroupVersionKind() = %v, want: %v", got, want)
+	}
+}
+
+func TestRolloutGroupOrderingFailurePolicy(t *testing.T) {
+	tests := []struct {
+		name                  string
+		spec                  RolloutGroupSpec
+		expectedOrdering      string
+		expectedFailurePolicy string
+	}{{
+		name:                  "defaults",
+		spec:                  RolloutGroupSpec{},
+		expectedOrdering:      RolloutGroupOrderingLockstep,
+		expectedFailurePolicy: RolloutGroupFailurePolicyHold,
+	}, {
+		name:                  "dag and rollback in upper case",
+		spec:                  RolloutGroupSpec{Ordering: "DAG", FailurePolicy: "Rollback"},
+		expectedOrdering:      RolloutGroupOrderingDAG,
+		expectedFailurePolicy: RolloutGroupFailurePolicyRollback,
+	}, {
+		name:                  "unknown values",
+		spec:                  RolloutGroupSpec{Ordering: "random", FailurePolicy: "ignore"},
+		expectedOrdering:      RolloutGroupOrderingLockstep,
+		expectedFailurePolicy: RolloutGroupFailurePolicyHold,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rg := &RolloutGroup{Spec: test.spec}
+			if got := rg.GetOrdering(); got != test.expectedOrdering {
+				t.Fatalf("Result of GetOrdering() = %v, want %v", got, test.expectedOrdering)
+			}
+			if got := rg.GetFailurePolicy(); got != test.expectedFailurePolicy {
+				t.Fatalf("Result of GetFailurePolicy() = %v, want %v", got, test.expectedFailurePolicy)
+			}
+		})
+	}
+}
+
+func TestRolloutGroupStatus(t *testing.T) {
+	rg := &RolloutGroup{}
+	rg.Status.InitializeConditions()
+	if rg.IsReady() || rg.IsFailed() {
+		t.Fatalf("Initialized RolloutGroup: IsReady() = %v, IsFailed() = %v, want false, false",
+			rg.IsReady(), rg.IsFailed())
+	}
+
+	rg.Status.MarkMembersHealthy()
+	rg.Status.MarkRolloutInProgress("svc-a")
+	if rg.IsReady() || rg.IsFailed() {
+		t.Fatalf("RolloutGroup in progress: IsReady() = %v, IsFailed() = %v, want false, false",
+			rg.IsReady(), rg.IsFailed())
+	}
+
+	rg.Status.MarkRolloutComplete()
+	if !rg.IsReady() || rg.IsFailed() {
+		t.Fatalf("Complete RolloutGroup: IsReady() = %v, IsFailed() = %v, want true, false",
+			rg.IsReady(), rg.IsFailed())
+	}
+
+	rg.Status.MarkMemberFailed("svc-a", RolloutGroupFailurePolicyHold)
+	if rg.IsReady() || !rg.IsFailed() {
+		t.Fatalf("Failed RolloutGroup: IsReady() = %v, IsFailed() = %v, want false, true",
+			rg.IsReady(), rg.IsFailed())
+	}
+
+	rg.Status.MarkMembersHealthy()
+	rg.Status.MarkInvalidMembers("cycle")
+	if cond := rg.Status.GetCondition(RolloutGroupConditionMembersHealthy); cond.Reason != "InvalidMembers" {
+		t.Fatalf("Result of MarkInvalidMembers() reason = %v, want InvalidMembers", cond.Reason)
+	}
+}
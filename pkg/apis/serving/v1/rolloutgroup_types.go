@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/kmeta"
+)
+
+// +genclient
+// +genreconciler
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RolloutGroup coordinates the progressive rollouts of several services, so that their stages advance
+// together or in the order of their dependencies.
+type RolloutGroup struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state of the RolloutGroup (from the client).
+	// +optional
+	Spec RolloutGroupSpec `json:"spec,omitempty"`
+
+	// Status communicates the observed state of the RolloutGroup (from the controller).
+	// +optional
+	Status RolloutGroupStatus `json:"status,omitempty"`
+}
+
+// Verify that RolloutGroup adheres to the appropriate interfaces.
+var (
+	// Check that we can create OwnerReferences to a RolloutGroup.
+	_ kmeta.OwnerRefable = (*RolloutGroup)(nil)
+
+	// Check that the type conforms to the duck Knative Resource shape.
+	_ duckv1.KRShaped = (*RolloutGroup)(nil)
+)
+
+const (
+	// RolloutGroupOrderingLockstep advances the stage of a member only when no other member is in the middle
+	// of a stage, so that all the members move forward together.
+	RolloutGroupOrderingLockstep = "lockstep"
+
+	// RolloutGroupOrderingDAG holds a member after its first stage, until all the members it depends on have
+	// completed their rollouts.
+	RolloutGroupOrderingDAG = "dag"
+
+	// RolloutGroupFailurePolicyHold holds all the members at their current stages, when any member fails.
+	RolloutGroupFailurePolicyHold = "hold"
+
+	// RolloutGroupFailurePolicyRollback holds all the members and shifts their traffic back to the initial
+	// revisions, when any member fails.
+	RolloutGroupFailurePolicyRollback = "rollback"
+)
+
+// RolloutGroupMember refers to one service taking part in the RolloutGroup.
+type RolloutGroupMember struct {
+	// ServiceName is the name of the Knative Service in the namespace of the RolloutGroup.
+	ServiceName string `json:"serviceName"`
+
+	// DependsOn lists the names of the member services, which have to complete their rollouts before
+	// this member moves on from its first stage. It is only used by the dag ordering.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// RolloutGroupSpec holds the desired state of the RolloutGroup (from the client).
+type RolloutGroupSpec struct {
+	// Members lists the services rolled out together.
+	Members []RolloutGroupMember `json:"members"`
+
+	// Ordering is either lockstep or dag. It defaults to lockstep.
+	// +optional
+	Ordering string `json:"ordering,omitempty"`
+
+	// FailurePolicy is either hold or rollback. It defaults to hold.
+	// +optional
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+const (
+	// RolloutGroupConditionReady is set to True, when all the members have completed their rollouts.
+	RolloutGroupConditionReady = apis.ConditionReady
+
+	// RolloutGroupConditionMembersHealthy is set to False, when the rollout of any member has failed.
+	RolloutGroupConditionMembersHealthy apis.ConditionType = "MembersHealthy"
+
+	// RolloutGroupConditionRolloutComplete is set to True, when all the members have completed their rollouts.
+	RolloutGroupConditionRolloutComplete apis.ConditionType = "RolloutComplete"
+
+	// RolloutGroupMemberPending means the RolloutOrchestrator of the member service does not exist yet.
+	RolloutGroupMemberPending = "Pending"
+
+	// RolloutGroupMemberProgressing means the member is rolling out a stage.
+	RolloutGroupMemberProgressing = "Progressing"
+
+	// RolloutGroupMemberHeld means the member is not allowed to move on to the next stage.
+	RolloutGroupMemberHeld = "Held"
+
+	// RolloutGroupMemberRollingBack means the traffic of the member is being shifted back to the initial revisions.
+	RolloutGroupMemberRollingBack = "RollingBack"
+
+	// RolloutGroupMemberComplete means the member has completed its rollout.
+	RolloutGroupMemberComplete = "Complete"
+
+	// RolloutGroupMemberFailed means the rollout of the member has failed.
+	RolloutGroupMemberFailed = "Failed"
+)
+
+// RolloutGroupMemberStatus holds the observed state of one member.
+type RolloutGroupMemberStatus struct {
+	// ServiceName is the name of the member service.
+	ServiceName string `json:"serviceName"`
+
+	// Phase is one of Pending, Progressing, Held, RollingBack, Complete or Failed.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Message explains the phase, e.g. which members the member is waiting for.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// TargetRevisionName is the name of the revision the member is rolling out. A failed member keeps the group
+	// held or rolled back, until it rolls out another revision.
+	// +optional
+	TargetRevisionName string `json:"targetRevisionName,omitempty"`
+}
+
+// RolloutGroupStatus communicates the observed state of the RolloutGroup (from the controller).
+type RolloutGroupStatus struct {
+	duckv1.Status `json:",inline"`
+
+	// MemberStatuses holds the observed state of the members.
+	// +optional
+	MemberStatuses []RolloutGroupMemberStatus `json:"memberStatuses,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RolloutGroupList is a list of RolloutGroup resources
+type RolloutGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RolloutGroup `json:"items"`
+}
+
+// GetStatus retrieves the status of the RolloutGroup. Implements the KRShaped interface.
+func (rg *RolloutGroup) GetStatus() *duckv1.Status {
+	return &rg.Status.Status
+}
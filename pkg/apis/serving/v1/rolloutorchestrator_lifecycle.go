@@ -18,6 +18,7 @@ package v1
 
 import (
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -33,6 +34,16 @@ var (
 	LastStageNotReached       = "Still in the progress of rolling the new revision."
 	StageRevisionStart        = "StageRevisionStart"
 	RolloutNewStage           = "Rolling out a new stage."
+	RolloutDisabled           = "Disabled"
+	RolloutDisabledMessage    = "The progressive rollout is disabled."
+)
+
+const (
+	// ScaleUpObservationWindow is the number of the latest scaling up phases the ScaleUpObservation covers.
+	ScaleUpObservationWindow = 10
+
+	// MaxStageDeltaMultiplier is the upper bound of the StageDeltaMultiplier.
+	MaxStageDeltaMultiplier = 32
 )
 
 // GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
@@ -108,6 +119,7 @@ func (sos *RolloutOrchestratorStatus) InitializeConditions() {
 // MarkStageRevisionFailed marks the RolloutOrchestratorStageReady condition to
 // indicate that the revision rollout failed for the current stage.
 func (sos *RolloutOrchestratorStatus) MarkStageRevisionFailed(message string) {
+	sos.StageDeltaMultiplier = 0
 	rolloutOrchestratorCondSet.Manage(sos).MarkFalse(
 		SOStageReady,
 		"StageRevisionRolloutFailed",
@@ -124,14 +136,56 @@ func (sos *RolloutOrchestratorStatus) MarkStageRevisionReady() {
 	rolloutOrchestratorCondSet.Manage(sos).MarkTrue(SOStageReady)
 }
 
+// MarkStageRevisionScaleUpReady marks the StageScaleUpReady condition to indicate that the scaling up phase
+// is finished for the current stage.
 func (sos *RolloutOrchestratorStatus) MarkStageRevisionScaleUpReady() {
 	rolloutOrchestratorCondSet.Manage(sos).MarkTrue(SOStageScaleUpReady)
 }
 
+// RecordScaleUpDuration adds the duration of one scaling up phase into the ScaleUpObservation. The oldest phase
+// leaves the window, once the window is full, and the average and the maximum are calculated over the window.
+func (sos *RolloutOrchestratorStatus) RecordScaleUpDuration(duration time.Duration) {
+	milliseconds := duration.Milliseconds()
+	if sos.ScaleUpObservation == nil {
+		sos.ScaleUpObservation = &ScaleUpObservation{}
+	}
+	observation := sos.ScaleUpObservation
+	observation.WindowMilliseconds = append(observation.WindowMilliseconds, milliseconds)
+	if len(observation.WindowMilliseconds) > ScaleUpObservationWindow {
+		observation.WindowMilliseconds = observation.WindowMilliseconds[len(observation.WindowMilliseconds)-
+			ScaleUpObservationWindow:]
+	}
+	var sum int64
+	observation.MaxMilliseconds = 0
+	for _, ms := range observation.WindowMilliseconds {
+		sum += ms
+		observation.MaxMilliseconds = max(observation.MaxMilliseconds, ms)
+	}
+	observation.Samples = int32(len(observation.WindowMilliseconds))
+	observation.AverageMilliseconds = sum / int64(observation.Samples)
+	observation.LastMilliseconds = milliseconds
+}
+
 func (sos *RolloutOrchestratorStatus) MarkStageRevisionScaleDownReady() {
 	rolloutOrchestratorCondSet.Manage(sos).MarkTrue(SOStageScaleDownReady)
 }
 
+// UpdateStageDeltaMultiplier doubles the StageDeltaMultiplier, if the current stage finishes in less than half of
+// the time given until the targetFinishTime, and resets it otherwise. It needs to be called before the
+// StageReady condition is marked as true.
+func (sos *RolloutOrchestratorStatus) UpdateStageDeltaMultiplier(targetFinishTime time.Time) {
+	cond := sos.GetCondition(SOStageReady)
+	if cond == nil || !cond.IsUnknown() || cond.LastTransitionTime.Inner.IsZero() || targetFinishTime.IsZero() {
+		return
+	}
+	start := cond.LastTransitionTime.Inner.Time
+	if time.Since(start) > targetFinishTime.Sub(start)/2 {
+		sos.StageDeltaMultiplier = 0
+		return
+	}
+	sos.StageDeltaMultiplier = min(max(sos.StageDeltaMultiplier, 1)*2, MaxStageDeltaMultiplier)
+}
+
 // MarkLastStageRevisionComplete marks the RolloutOrchestratorLastStageComplete condition to
 // indicate that the revision rollout succeeded for the last stage.
 func (sos *RolloutOrchestratorStatus) MarkLastStageRevisionComplete() {
@@ -154,7 +208,22 @@ func (sos *RolloutOrchestratorStatus) MarkStageRevisionScaleUpInProgress(reason,
 	rolloutOrchestratorCondSet.Manage(sos).MarkUnknown(SOStageScaleUpReady, reason, message)
 }
 
+// MarkProgressiveRolloutDisabled marks all the conditions of the stages true with the reason Disabled, to indicate
+// that the rollout is finalized, because the progressive rollout has been disabled.
+func (sos *RolloutOrchestratorStatus) MarkProgressiveRolloutDisabled() {
+	sos.StageDeltaMultiplier = 0
+	sos.ForcedDeletions = 0
+	sos.ScaleUpShortfall = 0
+	manager := rolloutOrchestratorCondSet.Manage(sos)
+	manager.MarkTrueWithReason(SOStageScaleUpReady, RolloutDisabled, RolloutDisabledMessage)
+	manager.MarkTrueWithReason(SOStageScaleDownReady, RolloutDisabled, RolloutDisabledMessage)
+	manager.MarkTrueWithReason(SOStageReady, RolloutDisabled, RolloutDisabledMessage)
+	manager.MarkTrueWithReason(SOLastStageComplete, RolloutDisabled, RolloutDisabledMessage)
+}
+
 func (sos *RolloutOrchestratorStatus) LaunchNewStage() {
+	sos.ForcedDeletions = 0
+	sos.ScaleUpShortfall = 0
 	sos.MarkStageRevisionScaleUpInProgress(StageRevisionStart, RolloutNewStage)
 	sos.MarkStageRevisionScaleDownInProgress(StageRevisionStart, RolloutNewStage)
 	sos.MarkStageRevisionInProgress(StageRevisionStart, RolloutNewStage)
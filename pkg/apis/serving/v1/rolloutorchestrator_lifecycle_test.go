@@ -19,7 +19,9 @@ package v1
 import (
 	"reflect"
 	"testing"
+	"time"
 
+	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
@@ -145,6 +147,116 @@ func emptyRolloutOrchestrator() *RolloutOrchestrator {
 	return &RolloutOrchestrator{}
 }
 
+func TestRecordScaleUpDuration(t *testing.T) {
+	window := make([]time.Duration, 0, ScaleUpObservationWindow+1)
+	window = append(window, 100*time.Second)
+	for i := 0; i < ScaleUpObservationWindow; i++ {
+		window = append(window, 10*time.Second)
+	}
+	tests := []struct {
+		name           string
+		durations      []time.Duration
+		ExpectedResult *ScaleUpObservation
+	}{{
+		name:      "Test with one scaling up",
+		durations: []time.Duration{20 * time.Second},
+		ExpectedResult: &ScaleUpObservation{
+			LastMilliseconds:    20000,
+			AverageMilliseconds: 20000,
+			MaxMilliseconds:     20000,
+			Samples:             1,
+			WindowMilliseconds:  []int64{20000},
+		},
+	}, {
+		name:      "Test with multiple scaling ups",
+		durations: []time.Duration{20 * time.Second, 40 * time.Second, 30 * time.Second},
+		ExpectedResult: &ScaleUpObservation{
+			LastMilliseconds:    30000,
+			AverageMilliseconds: 30000,
+			MaxMilliseconds:     40000,
+			Samples:             3,
+			WindowMilliseconds:  []int64{20000, 40000, 30000},
+		},
+	}, {
+		name:      "Test with the scaling ups shorter than a second",
+		durations: []time.Duration{1500 * time.Millisecond, 2 * time.Second},
+		ExpectedResult: &ScaleUpObservation{
+			LastMilliseconds:    2000,
+			AverageMilliseconds: 1750,
+			MaxMilliseconds:     2000,
+			Samples:             2,
+			WindowMilliseconds:  []int64{1500, 2000},
+		},
+	}, {
+		name:      "Test with the longest scaling up out of the window",
+		durations: window,
+		ExpectedResult: &ScaleUpObservation{
+			LastMilliseconds:    10000,
+			AverageMilliseconds: 10000,
+			MaxMilliseconds:     10000,
+			Samples:             ScaleUpObservationWindow,
+			WindowMilliseconds: []int64{10000, 10000, 10000, 10000, 10000, 10000, 10000, 10000, 10000,
+				10000},
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sos := &RolloutOrchestratorStatus{}
+			for _, duration := range test.durations {
+				sos.RecordScaleUpDuration(duration)
+			}
+			if !reflect.DeepEqual(sos.ScaleUpObservation, test.ExpectedResult) {
+				t.Fatalf("Result of RecordScaleUpDuration() = %v, want %v", sos.ScaleUpObservation,
+					test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestUpdateStageDeltaMultiplier(t *testing.T) {
+	tests := []struct {
+		name             string
+		multiplier       int32
+		targetFinishTime time.Time
+		ExpectedResult   int32
+	}{{
+		name:             "Test with the stage finished well before the deadline",
+		targetFinishTime: time.Now().Add(time.Hour),
+		ExpectedResult:   2,
+	}, {
+		name:             "Test with the stage finished well before the deadline after fast stages",
+		multiplier:       4,
+		targetFinishTime: time.Now().Add(time.Hour),
+		ExpectedResult:   8,
+	}, {
+		name:             "Test with the multiplier reaching the upper bound",
+		multiplier:       MaxStageDeltaMultiplier,
+		targetFinishTime: time.Now().Add(time.Hour),
+		ExpectedResult:   MaxStageDeltaMultiplier,
+	}, {
+		name:             "Test with the stage finished after the deadline",
+		multiplier:       4,
+		targetFinishTime: time.Now().Add(-time.Hour),
+		ExpectedResult:   0,
+	}, {
+		name:           "Test without the deadline",
+		multiplier:     4,
+		ExpectedResult: 4,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sos := &RolloutOrchestratorStatus{}
+			sos.MarkStageRevisionInProgress(StageRevisionStart, RolloutNewStage)
+			sos.StageDeltaMultiplier = test.multiplier
+			sos.UpdateStageDeltaMultiplier(test.targetFinishTime)
+			if sos.StageDeltaMultiplier != test.ExpectedResult {
+				t.Fatalf("Result of UpdateStageDeltaMultiplier() = %v, want %v", sos.StageDeltaMultiplier,
+					test.ExpectedResult)
+			}
+		})
+	}
+}
+
 func rolloutOrchestratorWithStageRevisionScaleUpReady() *RolloutOrchestrator {
 	so := &RolloutOrchestrator{}
 	so.Status.MarkStageRevisionScaleUpReady()
@@ -204,3 +316,29 @@ func rolloutOrchestratorWithStageReadyInProgress() *RolloutOrchestrator {
 	so.Status.MarkStageRevisionInProgress("", "")
 	return so
 }
+
+func TestMarkProgressiveRolloutDisabled(t *testing.T) {
+	ro := &RolloutOrchestrator{}
+	ro.Status.InitializeConditions()
+	ro.Status.LaunchNewStage()
+	ro.Status.StageDeltaMultiplier = 4
+	ro.Status.ForcedDeletions = 2
+
+	ro.Status.MarkProgressiveRolloutDisabled()
+	if !ro.IsReady() || !ro.IsStageReady() || !ro.IsLastStageComplete() || !ro.IsStageScaleUpReady() {
+		t.Fatalf("Result of MarkProgressiveRolloutDisabled(): IsReady() = %v, IsStageReady() = %v, "+
+			"IsLastStageComplete() = %v, IsStageScaleUpReady() = %v, want all true", ro.IsReady(), ro.IsStageReady(),
+			ro.IsLastStageComplete(), ro.IsStageScaleUpReady())
+	}
+	for _, condType := range []apis.ConditionType{SOStageReady, SOLastStageComplete, SOStageScaleUpReady,
+		SOStageScaleDownReady} {
+		if got := ro.Status.GetCondition(condType).Reason; got != RolloutDisabled {
+			t.Fatalf("Result of MarkProgressiveRolloutDisabled() reason of %s = %v, want %v", condType, got,
+				RolloutDisabled)
+		}
+	}
+	if ro.Status.StageDeltaMultiplier != 0 || ro.Status.ForcedDeletions != 0 {
+		t.Fatalf("Result of MarkProgressiveRolloutDisabled() StageDeltaMultiplier = %v, ForcedDeletions = %v, want 0, 0",
+			ro.Status.StageDeltaMultiplier, ro.Status.ForcedDeletions)
+	}
+}
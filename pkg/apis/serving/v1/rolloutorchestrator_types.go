@@ -17,7 +17,9 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmeta"
@@ -71,6 +73,38 @@ type TargetRevision struct {
 	// MaxScale sets the upper bound for the number of the replicas.
 	// +optional
 	MaxScale *int32 `json:"maxScale,omitempty"`
+
+	// PodCapacity indicates how much load one replica of the revision is able to handle. It is used to convert
+	// the number of replicas between revisions with different concurrency, autoscaling target or resources.
+	// +optional
+	PodCapacity *PodCapacity `json:"podCapacity,omitempty"`
+
+	// PodResources indicates the resources requested by one replica of the revision. It is used to keep the
+	// resources surging in each stage within the over-consumption budget.
+	// +optional
+	PodResources *PodResources `json:"podResources,omitempty"`
+}
+
+// PodCapacity holds the normalized capacity of one replica of the revision.
+type PodCapacity struct {
+	// Metric is the autoscaling metric, in which the capacity is measured, e.g. concurrency, rps, cpu or memory.
+	// +optional
+	Metric string `json:"metric,omitempty"`
+
+	// MilliValue is the amount of the metric one replica is expected to handle, in thousandths of the unit.
+	// +optional
+	MilliValue int64 `json:"milliValue,omitempty"`
+}
+
+// PodResources holds the sum of the resource requests of the containers in one replica of the revision.
+type PodResources struct {
+	// CPUMilliValue is the requested cpu in millicores.
+	// +optional
+	CPUMilliValue int64 `json:"cpuMilliValue,omitempty"`
+
+	// MemoryValue is the requested memory in bytes.
+	// +optional
+	MemoryValue int64 `json:"memoryValue,omitempty"`
 }
 
 // StageTarget holds the information of all revisions during the transition for the current stage.
@@ -87,6 +121,111 @@ type StageTarget struct {
 	// RolloutStrategy indicates the mode to roll out the new revision progressively. It is either availability or resourceUtil.
 	// +optional
 	RolloutStrategy string `json:"rolloutStrategy,omitempty"`
+
+	// PodTerminationPolicy determines how the terminating pods of the revisions scaling down are deleted.
+	// +optional
+	PodTerminationPolicy *PodTerminationPolicy `json:"podTerminationPolicy,omitempty"`
+
+	// ProgressiveRolloutDisabled indicates the progressive rollout has been disabled for the service. The
+	// StagePodAutoscalers stop limiting the revisions, and the rollout is finalized at once.
+	// +optional
+	ProgressiveRolloutDisabled bool `json:"progressiveRolloutDisabled,omitempty"`
+
+	// StageWarmUp determines whether the revision scaling up is pre-scaled to the load of its traffic share, before
+	// the traffic moves. It is nil, if the warm-up is disabled.
+	// +optional
+	StageWarmUp *StageWarmUp `json:"stageWarmUp,omitempty"`
+
+	// PostRolloutStandby determines whether the initial revisions keep some replicas on standby after their traffic
+	// has moved away, so that the traffic is able to go back to them without a cold start. It is nil, if the
+	// standby is disabled.
+	// +optional
+	PostRolloutStandby *PostRolloutStandby `json:"postRolloutStandby,omitempty"`
+
+	// StageScaleUpTolerance is the number or the percentage of the target replicas of the revision scaling up,
+	// that may still be missing, when the stage is considered scaled up. At least one replica is always required.
+	// It is nil, if all the target replicas are required.
+	// +optional
+	StageScaleUpTolerance *intstr.IntOrString `json:"stageScaleUpTolerance,omitempty"`
+
+	// Promotion records the promotion of the rollout straight to its final stage, skipping the remaining stages.
+	// It is nil, if the current rollout has not been promoted to the final stage.
+	// +optional
+	Promotion *RolloutPromotion `json:"promotion,omitempty"`
+
+	// Superseding records how the superseding policy has handled the revision created in the middle of the
+	// rollout. It is nil, if no revision has been created in the middle of the current rollout.
+	// +optional
+	Superseding *RolloutSuperseding `json:"superseding,omitempty"`
+}
+
+// StageWarmUp holds the configuration about how the revision scaling up is pre-scaled in each stage.
+type StageWarmUp struct {
+	// Replicas is the number of replicas the revision scaling up is pre-scaled to in the current stage. It is
+	// calculated from the desired scale of the revisions scaling down, and reset to 0, when the boost is released.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// StabilizationSeconds is the duration in seconds, for which the pre-scaled replicas are kept after the stage
+	// is ready, so that the autoscaler of the revision has collected enough metrics to take over.
+	// +optional
+	StabilizationSeconds int32 `json:"stabilizationSeconds,omitempty"`
+}
+
+// PostRolloutStandby holds the configuration about how the initial revisions are kept on standby after the rollout.
+type PostRolloutStandby struct {
+	// Replicas is the number of replicas each initial revision keeps, once its traffic has moved away. It is reset
+	// to 0, when the standby is released after the bake period.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// BakeSeconds is the duration in seconds, for which the replicas on standby are kept after the last stage
+	// is complete.
+	// +optional
+	BakeSeconds int32 `json:"bakeSeconds,omitempty"`
+}
+
+// RolloutPromotion holds the information about who promoted the rollout to its final stage, and when.
+type RolloutPromotion struct {
+	// PromotedBy is the user who promoted the rollout.
+	// +optional
+	PromotedBy string `json:"promotedBy,omitempty"`
+
+	// PromotedTime is the time the promotion was accepted.
+	// +optional
+	PromotedTime metav1.Time `json:"promotedTime,omitempty"`
+}
+
+// RolloutSuperseding holds the information about the revision created in the middle of a rollout, and the
+// superseding policy applied to it.
+type RolloutSuperseding struct {
+	// Policy is the superseding policy applied to the revision. It is supersede, queue or debounce.
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// Revision is the name of the revision created in the middle of the rollout.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// SupersededRevision is the name of the revision the superseded rollout was going to. It is scaled down before
+	// the other revisions. It is empty, as long as the revision created in the middle of the rollout is held back.
+	// +optional
+	SupersededRevision string `json:"supersededRevision,omitempty"`
+}
+
+// PodTerminationPolicy holds the configuration about how the terminating pods are deleted during the rollout.
+type PodTerminationPolicy struct {
+	// Mode is one of graceful, force-after-timeout or force-immediately. The graceful mode never force-deletes
+	// the pods. The force-after-timeout mode force-deletes the pods, when their grace period has expired, or in
+	// the first stage of the resourceUtil strategy. The force-immediately mode force-deletes the pods as soon as
+	// they start terminating.
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// MaxForcedDeletionsPerStage sets the upper bound for the number of the pods force-deleted in one stage.
+	// 0 means no limit.
+	// +optional
+	MaxForcedDeletionsPerStage int32 `json:"maxForcedDeletionsPerStage,omitempty"`
 }
 
 // RolloutOrchestratorSpec holds the desired state of the RolloutOrchestrator (from the client).
@@ -152,6 +291,72 @@ type RolloutOrchestratorStatusFields struct {
 	// StageRevisionStatus holds the traffic split.
 	// +optional
 	StageRevisionStatus []TargetRevision `json:"stageRevisionStatus,omitempty"`
+
+	// ScaleUpObservation holds the measured durations of the scaling up phases of the stages. It is used to
+	// calculate the target finish time of the further stages.
+	// +optional
+	ScaleUpObservation *ScaleUpObservation `json:"scaleUpObservation,omitempty"`
+
+	// StageDeltaMultiplier is the factor applied to the traffic and replicas shifted in the next stage, when the
+	// stages are accelerated. It doubles after each stage finished well before its deadline, and resets after
+	// a slow or failed stage. 0 means the same as 1.
+	// +optional
+	StageDeltaMultiplier int32 `json:"stageDeltaMultiplier,omitempty"`
+
+	// ForcedDeletions is the number of the pods force-deleted in the current stage.
+	// +optional
+	ForcedDeletions int32 `json:"forcedDeletions,omitempty"`
+
+	// ScaleUpShortfall is the number of the replicas the revisions scaling up still miss in the current stage. The
+	// stage is scaled up with a shortfall, as long as it is within the StageScaleUpTolerance.
+	// +optional
+	ScaleUpShortfall int32 `json:"scaleUpShortfall,omitempty"`
+
+	// Notified records the state of the rollout the CloudEvents have been sent for. The CloudEvents are sent for
+	// the changes of the persisted status since then, so that they are not sent again after a failed update or a
+	// restart of the controller.
+	// +optional
+	Notified *RolloutNotified `json:"notified,omitempty"`
+}
+
+// RolloutNotified holds the state of the rollout the CloudEvents have been sent for.
+type RolloutNotified struct {
+	// StageReady is the status of the condition StageReady.
+	// +optional
+	StageReady corev1.ConditionStatus `json:"stageReady,omitempty"`
+
+	// LastStageComplete is the status of the condition LastStageComplete.
+	// +optional
+	LastStageComplete corev1.ConditionStatus `json:"lastStageComplete,omitempty"`
+
+	// PausedBy is the value of the annotation pausing the rollout. It is empty, if the rollout is not paused.
+	// +optional
+	PausedBy string `json:"pausedBy,omitempty"`
+}
+
+// ScaleUpObservation holds the statistics of how long the revisions take to scale up in the stages. The statistics
+// cover the latest ScaleUpObservationWindow scaling up phases.
+type ScaleUpObservation struct {
+	// LastMilliseconds is the duration in milliseconds of the last scaling up phase.
+	// +optional
+	LastMilliseconds int64 `json:"lastMilliseconds,omitempty"`
+
+	// AverageMilliseconds is the average of the durations in milliseconds of the scaling up phases in the window.
+	// +optional
+	AverageMilliseconds int64 `json:"averageMilliseconds,omitempty"`
+
+	// MaxMilliseconds is the longest duration in milliseconds of the scaling up phases in the window.
+	// +optional
+	MaxMilliseconds int64 `json:"maxMilliseconds,omitempty"`
+
+	// Samples is the number of the observed scaling up phases in the window.
+	// +optional
+	Samples int32 `json:"samples,omitempty"`
+
+	// WindowMilliseconds holds the durations in milliseconds of the scaling up phases in the window, the oldest
+	// first.
+	// +optional
+	WindowMilliseconds []int64 `json:"windowMilliseconds,omitempty"`
 }
 
 // RolloutOrchestratorStatus communicates the observed state of the RolloutOrchestrator (from the controller).
@@ -58,6 +58,31 @@ type StagePodAutoscalerStatus struct {
 
 	// ReplicasTerminating shows the actual number of replicas being terminated.
 	ReplicasTerminating *int32 `json:"replicasTerminating,omitempty"`
+
+	// ReplicasReady shows the number of replicas running and ready to serve the traffic.
+	// +optional
+	ReplicasReady *int32 `json:"replicasReady,omitempty"`
+
+	// ReplicasNotReady shows the number of replicas running, but not ready yet.
+	// +optional
+	ReplicasNotReady *int32 `json:"replicasNotReady,omitempty"`
+
+	// ReplicasPending shows the number of replicas not scheduled or not started yet.
+	// +optional
+	ReplicasPending *int32 `json:"replicasPending,omitempty"`
+
+	// EffectiveMinScale shows the lower bound for the number of the replicas, actually applied by the autoscaler.
+	// +optional
+	EffectiveMinScale *int32 `json:"effectiveMinScale,omitempty"`
+
+	// EffectiveMaxScale shows the upper bound for the number of the replicas, actually applied by the autoscaler.
+	// 0 means no limit.
+	// +optional
+	EffectiveMaxScale *int32 `json:"effectiveMaxScale,omitempty"`
+
+	// LastPodReadyTime shows the last time one of the replicas became ready.
+	// +optional
+	LastPodReadyTime *metav1.Time `json:"lastPodReadyTime,omitempty"`
 }
 
 // Verify that StagePodAutoscaler adheres to the appropriate interfaces.
@@ -23,8 +23,232 @@ package v1
 
 import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodCapacity) DeepCopyInto(out *PodCapacity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodCapacity.
+func (in *PodCapacity) DeepCopy() *PodCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(PodCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodResources) DeepCopyInto(out *PodResources) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodResources.
+func (in *PodResources) DeepCopy() *PodResources {
+	if in == nil {
+		return nil
+	}
+	out := new(PodResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodTerminationPolicy) DeepCopyInto(out *PodTerminationPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodTerminationPolicy.
+func (in *PodTerminationPolicy) DeepCopy() *PodTerminationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PodTerminationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostRolloutStandby) DeepCopyInto(out *PostRolloutStandby) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostRolloutStandby.
+func (in *PostRolloutStandby) DeepCopy() *PostRolloutStandby {
+	if in == nil {
+		return nil
+	}
+	out := new(PostRolloutStandby)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutGroup) DeepCopyInto(out *RolloutGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutGroup.
+func (in *RolloutGroup) DeepCopy() *RolloutGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RolloutGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutGroupList) DeepCopyInto(out *RolloutGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RolloutGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutGroupList.
+func (in *RolloutGroupList) DeepCopy() *RolloutGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RolloutGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutGroupMember) DeepCopyInto(out *RolloutGroupMember) {
+	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutGroupMember.
+func (in *RolloutGroupMember) DeepCopy() *RolloutGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutGroupMemberStatus) DeepCopyInto(out *RolloutGroupMemberStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutGroupMemberStatus.
+func (in *RolloutGroupMemberStatus) DeepCopy() *RolloutGroupMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutGroupMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutGroupSpec) DeepCopyInto(out *RolloutGroupSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]RolloutGroupMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutGroupSpec.
+func (in *RolloutGroupSpec) DeepCopy() *RolloutGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutGroupStatus) DeepCopyInto(out *RolloutGroupStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.MemberStatuses != nil {
+		in, out := &in.MemberStatuses, &out.MemberStatuses
+		*out = make([]RolloutGroupMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutGroupStatus.
+func (in *RolloutGroupStatus) DeepCopy() *RolloutGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutNotified) DeepCopyInto(out *RolloutNotified) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutNotified.
+func (in *RolloutNotified) DeepCopy() *RolloutNotified {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutNotified)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutOrchestrator) DeepCopyInto(out *RolloutOrchestrator) {
 	*out = *in
@@ -145,6 +369,16 @@ func (in *RolloutOrchestratorStatusFields) DeepCopyInto(out *RolloutOrchestrator
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ScaleUpObservation != nil {
+		in, out := &in.ScaleUpObservation, &out.ScaleUpObservation
+		*out = new(ScaleUpObservation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notified != nil {
+		in, out := &in.Notified, &out.Notified
+		*out = new(RolloutNotified)
+		**out = **in
+	}
 	return
 }
 
@@ -158,6 +392,60 @@ func (in *RolloutOrchestratorStatusFields) DeepCopy() *RolloutOrchestratorStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutPromotion) DeepCopyInto(out *RolloutPromotion) {
+	*out = *in
+	in.PromotedTime.DeepCopyInto(&out.PromotedTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutPromotion.
+func (in *RolloutPromotion) DeepCopy() *RolloutPromotion {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutPromotion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSuperseding) DeepCopyInto(out *RolloutSuperseding) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSuperseding.
+func (in *RolloutSuperseding) DeepCopy() *RolloutSuperseding {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSuperseding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleUpObservation) DeepCopyInto(out *ScaleUpObservation) {
+	*out = *in
+	if in.WindowMilliseconds != nil {
+		in, out := &in.WindowMilliseconds, &out.WindowMilliseconds
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleUpObservation.
+func (in *ScaleUpObservation) DeepCopy() *ScaleUpObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleUpObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StagePodAutoscaler) DeepCopyInto(out *StagePodAutoscaler) {
 	*out = *in
@@ -264,6 +552,35 @@ func (in *StagePodAutoscalerStatus) DeepCopyInto(out *StagePodAutoscalerStatus)
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ReplicasReady != nil {
+		in, out := &in.ReplicasReady, &out.ReplicasReady
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicasNotReady != nil {
+		in, out := &in.ReplicasNotReady, &out.ReplicasNotReady
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicasPending != nil {
+		in, out := &in.ReplicasPending, &out.ReplicasPending
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EffectiveMinScale != nil {
+		in, out := &in.EffectiveMinScale, &out.EffectiveMinScale
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EffectiveMaxScale != nil {
+		in, out := &in.EffectiveMaxScale, &out.EffectiveMaxScale
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastPodReadyTime != nil {
+		in, out := &in.LastPodReadyTime, &out.LastPodReadyTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -288,6 +605,36 @@ func (in *StageTarget) DeepCopyInto(out *StageTarget) {
 		}
 	}
 	in.TargetFinishTime.DeepCopyInto(&out.TargetFinishTime)
+	if in.PodTerminationPolicy != nil {
+		in, out := &in.PodTerminationPolicy, &out.PodTerminationPolicy
+		*out = new(PodTerminationPolicy)
+		**out = **in
+	}
+	if in.StageWarmUp != nil {
+		in, out := &in.StageWarmUp, &out.StageWarmUp
+		*out = new(StageWarmUp)
+		**out = **in
+	}
+	if in.PostRolloutStandby != nil {
+		in, out := &in.PostRolloutStandby, &out.PostRolloutStandby
+		*out = new(PostRolloutStandby)
+		**out = **in
+	}
+	if in.StageScaleUpTolerance != nil {
+		in, out := &in.StageScaleUpTolerance, &out.StageScaleUpTolerance
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(RolloutPromotion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Superseding != nil {
+		in, out := &in.Superseding, &out.Superseding
+		*out = new(RolloutSuperseding)
+		**out = **in
+	}
 	return
 }
 
@@ -301,6 +648,22 @@ func (in *StageTarget) DeepCopy() *StageTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageWarmUp) DeepCopyInto(out *StageWarmUp) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageWarmUp.
+func (in *StageWarmUp) DeepCopy() *StageWarmUp {
+	if in == nil {
+		return nil
+	}
+	out := new(StageWarmUp)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetRevision) DeepCopyInto(out *TargetRevision) {
 	*out = *in
@@ -320,6 +683,16 @@ func (in *TargetRevision) DeepCopyInto(out *TargetRevision) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.PodCapacity != nil {
+		in, out := &in.PodCapacity, &out.PodCapacity
+		*out = new(PodCapacity)
+		**out = **in
+	}
+	if in.PodResources != nil {
+		in, out := &in.PodResources, &out.PodResources
+		*out = new(PodResources)
+		**out = **in
+	}
 	return
 }
 
@@ -0,0 +1,225 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisionstats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+)
+
+const (
+	// tokenReviewTTL is how long the result of a successful token review is cached.
+	tokenReviewTTL = time.Minute
+
+	// ForwardedHeader marks the request forwarded to the autoscaler owning the bucket of the revision, so that it
+	// is never forwarded again.
+	ForwardedHeader = "K-Revision-Stats-Forwarded"
+
+	// TLSCertFileEnvKey and TLSKeyFileEnvKey are the environment variables of the autoscaler, that set the files of
+	// the certificate and the key the revision stats are served with over TLS. Without them, the revision stats are
+	// not served, since the bearer tokens of the requests must not be sent in plain text.
+	TLSCertFileEnvKey = "REVISION_STATS_TLS_CERT_FILE"
+	TLSKeyFileEnvKey  = "REVISION_STATS_TLS_KEY_FILE"
+
+	// TLSCAFileEnvKey is the environment variable of the autoscaler, that sets the file of the CA certificate to
+	// verify the autoscalers the requests are forwarded to. The system roots are used, if it is empty.
+	TLSCAFileEnvKey = "REVISION_STATS_TLS_CA_FILE"
+)
+
+var (
+	errTokenReview     = errors.New("failed to review the token")
+	errUnauthenticated = errors.New("the token is not authenticated")
+	errForbidden       = errors.New("the user is not allowed to read the revision stats")
+	errNotTLS          = errors.New("the revision stats are only forwarded over TLS")
+)
+
+// Handler serves the stats of the revisions in the Store at analysis.StatsPath{namespace}/{revision}. The requests
+// are authenticated with the bearer tokens, reviewed by the Kubernetes API server, and only the allowed users are
+// authorized. The requests for the revisions owned by another autoscaler are forwarded to it over TLS.
+type Handler struct {
+	store        *Store
+	kubeClient   kubernetes.Interface
+	allowedUsers sets.Set[string]
+	logger       *zap.SugaredLogger
+	owner        OwnerFunc
+	transport    http.RoundTripper
+
+	// now returns the current time, replaced in the tests.
+	now func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]time.Time
+}
+
+// NewHandler creates a Handler serving the stats in the store to the allowed users.
+func NewHandler(store *Store, kubeClient kubernetes.Interface, allowedUsers sets.Set[string],
+	logger *zap.SugaredLogger) *Handler {
+	return &Handler{
+		store:        store,
+		kubeClient:   kubeClient,
+		allowedUsers: allowedUsers,
+		logger:       logger,
+		now:          time.Now,
+		tokens:       make(map[string]time.Time),
+	}
+}
+
+// SetOwner sets the OwnerFunc to find the autoscaler owning the bucket of a revision. Without it, only the stats
+// of this autoscaler are served.
+func (h *Handler) SetOwner(owner OwnerFunc) {
+	h.owner = owner
+}
+
+// SetTransport sets the transport to forward the requests to the other autoscalers, e.g. with the TLS configuration
+// verifying them. Without it, http.DefaultTransport is used.
+func (h *Handler) SetTransport(transport http.RoundTripper) {
+	h.transport = transport
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	if status, err := h.authorize(r.Context(), token); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, analysis.StatsPath), "/")
+	if !strings.HasPrefix(r.URL.Path, analysis.StatsPath) || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	window := analysis.DefaultAutoscalerWindow
+	if val := r.URL.Query().Get("window"); val != "" {
+		var err error
+		if window, err = time.ParseDuration(val); err != nil || window <= 0 || window > MaxWindow {
+			http.Error(w, "window must be a duration between 0 and "+MaxWindow.String(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Only the autoscaler owning the bucket of the revision receives its stats, so the request is forwarded to it.
+	key := types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+	if h.owner != nil && r.Header.Get(ForwardedHeader) == "" {
+		addr, err := h.owner(key)
+		if err != nil {
+			h.logger.Errorw("Failed to find the owner of the revision stats", zap.Error(err))
+			http.Error(w, "the owner of the revision stats is not available", http.StatusServiceUnavailable)
+			return
+		}
+		if addr != "" {
+			h.forward(w, r, addr)
+			return
+		}
+	}
+	stats, ok := h.store.Stats(key, window, h.now())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.logger.Errorw("Failed to write the revision stats", zap.Error(err))
+	}
+}
+
+// forward proxies the request to the revision stats server at addr. The token is reviewed by that server again, so
+// the request is only forwarded over TLS.
+func (h *Handler) forward(w http.ResponseWriter, r *http.Request, addr string) {
+	target, err := url.Parse(addr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if target.Scheme != "https" {
+		h.logger.Errorw("Failed to forward the request for the revision stats", zap.String("owner", addr),
+			zap.Error(errNotTLS))
+		http.Error(w, errNotTLS.Error(), http.StatusBadGateway)
+		return
+	}
+	proxy := &httputil.ReverseProxy{
+		Transport: h.transport,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.Header.Set(ForwardedHeader, "true")
+		},
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+			h.logger.Errorw("Failed to forward the request for the revision stats", zap.String("owner", addr),
+				zap.Error(err))
+			http.Error(w, "failed to reach the owner of the revision stats", http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// authorize reviews the token, and checks whether its user is allowed. It returns the HTTP status to respond
+// with the error.
+func (h *Handler) authorize(ctx context.Context, token string) (int, error) {
+	now := h.now()
+	h.mu.Lock()
+	expiry, ok := h.tokens[token]
+	h.mu.Unlock()
+	if ok && now.Before(expiry) {
+		return http.StatusOK, nil
+	}
+
+	review, err := h.kubeClient.AuthenticationV1().TokenReviews().Create(ctx,
+		&authv1.TokenReview{Spec: authv1.TokenReviewSpec{Token: token}}, metav1.CreateOptions{})
+	if err != nil {
+		h.logger.Errorw("Failed to review the token", zap.Error(err))
+		return http.StatusInternalServerError, errTokenReview
+	}
+	if !review.Status.Authenticated {
+		return http.StatusUnauthorized, errUnauthenticated
+	}
+	if !h.allowedUsers.Has(review.Status.User.Username) {
+		return http.StatusForbidden, errForbidden
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for cached, expiry := range h.tokens {
+		if !now.Before(expiry) {
+			delete(h.tokens, cached)
+		}
+	}
+	h.tokens[token] = now.Add(tokenReviewTTL)
+	return http.StatusOK, nil
+}
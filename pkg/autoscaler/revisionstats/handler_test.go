@@ -0,0 +1,237 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisionstats
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
+)
+
+func TestHandler(t *testing.T) {
+	now := time.Unix(10000, 0)
+	users := map[string]string{
+		"controller-token": "system:serviceaccount:knative-serving:controller",
+		"other-token":      "system:serviceaccount:default:default",
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		ExpectedStatus int
+		ExpectedResult *analysis.RevisionStats
+	}{{
+		name:           "Test the stats of the revision",
+		method:         http.MethodGet,
+		path:           "/stats/default/test-00002?window=30s",
+		token:          "controller-token",
+		ExpectedStatus: http.StatusOK,
+		ExpectedResult: &analysis.RevisionStats{
+			Namespace:          "default",
+			Revision:           "test-00002",
+			WindowSeconds:      30,
+			Pods:               1,
+			RequestCount:       5,
+			AverageConcurrency: 2,
+		},
+	}, {
+		name:           "Test the revision without stats",
+		method:         http.MethodGet,
+		path:           "/stats/default/test-00001",
+		token:          "controller-token",
+		ExpectedStatus: http.StatusNotFound,
+	}, {
+		name:           "Test the invalid path",
+		method:         http.MethodGet,
+		path:           "/stats/default",
+		token:          "controller-token",
+		ExpectedStatus: http.StatusNotFound,
+	}, {
+		name:           "Test the window longer than the max window",
+		method:         http.MethodGet,
+		path:           "/stats/default/test-00002?window=1h",
+		token:          "controller-token",
+		ExpectedStatus: http.StatusBadRequest,
+	}, {
+		name:           "Test the request without token",
+		method:         http.MethodGet,
+		path:           "/stats/default/test-00002",
+		ExpectedStatus: http.StatusUnauthorized,
+	}, {
+		name:           "Test the unauthenticated token",
+		method:         http.MethodGet,
+		path:           "/stats/default/test-00002",
+		token:          "invalid-token",
+		ExpectedStatus: http.StatusUnauthorized,
+	}, {
+		name:           "Test the user not allowed",
+		method:         http.MethodGet,
+		path:           "/stats/default/test-00002",
+		token:          "other-token",
+		ExpectedStatus: http.StatusForbidden,
+	}, {
+		name:           "Test the method not allowed",
+		method:         http.MethodPost,
+		path:           "/stats/default/test-00002",
+		token:          "controller-token",
+		ExpectedStatus: http.StatusMethodNotAllowed,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeClient := fakekubeclient.NewSimpleClientset()
+			kubeClient.PrependReactor("create", "tokenreviews",
+				func(action ktesting.Action) (bool, runtime.Object, error) {
+					review := action.(ktesting.CreateAction).GetObject().(*authv1.TokenReview)
+					if user, ok := users[review.Spec.Token]; ok {
+						review.Status.Authenticated = true
+						review.Status.User.Username = user
+					}
+					return true, review, nil
+				})
+			store := NewStore()
+			store.Record(types.NamespacedName{Namespace: "default", Name: "test-00002"}, now.Add(-10*time.Second),
+				asmetrics.Stat{PodName: "pod-a", RequestCount: 5, AverageConcurrentRequests: 2})
+			handler := NewHandler(store, kubeClient, sets.New("system:serviceaccount:knative-serving:controller"),
+				logtesting.TestLogger(t))
+			handler.now = func() time.Time { return now }
+
+			req := httptest.NewRequest(test.method, test.path, nil)
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != test.ExpectedStatus {
+				t.Fatalf("Status of ServeHTTP() = %v, want %v", rec.Code, test.ExpectedStatus)
+			}
+			if test.ExpectedResult != nil {
+				r := &analysis.RevisionStats{}
+				if err := json.NewDecoder(rec.Body).Decode(r); err != nil {
+					t.Fatalf("Failed to decode the stats: %v", err)
+				}
+				if !reflect.DeepEqual(r, test.ExpectedResult) {
+					t.Fatalf("Result of ServeHTTP() = %v, want %v", r, test.ExpectedResult)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerCachesTokenReviews(t *testing.T) {
+	now := time.Unix(10000, 0)
+	reviews := 0
+	kubeClient := fakekubeclient.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(ktesting.CreateAction).GetObject().(*authv1.TokenReview)
+		review.Status.Authenticated = true
+		review.Status.User.Username = "controller"
+		return true, review, nil
+	})
+	handler := NewHandler(NewStore(), kubeClient, sets.New("controller"), logtesting.TestLogger(t))
+
+	for _, offset := range []time.Duration{0, 30 * time.Second, 2 * time.Minute} {
+		handler.now = func() time.Time { return now.Add(offset) }
+		req := httptest.NewRequest(http.MethodGet, "/stats/default/test-00002", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if reviews != 2 {
+		t.Fatalf("Number of the token reviews = %v, want %v", reviews, 2)
+	}
+}
+
+func TestHandlerForwardsToOwner(t *testing.T) {
+	now := time.Unix(10000, 0)
+	kubeClient := fakekubeclient.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authv1.TokenReview)
+		review.Status.Authenticated = true
+		review.Status.User.Username = "controller"
+		return true, review, nil
+	})
+	ownerStore := NewStore()
+	ownerStore.Record(types.NamespacedName{Namespace: "default", Name: "test-00002"}, now.Add(-10*time.Second),
+		asmetrics.Stat{PodName: "pod-a", RequestCount: 5, AverageConcurrentRequests: 2})
+	ownerHandler := NewHandler(ownerStore, kubeClient, sets.New("controller"), logtesting.TestLogger(t))
+	ownerHandler.now = func() time.Time { return now }
+	// The owner would forward the request back, if the forwarded request was not marked.
+	ownerHandler.SetOwner(func(types.NamespacedName) (string, error) { return "https://invalid", nil })
+	owner := httptest.NewTLSServer(ownerHandler)
+	defer owner.Close()
+	// The token must never be forwarded in plain text.
+	plain := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("The request was forwarded over HTTP with the Authorization %q", r.Header.Get("Authorization"))
+	}))
+	defer plain.Close()
+
+	tests := []struct {
+		name           string
+		owner          OwnerFunc
+		ExpectedStatus int
+	}{{
+		name:           "Test the revision owned by another autoscaler",
+		owner:          func(types.NamespacedName) (string, error) { return owner.URL, nil },
+		ExpectedStatus: http.StatusOK,
+	}, {
+		name:           "Test the revision owned by this autoscaler",
+		owner:          func(types.NamespacedName) (string, error) { return "", nil },
+		ExpectedStatus: http.StatusNotFound,
+	}, {
+		name:           "Test the revision without owner",
+		owner:          func(types.NamespacedName) (string, error) { return "", errors.New("no owner") },
+		ExpectedStatus: http.StatusServiceUnavailable,
+	}, {
+		name:           "Test the owner not reachable",
+		owner:          func(types.NamespacedName) (string, error) { return "https://127.0.0.1:1", nil },
+		ExpectedStatus: http.StatusBadGateway,
+	}, {
+		name:           "Test the owner over plain HTTP",
+		owner:          func(types.NamespacedName) (string, error) { return plain.URL, nil },
+		ExpectedStatus: http.StatusBadGateway,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewHandler(NewStore(), kubeClient, sets.New("controller"), logtesting.TestLogger(t))
+			handler.SetOwner(test.owner)
+			handler.SetTransport(owner.Client().Transport)
+			req := httptest.NewRequest(http.MethodGet, "/stats/default/test-00002", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != test.ExpectedStatus {
+				t.Fatalf("Status of ServeHTTP() = %v, want %v", rec.Code, test.ExpectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisionstats
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/types"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"knative.dev/pkg/hash"
+)
+
+// OwnerFunc returns the address of the revision stats server of the autoscaler owning the bucket of the revision.
+// It returns an empty address, if the bucket is owned by this autoscaler. The revision stats are served over TLS.
+type OwnerFunc func(rev types.NamespacedName) (string, error)
+
+// LeaseOwner returns the OwnerFunc for the autoscalers running with the Lease based leader election. The
+// autoscaler holding the Lease of a bucket points the Endpoints named after the bucket to its own IP, which is
+// how the stats are forwarded to it as well.
+func LeaseOwner(bs *hash.BucketSet, isBucketOwner func(string) bool,
+	endpointsLister corev1listers.EndpointsNamespaceLister, port string) OwnerFunc {
+	return func(rev types.NamespacedName) (string, error) {
+		bkt := bs.Owner(rev.String())
+		if isBucketOwner(bkt) {
+			return "", nil
+		}
+		endpoints, err := endpointsLister.Get(bkt)
+		if err != nil {
+			return "", err
+		}
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				return "https://" + net.JoinHostPort(address.IP, port), nil
+			}
+		}
+		return "", fmt.Errorf("the bucket %s has no owner", bkt)
+	}
+}
+
+// StatefulSetOwner returns the OwnerFunc for the autoscalers running as a StatefulSet. The name of each bucket is
+// the address of the autoscaler pod owning it.
+func StatefulSetOwner(bs *hash.BucketSet, isBucketOwner func(string) bool, port string) OwnerFunc {
+	return func(rev types.NamespacedName) (string, error) {
+		bkt := bs.Owner(rev.String())
+		if isBucketOwner(bkt) {
+			return "", nil
+		}
+		u, err := url.Parse(bkt)
+		if err != nil {
+			return "", err
+		}
+		return "https://" + net.JoinHostPort(u.Hostname(), port), nil
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisionstats
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/hash"
+)
+
+func TestLeaseOwner(t *testing.T) {
+	rev := types.NamespacedName{Namespace: "default", Name: "test-00002"}
+	tests := []struct {
+		name           string
+		owned          bool
+		endpoints      *corev1.Endpoints
+		ExpectedResult string
+		ExpectedError  bool
+	}{{
+		name:           "Test the bucket owned by this autoscaler",
+		owned:          true,
+		ExpectedResult: "",
+	}, {
+		name: "Test the bucket owned by another autoscaler",
+		endpoints: &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "autoscaler-bucket-00-of-01", Namespace: "knative-serving"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}},
+			}},
+		},
+		ExpectedResult: "https://10.0.0.2:8090",
+	}, {
+		name: "Test the bucket without address",
+		endpoints: &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "autoscaler-bucket-00-of-01", Namespace: "knative-serving"},
+		},
+		ExpectedError: true,
+	}, {
+		name:          "Test the bucket without endpoints",
+		ExpectedError: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if test.endpoints != nil {
+				indexer.Add(test.endpoints)
+			}
+			bs := hash.NewBucketSet(sets.New("autoscaler-bucket-00-of-01"))
+			owner := LeaseOwner(bs, func(string) bool { return test.owned },
+				corev1listers.NewEndpointsLister(indexer).Endpoints("knative-serving"), "8090")
+			r, err := owner(rev)
+			if (err != nil) != test.ExpectedError {
+				t.Fatalf("Error of LeaseOwner() = %v, want error %v", err, test.ExpectedError)
+			}
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of LeaseOwner() = %q, want %q", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestStatefulSetOwner(t *testing.T) {
+	rev := types.NamespacedName{Namespace: "default", Name: "test-00002"}
+	bkt := "http://autoscaler-1.autoscaler.knative-serving.svc.cluster.local:8080"
+	tests := []struct {
+		name           string
+		owned          bool
+		ExpectedResult string
+	}{{
+		name:           "Test the bucket owned by this autoscaler",
+		owned:          true,
+		ExpectedResult: "",
+	}, {
+		name:           "Test the bucket owned by another autoscaler",
+		ExpectedResult: "https://autoscaler-1.autoscaler.knative-serving.svc.cluster.local:8090",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			owner := StatefulSetOwner(hash.NewBucketSet(sets.New(bkt)), func(string) bool { return test.owned },
+				"8090")
+			r, err := owner(rev)
+			if err != nil {
+				t.Fatalf("Error of StatefulSetOwner() = %v", err)
+			}
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of StatefulSetOwner() = %q, want %q", r, test.ExpectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package revisionstats aggregates the stats received by the autoscaler per revision over a window, and serves
+// them to the rollout controller.
+package revisionstats
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
+)
+
+// MaxWindow is the longest window of the stats kept for each revision.
+const MaxWindow = analysis.MaxAutoscalerWindow
+
+type sample struct {
+	time time.Time
+	stat asmetrics.Stat
+}
+
+// Store keeps the stats of the revisions received in the last MaxWindow.
+type Store struct {
+	mu        sync.RWMutex
+	revisions map[types.NamespacedName][]sample
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		revisions: make(map[types.NamespacedName][]sample),
+	}
+}
+
+// Record records the stat of the revision with the key, received at the time, and drops the stats of the revision
+// older than MaxWindow.
+func (s *Store) Record(key types.NamespacedName, now time.Time, stat asmetrics.Stat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revisions[key] = append(expire(s.revisions[key], now.Add(-MaxWindow)), sample{time: now, stat: stat})
+}
+
+// Prune drops the stats older than MaxWindow, and the revisions without any stats left.
+func (s *Store) Prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, samples := range s.revisions {
+		if samples = expire(samples, now.Add(-MaxWindow)); len(samples) == 0 {
+			delete(s.revisions, key)
+		} else {
+			s.revisions[key] = samples
+		}
+	}
+}
+
+// Stats aggregates the stats of the revision with the key received in the window before now. The counts of the
+// requests are summed up, and the concurrency is the sum of the average concurrency of each pod. It returns false,
+// if there is no stat in the window.
+func (s *Store) Stats(key types.NamespacedName, window time.Duration, now time.Time) (*analysis.RevisionStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &analysis.RevisionStats{
+		Namespace:     key.Namespace,
+		Revision:      key.Name,
+		WindowSeconds: window.Seconds(),
+	}
+	type podStat struct {
+		count, concurrency, proxiedConcurrency float64
+	}
+	pods := make(map[string]*podStat)
+	since := now.Add(-window)
+	for _, sample := range s.revisions[key] {
+		if !sample.time.After(since) || sample.time.After(now) {
+			continue
+		}
+		stats.RequestCount += sample.stat.RequestCount
+		stats.ProxiedRequestCount += sample.stat.ProxiedRequestCount
+		pod, ok := pods[sample.stat.PodName]
+		if !ok {
+			pod = &podStat{}
+			pods[sample.stat.PodName] = pod
+		}
+		pod.count++
+		pod.concurrency += sample.stat.AverageConcurrentRequests
+		pod.proxiedConcurrency += sample.stat.AverageProxiedConcurrentRequests
+	}
+	if len(pods) == 0 {
+		return nil, false
+	}
+
+	stats.Pods = len(pods)
+	for _, pod := range pods {
+		stats.AverageConcurrency += pod.concurrency / pod.count
+		stats.AverageProxiedConcurrency += pod.proxiedConcurrency / pod.count
+	}
+	return stats, true
+}
+
+// expire drops the samples received before the time. The samples are in the order they are received.
+func expire(samples []sample, before time.Time) []sample {
+	i := 0
+	for i < len(samples) && samples[i].time.Before(before) {
+		i++
+	}
+	return samples[i:]
+}
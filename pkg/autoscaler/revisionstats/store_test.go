@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisionstats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
+)
+
+func TestStoreStats(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "test-00002"}
+	now := time.Unix(10000, 0)
+	stats := []struct {
+		age  time.Duration
+		stat asmetrics.Stat
+	}{
+		{11 * time.Minute, asmetrics.Stat{PodName: "pod-a", RequestCount: 100, AverageConcurrentRequests: 10}},
+		{2 * time.Minute, asmetrics.Stat{PodName: "pod-a", RequestCount: 10, AverageConcurrentRequests: 4}},
+		{50 * time.Second, asmetrics.Stat{PodName: "pod-a", RequestCount: 3, AverageConcurrentRequests: 1}},
+		{40 * time.Second, asmetrics.Stat{PodName: "pod-a", RequestCount: 5, AverageConcurrentRequests: 3}},
+		{30 * time.Second, asmetrics.Stat{PodName: "pod-b", RequestCount: 4, AverageConcurrentRequests: 1,
+			ProxiedRequestCount: 2, AverageProxiedConcurrentRequests: 0.5}},
+	}
+
+	tests := []struct {
+		name           string
+		key            types.NamespacedName
+		window         time.Duration
+		ExpectedResult *analysis.RevisionStats
+		ExpectedFound  bool
+	}{{
+		name:   "Test the stats in the window of one minute",
+		key:    key,
+		window: time.Minute,
+		ExpectedResult: &analysis.RevisionStats{
+			Namespace:                 "default",
+			Revision:                  "test-00002",
+			WindowSeconds:             60,
+			Pods:                      2,
+			RequestCount:              12,
+			ProxiedRequestCount:       2,
+			AverageConcurrency:        3,
+			AverageProxiedConcurrency: 0.5,
+		},
+		ExpectedFound: true,
+	}, {
+		name:   "Test the stats in the max window",
+		key:    key,
+		window: MaxWindow,
+		ExpectedResult: &analysis.RevisionStats{
+			Namespace:                 "default",
+			Revision:                  "test-00002",
+			WindowSeconds:             600,
+			Pods:                      2,
+			RequestCount:              22,
+			ProxiedRequestCount:       2,
+			AverageConcurrency:        3.6666666666666665,
+			AverageProxiedConcurrency: 0.5,
+		},
+		ExpectedFound: true,
+	}, {
+		name:          "Test the stats in the window without stats",
+		key:           key,
+		window:        10 * time.Second,
+		ExpectedFound: false,
+	}, {
+		name:          "Test the stats of an unknown revision",
+		key:           types.NamespacedName{Namespace: "default", Name: "test-00001"},
+		window:        time.Minute,
+		ExpectedFound: false,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := NewStore()
+			for _, s := range stats {
+				store.Record(key, now.Add(-s.age), s.stat)
+			}
+			r, found := store.Stats(test.key, test.window, now)
+			if found != test.ExpectedFound {
+				t.Fatalf("Found of Stats() = %v, want %v", found, test.ExpectedFound)
+			}
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of Stats() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	now := time.Unix(10000, 0)
+	store := NewStore()
+	old := types.NamespacedName{Namespace: "default", Name: "test-00001"}
+	current := types.NamespacedName{Namespace: "default", Name: "test-00002"}
+	store.Record(old, now.Add(-MaxWindow-time.Second), asmetrics.Stat{PodName: "pod-a"})
+	store.Record(current, now.Add(-MaxWindow-time.Second), asmetrics.Stat{PodName: "pod-b"})
+	store.Record(current, now.Add(-time.Second), asmetrics.Stat{PodName: "pod-b"})
+
+	store.Prune(now)
+	if _, ok := store.revisions[old]; ok {
+		t.Fatalf("The stats of %v are not pruned", old)
+	}
+	if got := len(store.revisions[current]); got != 1 {
+		t.Fatalf("Number of the stats of %v = %v, want %v", current, got, 1)
+	}
+}
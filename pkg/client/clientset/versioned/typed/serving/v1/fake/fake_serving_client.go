@@ -28,6 +28,10 @@ type FakeServingV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeServingV1) RolloutGroups(namespace string) v1.RolloutGroupInterface {
+	return newFakeRolloutGroups(c, namespace)
+}
+
 func (c *FakeServingV1) RolloutOrchestrators(namespace string) v1.RolloutOrchestratorInterface {
 	return newFakeRolloutOrchestrators(c, namespace)
 }
@@ -18,6 +18,8 @@ limitations under the License.
 
 package v1
 
+type RolloutGroupExpansion interface{}
+
 type RolloutOrchestratorExpansion interface{}
 
 type StagePodAutoscalerExpansion interface{}
@@ -28,6 +28,7 @@ import (
 
 type ServingV1Interface interface {
 	RESTClient() rest.Interface
+	RolloutGroupsGetter
 	RolloutOrchestratorsGetter
 	StagePodAutoscalersGetter
 }
@@ -37,6 +38,10 @@ type ServingV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *ServingV1Client) RolloutGroups(namespace string) RolloutGroupInterface {
+	return newRolloutGroups(c, namespace)
+}
+
 func (c *ServingV1Client) RolloutOrchestrators(namespace string) RolloutOrchestratorInterface {
 	return newRolloutOrchestrators(c, namespace)
 }
@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// RolloutGroups returns a RolloutGroupInformer.
+	RolloutGroups() RolloutGroupInformer
 	// RolloutOrchestrators returns a RolloutOrchestratorInformer.
 	RolloutOrchestrators() RolloutOrchestratorInformer
 	// StagePodAutoscalers returns a StagePodAutoscalerInformer.
@@ -41,6 +43,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// RolloutGroups returns a RolloutGroupInformer.
+func (v *version) RolloutGroups() RolloutGroupInformer {
+	return &rolloutGroupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // RolloutOrchestrators returns a RolloutOrchestratorInformer.
 func (v *version) RolloutOrchestrators() RolloutOrchestratorInformer {
 	return &rolloutOrchestratorInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1
 
+// RolloutGroupListerExpansion allows custom methods to be added to
+// RolloutGroupLister.
+type RolloutGroupListerExpansion interface{}
+
+// RolloutGroupNamespaceListerExpansion allows custom methods to be added to
+// RolloutGroupNamespaceLister.
+type RolloutGroupNamespaceListerExpansion interface{}
+
 // RolloutOrchestratorListerExpansion allows custom methods to be added to
 // RolloutOrchestratorLister.
 type RolloutOrchestratorListerExpansion interface{}
@@ -0,0 +1,229 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notification delivers the CloudEvents about the progress of the rollouts to a sink.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+)
+
+const (
+	// SinkKey is the key in the ConfigMap config-rolloutorchestrator, that sets the URI the CloudEvents are sent
+	// to, e.g. a Knative Eventing broker or any HTTP endpoint. No CloudEvents are sent, if it is empty.
+	SinkKey = "notification-sink"
+
+	// TypeRolloutStarted is the type of the CloudEvent sent, when a rollout starts.
+	TypeRolloutStarted = "dev.knative.rollout.started"
+
+	// TypeStageAdvanced is the type of the CloudEvent sent, when a rollout moves on to the next stage.
+	TypeStageAdvanced = "dev.knative.rollout.stage.advanced"
+
+	// TypeStageFailed is the type of the CloudEvent sent, when a stage of a rollout fails.
+	TypeStageFailed = "dev.knative.rollout.stage.failed"
+
+	// TypeRolloutPaused is the type of the CloudEvent sent, when a rollout is paused.
+	TypeRolloutPaused = "dev.knative.rollout.paused"
+
+	// TypeRolloutRolledBack is the type of the CloudEvent sent, when the traffic of a rollout has been shifted
+	// back to the initial revisions.
+	TypeRolloutRolledBack = "dev.knative.rollout.rolledback"
+
+	// TypeRolloutCompleted is the type of the CloudEvent sent, when a rollout completes its last stage.
+	TypeRolloutCompleted = "dev.knative.rollout.completed"
+
+	// QueueSize is the number of the CloudEvents waiting for the delivery. The new CloudEvents are dropped, when
+	// the queue is full.
+	QueueSize = 1000
+
+	// MaxRetries is the number of the retries to deliver a CloudEvent, before it is dropped.
+	MaxRetries = 5
+
+	// deliveryTimeout is the timeout of one attempt to deliver a CloudEvent.
+	deliveryTimeout = 10 * time.Second
+)
+
+// Data is the data of the CloudEvents about a rollout.
+type Data struct {
+	Namespace        string              `json:"namespace"`
+	Name             string              `json:"name"`
+	Stage            []v1.TargetRevision `json:"stage,omitempty"`
+	InitialRevisions []v1.TargetRevision `json:"initialRevisions,omitempty"`
+	TargetRevisions  []v1.TargetRevision `json:"targetRevisions,omitempty"`
+	Message          string              `json:"message,omitempty"`
+}
+
+type event struct {
+	id        string
+	eventType string
+	source    string
+	subject   string
+	time      time.Time
+	data      []byte
+}
+
+// Notifier sends the CloudEvents in the binary content mode of the HTTP binding to the sink. The CloudEvents
+// are delivered from a bounded queue in the background, so that the reconciliation never blocks on the sink.
+type Notifier struct {
+	queue  chan event
+	client *http.Client
+	logger *zap.SugaredLogger
+
+	// backoff is the delay before the first retry, doubled for every retry.
+	backoff time.Duration
+
+	mu   sync.RWMutex
+	sink string
+}
+
+// NewNotifier creates a Notifier, that delivers the CloudEvents until the context is done.
+func NewNotifier(ctx context.Context, logger *zap.SugaredLogger) *Notifier {
+	n := &Notifier{
+		queue:   make(chan event, QueueSize),
+		client:  &http.Client{Timeout: deliveryTimeout},
+		logger:  logger,
+		backoff: time.Second,
+	}
+	go n.run(ctx)
+	return n
+}
+
+// SetSink sets the URI the CloudEvents are sent to. No CloudEvents are sent, if it is empty.
+func (n *Notifier) SetSink(sink string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sink = sink
+}
+
+func (n *Notifier) getSink() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.sink
+}
+
+// Notify queues the CloudEvent of the type about the rollout of the RolloutOrchestrator. The id is the same for
+// the CloudEvents about the same transition of the rollout, so that the sink can drop the duplicates. A random id
+// is used, if it is empty. It never blocks, and drops the CloudEvent, if the queue is full.
+func (n *Notifier) Notify(ro *v1.RolloutOrchestrator, eventType, id, message string) {
+	if n == nil || n.getSink() == "" {
+		return
+	}
+	data, err := json.Marshal(Data{
+		Namespace:        ro.Namespace,
+		Name:             ro.Name,
+		Stage:            ro.Spec.StageTargetRevisions,
+		InitialRevisions: ro.Spec.InitialRevisions,
+		TargetRevisions:  ro.Spec.TargetRevisions,
+		Message:          message,
+	})
+	if err != nil {
+		n.logger.Errorw("Failed to marshal the CloudEvent", zap.Error(err))
+		return
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+	e := event{
+		id:        id,
+		eventType: eventType,
+		source: fmt.Sprintf("/apis/%s/namespaces/%s/rolloutorchestrators/%s", v1.SchemeGroupVersion.String(),
+			ro.Namespace, ro.Name),
+		subject: ro.Name,
+		time:    time.Now(),
+		data:    data,
+	}
+	select {
+	case n.queue <- e:
+	default:
+		n.logger.Warnw("Dropped the CloudEvent, because the queue is full", zap.String("type", eventType),
+			zap.String("source", e.source))
+	}
+}
+
+func (n *Notifier) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-n.queue:
+			n.deliver(ctx, e)
+		}
+	}
+}
+
+// deliver sends the CloudEvent to the sink, and retries with the exponential backoff, if the sink is unavailable
+// or the delivery fails with the status, that can be retried.
+func (n *Notifier) deliver(ctx context.Context, e event) {
+	backoff := n.backoff
+	for attempt := 0; ; attempt++ {
+		sink := n.getSink()
+		if sink == "" {
+			return
+		}
+		retry, err := n.send(ctx, sink, e)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= MaxRetries {
+			n.logger.Errorw("Failed to deliver the CloudEvent", zap.String("type", e.eventType),
+				zap.String("source", e.source), zap.Int("attempts", attempt+1), zap.Error(err))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send sends the CloudEvent to the sink once. It returns whether the delivery can be retried, if it fails.
+func (n *Notifier) send(ctx context.Context, sink string, e event) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink, bytes.NewReader(e.data))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Ce-Specversion", "1.0")
+	req.Header.Set("Ce-Id", e.id)
+	req.Header.Set("Ce-Type", e.eventType)
+	req.Header.Set("Ce-Source", e.source)
+	req.Header.Set("Ce-Subject", e.subject)
+	req.Header.Set("Ce-Time", e.time.UTC().Format(time.RFC3339Nano))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("the sink responded with the status %d", resp.StatusCode)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout, err
+}
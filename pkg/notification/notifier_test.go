@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+)
+
+var testRO = &v1.RolloutOrchestrator{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+
+type received struct {
+	header http.Header
+	data   Data
+}
+
+func newSink(t *testing.T, statuses ...int) (*httptest.Server, chan received) {
+	var mu sync.Mutex
+	ch := make(chan received, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		status := http.StatusAccepted
+		if len(statuses) != 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		mu.Unlock()
+		var data Data
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			t.Errorf("Failed to decode the data: %v", err)
+		}
+		ch <- received{header: r.Header, data: data}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, ch
+}
+
+func TestNotify(t *testing.T) {
+	server, ch := newSink(t)
+	n := NewNotifier(context.Background(), logtesting.TestLogger(t))
+	n.SetSink(server.URL)
+	n.Notify(testRO, TypeRolloutStarted, "test-id", "started")
+
+	r := <-ch
+	expected := map[string]string{
+		"Ce-Specversion": "1.0",
+		"Ce-Type":        TypeRolloutStarted,
+		"Ce-Source":      "/apis/serving.knative.dev/v1/namespaces/default/rolloutorchestrators/test",
+		"Ce-Subject":     "test",
+		"Content-Type":   "application/json",
+	}
+	for key, val := range expected {
+		if r.header.Get(key) != val {
+			t.Fatalf("Header %s of the CloudEvent = %v, want %v", key, r.header.Get(key), val)
+		}
+	}
+	if r.header.Get("Ce-Id") != "test-id" {
+		t.Fatalf("Header Ce-Id of the CloudEvent = %v, want %v", r.header.Get("Ce-Id"), "test-id")
+	}
+	expectedData := Data{Namespace: "default", Name: "test", Message: "started"}
+	if !reflect.DeepEqual(r.data, expectedData) {
+		t.Fatalf("Data of the CloudEvent = %v, want %v", r.data, expectedData)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name           string
+		statuses       []int
+		ExpectedResult int
+	}{{
+		name:           "Test the delivery retried after the sink is unavailable",
+		statuses:       []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+		ExpectedResult: 3,
+	}, {
+		name:           "Test the delivery rejected by the sink",
+		statuses:       []int{http.StatusBadRequest},
+		ExpectedResult: 1,
+	}, {
+		name: "Test the delivery given up after the retries",
+		statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError,
+			http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError,
+			http.StatusInternalServerError, http.StatusInternalServerError},
+		ExpectedResult: MaxRetries + 1,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, ch := newSink(t, test.statuses...)
+			n := &Notifier{client: server.Client(), logger: logtesting.TestLogger(t), backoff: time.Millisecond,
+				sink: server.URL}
+			n.deliver(context.Background(), event{eventType: TypeStageFailed, data: []byte("{}")})
+			if len(ch) != test.ExpectedResult {
+				t.Fatalf("Number of the attempts of deliver() = %v, want %v", len(ch), test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestNotifyNeverBlocks(t *testing.T) {
+	// The notifier is not running, so the queue is never drained.
+	n := &Notifier{queue: make(chan event, 1), logger: logtesting.TestLogger(t), sink: "http://sink"}
+	for range 3 {
+		n.Notify(testRO, TypeStageAdvanced, "", "")
+	}
+	if len(n.queue) != 1 {
+		t.Fatalf("Length of the queue = %v, want %v", len(n.queue), 1)
+	}
+
+	// No CloudEvents are queued without the sink.
+	n = &Notifier{queue: make(chan event, 1), logger: logtesting.TestLogger(t)}
+	n.Notify(testRO, TypeStageAdvanced, "", "")
+	if len(n.queue) != 0 {
+		t.Fatalf("Length of the queue = %v, want %v", len(n.queue), 0)
+	}
+}
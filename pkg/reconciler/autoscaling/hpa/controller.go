@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"context"
+
+	"k8s.io/client-go/tools/cache"
+	networkingclient "knative.dev/networking/pkg/client/injection/client"
+	sksinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/serverlessservice"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	hpainformer "knative.dev/pkg/client/injection/kube/informers/autoscaling/v2/horizontalpodautoscaler"
+	spainformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/stagepodautoscaler"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/common"
+	servingclient "knative.dev/serving/pkg/client/injection/client"
+	metricinformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/metric"
+	painformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler"
+	pareconciler "knative.dev/serving/pkg/client/injection/reconciler/autoscaling/v1alpha1/podautoscaler"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
+	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
+	"knative.dev/serving/pkg/reconciler/autoscaling/config"
+)
+
+// NewController returns a new HPA reconcile controller.
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+	logger := logging.FromContext(ctx)
+	paInformer := painformer.Get(ctx)
+	sksInformer := sksinformer.Get(ctx)
+	hpaInformer := hpainformer.Get(ctx)
+	metricInformer := metricinformer.Get(ctx)
+	spaInformer := spainformer.Get(ctx)
+
+	onlyHPAClass := pkgreconciler.AnnotationFilterFunc(
+		autoscaling.ClassAnnotationKey, autoscaling.HPA, false /*allowUnset*/)
+
+	c := &Reconciler{
+		Base: &areconciler.Base{
+			Client:           servingclient.Get(ctx),
+			NetworkingClient: networkingclient.Get(ctx),
+			SKSLister:        sksInformer.Lister(),
+			MetricLister:     metricInformer.Lister(),
+		},
+		kubeClient: kubeclient.Get(ctx),
+		hpaLister:  hpaInformer.Lister(),
+		spaLister:  spaInformer.Lister(),
+	}
+	impl := pareconciler.NewImpl(ctx, c, autoscaling.HPA, func(impl *controller.Impl) controller.Options {
+		logger.Info("Setting up ConfigMap receivers")
+		configsToResync := []interface{}{
+			&autoscalerconfig.Config{},
+		}
+		resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
+			impl.FilteredGlobalResync(onlyHPAClass, paInformer.Informer())
+		})
+		configStore := config.NewStore(logger.Named(common.ConfigStoreName), resync)
+		configStore.WatchConfigs(cmw)
+		return controller.Options{ConfigStore: configStore}
+	})
+
+	logger.Info("Setting up HPA-Class event handlers")
+
+	paInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: onlyHPAClass,
+		Handler:    controller.HandleAll(impl.Enqueue),
+	})
+
+	onlyPAControlled := controller.FilterController(&autoscalingv1alpha1.PodAutoscaler{})
+	handleMatchingControllers := cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.ChainFilterFuncs(onlyHPAClass, onlyPAControlled),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	}
+	hpaInformer.Informer().AddEventHandler(handleMatchingControllers)
+	sksInformer.Informer().AddEventHandler(handleMatchingControllers)
+
+	// The spa and the pa have a one-on-one mapping relationship, sharing the same name.
+	spaInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	return impl
+}
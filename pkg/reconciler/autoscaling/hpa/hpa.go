@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+
+	nv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	autoscalingv1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	sprlisters "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/autoscaling/hpa/resources"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	pareconciler "knative.dev/serving/pkg/client/injection/reconciler/autoscaling/v1alpha1/podautoscaler"
+	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
+	"knative.dev/serving/pkg/reconciler/autoscaling/config"
+	kparesources "knative.dev/serving/pkg/reconciler/autoscaling/kpa/resources"
+)
+
+// Reconciler implements the control loop for the HPA resources, applying the scale bounds of
+// the StagePodAutoscaler for the current stage.
+type Reconciler struct {
+	*areconciler.Base
+
+	kubeClient kubernetes.Interface
+	hpaLister  autoscalingv2listers.HorizontalPodAutoscalerLister
+	spaLister  sprlisters.StagePodAutoscalerLister
+}
+
+// Check that our Reconciler implements pareconciler.Interface
+var _ pareconciler.Interface = (*Reconciler)(nil)
+
+// ReconcileKind implements Interface.ReconcileKind.
+func (c *Reconciler) ReconcileKind(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler) pkgreconciler.Event {
+	ctx, cancel := context.WithTimeout(ctx, pkgreconciler.DefaultTimeout)
+	defer cancel()
+
+	logger := logging.FromContext(ctx)
+
+	spa, err := c.spaLister.StagePodAutoscalers(pa.Namespace).Get(pa.Name)
+	if err != nil {
+		logger.Warnw("Set the StagePodAutoscaler to empty, due to the error retrieving it", zap.Error(err))
+		spa = nil
+	}
+
+	// Reconcile the HPA, bounded by the scales of the current stage.
+	desiredHpa := resources.MakeHPA(pa, spa, config.FromContext(ctx).Autoscaler)
+	hpa, err := c.hpaLister.HorizontalPodAutoscalers(pa.Namespace).Get(desiredHpa.Name)
+	if errors.IsNotFound(err) {
+		logger.Infof("Creating HPA %q", desiredHpa.Name)
+		if hpa, err = c.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(pa.Namespace).Create(ctx, desiredHpa,
+			metav1.CreateOptions{}); err != nil {
+			pa.Status.MarkResourceFailedCreation("HorizontalPodAutoscaler", desiredHpa.Name)
+			return fmt.Errorf("failed to create HPA: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get HPA: %w", err)
+	} else if !metav1.IsControlledBy(hpa, pa) {
+		// Surface an error in the PodAutoscaler's status, and return an error.
+		pa.Status.MarkResourceNotOwned("HorizontalPodAutoscaler", desiredHpa.Name)
+		return fmt.Errorf("PodAutoscaler: %q does not own HPA: %q", pa.Name, desiredHpa.Name)
+	}
+	if !equality.Semantic.DeepEqual(desiredHpa.Spec, hpa.Spec) {
+		logger.Infof("Updating HPA %q", desiredHpa.Name)
+		want := hpa.DeepCopy()
+		want.Spec = desiredHpa.Spec
+		if hpa, err = c.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(pa.Namespace).Update(ctx, want,
+			metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update HPA: %w", err)
+		}
+	}
+
+	// The HPA gathers the metrics itself, so there is no need for the metrics service.
+	pa.Status.MetricsServiceName = ""
+
+	// The HPA never scales to zero, so the SKS is always in the serve mode.
+	sks, err := c.ReconcileSKS(ctx, pa, nv1alpha1.SKSOperationModeServe, 0 /*numActivators == all*/)
+	if err != nil {
+		return fmt.Errorf("error reconciling SKS: %w", err)
+	}
+
+	// Propagate the service name regardless of the status.
+	pa.Status.ServiceName = sks.Status.ServiceName
+	if !sks.IsReady() {
+		pa.Status.MarkSKSNotReady("SKS Services are not ready yet")
+	} else {
+		pa.Status.MarkSKSReady()
+		// If a min-scale value has been set, we don't want to mark the scale target
+		// as initialized until the current replicas are >= the min-scale value.
+		if !pa.Status.IsScaleTargetInitialized() {
+			ms := activeThreshold(ctx, pa, spa)
+			if hpa.Status.CurrentReplicas >= ms {
+				pa.Status.MarkScaleTargetInitialized()
+			}
+		}
+	}
+
+	// The HPA is always active. The scales are propagated to the PodAutoscaler, which are picked up
+	// by the StagePodAutoscaler in the same way as for the KPA.
+	pa.Status.MarkActive()
+	pa.Status.DesiredScale = ptr.Int32(hpa.Status.DesiredReplicas)
+	pa.Status.ActualScale = ptr.Int32(hpa.Status.CurrentReplicas)
+	return nil
+}
+
+// activeThreshold returns the scale required for the pa to be marked as initialized.
+func activeThreshold(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler,
+	spa *autoscalingv1.StagePodAutoscaler) int32 {
+	asConfig := config.FromContext(ctx).Autoscaler
+	minR, _ := resources.GetScaleBounds(asConfig, pa, spa)
+	if !pa.Status.IsScaleTargetInitialized() {
+		initialScale := kparesources.GetInitialScale(asConfig, pa)
+		return max(minR, initialScale)
+	}
+	return max(minR, 1)
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	// These are the fake informers we want setup.
+	fakenetworkingclient "knative.dev/networking/pkg/client/injection/client/fake"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"knative.dev/networking/pkg/apis/networking"
+	nv1a1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	sprv1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/autoscaling/hpa/resources"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	asconfig "knative.dev/serving/pkg/autoscaler/config"
+	pareconciler "knative.dev/serving/pkg/client/injection/reconciler/autoscaling/v1alpha1/podautoscaler"
+	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
+	"knative.dev/serving/pkg/reconciler/autoscaling/config"
+	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
+
+	recTest "knative.dev/pkg/reconciler/testing"
+	testV1 "knative.dev/serving-progressive-rollout/pkg/reconciler/testing/v1"
+	pkgTest "knative.dev/serving/pkg/testing"
+)
+
+const (
+	testNamespace = "test-namespace"
+	testRevision  = "test-revision"
+	key           = testNamespace + "/" + testRevision
+)
+
+func defaultConfig() *config.Config {
+	ac, _ := asconfig.NewConfigFromMap(map[string]string{})
+	return &config.Config{
+		Autoscaler: ac,
+	}
+}
+
+func hpaPA(opts ...pkgTest.PodAutoscalerOption) *autoscalingv1alpha1.PodAutoscaler {
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  testNamespace,
+			Name:       testRevision,
+			Generation: 1,
+			Annotations: map[string]string{
+				autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+				autoscaling.MetricAnnotationKey: autoscaling.CPU,
+				autoscaling.TargetAnnotationKey: "80",
+			},
+		},
+		Spec: autoscalingv1alpha1.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       testRevision + "-deployment",
+			},
+			ProtocolType: networking.ProtocolHTTP1,
+		},
+	}
+	pa.Status.InitializeConditions()
+	for _, opt := range opts {
+		opt(pa)
+	}
+	return pa
+}
+
+func spa(minScale, maxScale int32) *sprv1.StagePodAutoscaler {
+	return &sprv1.StagePodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testRevision,
+		},
+		Spec: sprv1.StagePodAutoscalerSpec{
+			StageMinScale: ptr.Int32(minScale),
+			StageMaxScale: ptr.Int32(maxScale),
+		},
+	}
+}
+
+type hpaOption func(*autoscalingv2.HorizontalPodAutoscaler)
+
+func withReplicas(desired, current int32) hpaOption {
+	return func(hpa *autoscalingv2.HorizontalPodAutoscaler) {
+		hpa.Status.DesiredReplicas, hpa.Status.CurrentReplicas = desired, current
+	}
+}
+
+func withHPAOwnersRemoved(hpa *autoscalingv2.HorizontalPodAutoscaler) {
+	hpa.OwnerReferences = nil
+}
+
+func hpa(pa *autoscalingv1alpha1.PodAutoscaler, spa *sprv1.StagePodAutoscaler,
+	opts ...hpaOption) *autoscalingv2.HorizontalPodAutoscaler {
+	h := resources.MakeHPA(pa, spa, defaultConfig().Autoscaler)
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func sks(opts ...pkgTest.SKSOption) *nv1a1.ServerlessService {
+	s := aresources.MakeSKS(hpaPA(), nv1a1.SKSOperationModeServe, 0)
+	s.Status.InitializeConditions()
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func withScales(desired, actual int32) pkgTest.PodAutoscalerOption {
+	return func(pa *autoscalingv1alpha1.PodAutoscaler) {
+		pa.Status.DesiredScale, pa.Status.ActualScale = ptr.Int32(desired), ptr.Int32(actual)
+	}
+}
+
+func withMinScale(minScale int) pkgTest.PodAutoscalerOption {
+	return func(pa *autoscalingv1alpha1.PodAutoscaler) {
+		pa.Annotations[autoscaling.MinScaleAnnotationKey] = strconv.Itoa(minScale)
+	}
+}
+
+func markResourceFailedCreation(pa *autoscalingv1alpha1.PodAutoscaler) {
+	pa.Status.MarkResourceFailedCreation("HorizontalPodAutoscaler", testRevision)
+}
+
+func markResourceNotOwned(pa *autoscalingv1alpha1.PodAutoscaler) {
+	pa.Status.MarkResourceNotOwned("HorizontalPodAutoscaler", testRevision)
+}
+
+func TestReconcile(t *testing.T) {
+	readySKS := sks(pkgTest.WithDeployRef(testRevision+"-deployment"), pkgTest.WithSKSReady)
+
+	table := recTest.TableTest{{
+		Name: "bad workqueue key",
+		Key:  "too/many/parts",
+	}, {
+		Name: "key not found",
+		Key:  "foo/not-found",
+	}, {
+		Name: "create the HPA without the StagePodAutoscaler",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(), readySKS,
+		},
+		WantCreates: []runtime.Object{
+			hpa(hpaPA(), nil),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(pkgTest.WithTraffic, pkgTest.WithPASKSReady, withScales(0, 0),
+				pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+		}},
+	}, {
+		Name: "create the HPA with the scales of the StagePodAutoscaler",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(), readySKS, spa(1, 3),
+		},
+		WantCreates: []runtime.Object{
+			hpa(hpaPA(), spa(1, 3)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(pkgTest.WithTraffic, pkgTest.WithPASKSReady, withScales(0, 0),
+				pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+		}},
+	}, {
+		Name: "update the HPA to the scales of the StagePodAutoscaler",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(pkgTest.WithTraffic, pkgTest.WithPASKSReady, pkgTest.WithScaleTargetInitialized,
+				withScales(5, 5), pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+			readySKS, spa(0, 1), hpa(hpaPA(), nil, withReplicas(5, 5)),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpa(hpaPA(), spa(0, 1), withReplicas(5, 5)),
+		}},
+	}, {
+		Name: "propagate the scales of the HPA to the PodAutoscaler",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(pkgTest.WithTraffic, pkgTest.WithPASKSReady, withScales(1, 1),
+				pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+			readySKS, spa(1, 3), hpa(hpaPA(), spa(1, 3), withReplicas(3, 2)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(pkgTest.WithTraffic, pkgTest.WithPASKSReady, pkgTest.WithScaleTargetInitialized,
+				withScales(3, 2), pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+		}},
+	}, {
+		Name: "the scale target is initialized at the min scale of the StagePodAutoscaler",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(withMinScale(3), pkgTest.WithTraffic, pkgTest.WithPASKSReady, withScales(1, 1),
+				pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+			readySKS, spa(1, 3), hpa(hpaPA(withMinScale(3)), spa(1, 3), withReplicas(3, 2)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(withMinScale(3), pkgTest.WithTraffic, pkgTest.WithPASKSReady,
+				pkgTest.WithScaleTargetInitialized, withScales(3, 2), pkgTest.WithPAStatusService(testRevision),
+				pkgTest.WithObservedGeneration(1)),
+		}},
+	}, {
+		Name: "the scale target is not initialized below the min scale without the StagePodAutoscaler",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(withMinScale(3), pkgTest.WithTraffic, pkgTest.WithPASKSReady, withScales(1, 1),
+				pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+			readySKS, hpa(hpaPA(withMinScale(3)), nil, withReplicas(3, 2)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(withMinScale(3), pkgTest.WithTraffic, pkgTest.WithPASKSReady, withScales(3, 2),
+				pkgTest.WithPAStatusService(testRevision), pkgTest.WithObservedGeneration(1)),
+		}},
+	}, {
+		Name: "propagate the SKS not ready",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(), sks(pkgTest.WithDeployRef(testRevision + "-deployment")), hpa(hpaPA(), nil, withReplicas(1, 1)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(pkgTest.WithTraffic, pkgTest.WithPASKSNotReady("SKS Services are not ready yet"),
+				withScales(1, 1), pkgTest.WithObservedGeneration(1)),
+		}},
+	}, {
+		Name: "the HPA is not owned by the PodAutoscaler",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(), readySKS, hpa(hpaPA(), nil, withHPAOwnersRemoved),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(markResourceNotOwned, pkgTest.WithObservedGeneration(1)),
+		}},
+		WantEvents: []string{
+			recTest.Eventf(corev1.EventTypeWarning, "InternalError",
+				`PodAutoscaler: "test-revision" does not own HPA: "test-revision"`),
+		},
+		WantErr: true,
+	}, {
+		Name: "failure creating the HPA",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(), readySKS, spa(1, 3),
+		},
+		WithReactors: []clientgotesting.ReactionFunc{
+			recTest.InduceFailure("create", "horizontalpodautoscalers"),
+		},
+		WantCreates: []runtime.Object{
+			hpa(hpaPA(), spa(1, 3)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpaPA(markResourceFailedCreation, pkgTest.WithObservedGeneration(1)),
+		}},
+		WantEvents: []string{
+			recTest.Eventf(corev1.EventTypeWarning, "InternalError",
+				`failed to create HPA: inducing failure for create horizontalpodautoscalers`),
+		},
+		WantErr: true,
+	}, {
+		Name: "failure updating the HPA",
+		Key:  key,
+		Objects: []runtime.Object{
+			hpaPA(pkgTest.WithObservedGeneration(1)), readySKS, spa(0, 1), hpa(hpaPA(), nil),
+		},
+		WithReactors: []clientgotesting.ReactionFunc{
+			recTest.InduceFailure("update", "horizontalpodautoscalers"),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: hpa(hpaPA(), spa(0, 1)),
+		}},
+		WantEvents: []string{
+			recTest.Eventf(corev1.EventTypeWarning, "InternalError",
+				`failed to update HPA: inducing failure for update horizontalpodautoscalers`),
+		},
+		WantErr: true,
+	}}
+
+	table.Test(t, testV1.MakeFactory(func(ctx context.Context, listers *testV1.Listers, _ configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			Base: &areconciler.Base{
+				Client:           fakeservingclient.Get(ctx),
+				NetworkingClient: fakenetworkingclient.Get(ctx),
+				SKSLister:        listers.GetServerlessServiceLister(),
+				MetricLister:     listers.GetMetricLister(),
+			},
+			kubeClient: fakekubeclient.Get(ctx),
+			hpaLister:  listers.GetHorizontalPodAutoscalerLister(),
+			spaLister:  listers.GetStagePodAutoscalerLister(),
+		}
+		return pareconciler.NewReconciler(ctx, logging.FromContext(ctx),
+			fakeservingclient.Get(ctx), listers.GetPodAutoscalerLister(),
+			controller.GetEventRecorder(ctx), r, autoscaling.HPA,
+			controller.Options{
+				ConfigStore: &testConfigStore{config: defaultConfig()},
+			})
+	}))
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
+}
+
+var _ pkgreconciler.ConfigStore = (*testConfigStore)(nil)
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
+	autoscalingv1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/autoscaling/kpa"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
+)
+
+// MakeHPA creates an HPA resource from a PA resource, bounded by the StagePodAutoscaler of the
+// current stage.
+func MakeHPA(pa *autoscalingv1alpha1.PodAutoscaler, spa *autoscalingv1.StagePodAutoscaler,
+	config *autoscalerconfig.Config) *autoscalingv2.HorizontalPodAutoscaler {
+	minR, maxR := GetScaleBounds(config, pa, spa)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pa.Name,
+			Namespace:       pa.Namespace,
+			Labels:          pa.Labels,
+			Annotations:     pa.Annotations,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(pa)},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: pa.Spec.ScaleTargetRef.APIVersion,
+				Kind:       pa.Spec.ScaleTargetRef.Kind,
+				Name:       pa.Spec.ScaleTargetRef.Name,
+			},
+			MaxReplicas: maxR,
+		},
+	}
+	if minR > 0 {
+		hpa.Spec.MinReplicas = ptr.Int32(minR)
+	}
+
+	if target, ok := pa.Target(); ok {
+		switch pa.Metric() {
+		case autoscaling.CPU:
+			hpa.Spec.Metrics = []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: ptr.Int32(int32(math.Ceil(target))),
+					},
+				},
+			}}
+		case autoscaling.Memory:
+			memory := resource.NewQuantity(int64(target)*1024*1024, resource.BinarySI)
+			hpa.Spec.Metrics = []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceMemory,
+					Target: autoscalingv2.MetricTarget{
+						Type:         autoscalingv2.AverageValueMetricType,
+						AverageValue: memory,
+					},
+				},
+			}}
+		default:
+			targetQuantity := resource.NewQuantity(int64(math.Ceil(target)), resource.DecimalSI)
+			hpa.Spec.Metrics = []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.PodsMetricSourceType,
+				Pods: &autoscalingv2.PodsMetricSource{
+					Metric: autoscalingv2.MetricIdentifier{
+						Name: pa.Metric(),
+					},
+					Target: autoscalingv2.MetricTarget{
+						Type:         autoscalingv2.AverageValueMetricType,
+						AverageValue: targetQuantity,
+					},
+				},
+			}}
+		}
+	}
+
+	if window, hasWindow := pa.Window(); hasWindow {
+		windowSeconds := int32(window.Seconds())
+		hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleDown: &autoscalingv2.HPAScalingRules{
+				StabilizationWindowSeconds: &windowSeconds,
+			},
+			ScaleUp: &autoscalingv2.HPAScalingRules{
+				StabilizationWindowSeconds: &windowSeconds,
+			},
+		}
+	}
+	return hpa
+}
+
+// GetScaleBounds returns the min and the max replicas of the HPA for the current stage.
+//
+// The bounds are the same as the KPA applies, with the differences that the HPA is not able to scale
+// to zero, and that it does not accept 0 as "no limit" for the max replicas.
+func GetScaleBounds(config *autoscalerconfig.Config, pa *autoscalingv1alpha1.PodAutoscaler,
+	spa *autoscalingv1.StagePodAutoscaler) (int32, int32) {
+	minR, maxR := kpa.GetScaleBounds(config, pa, spa)
+	if maxR == 0 && spa != nil {
+		// The max scale of the revision is not limited, so the StageMaxScale is the only upper bound.
+		if _, maxS := spa.ScaleBounds(); maxS != nil {
+			maxR = *maxS
+		}
+	}
+	if maxR <= 0 {
+		maxR = math.MaxInt32
+	}
+	if maxR < minR {
+		maxR = minR
+	}
+	return minR, maxR
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/ptr"
+	autoscalingv1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	asconfig "knative.dev/serving/pkg/autoscaler/config"
+)
+
+func TestMakeHPA(t *testing.T) {
+	config, _ := asconfig.NewConfigFromMap(map[string]string{})
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "test-name-00001",
+			Annotations: map[string]string{
+				autoscaling.ClassAnnotationKey:    autoscaling.HPA,
+				autoscaling.MetricAnnotationKey:   autoscaling.CPU,
+				autoscaling.TargetAnnotationKey:   "80",
+				autoscaling.MinScaleAnnotationKey: "2",
+				autoscaling.MaxScaleAnnotationKey: "10",
+			},
+		},
+		Spec: autoscalingv1alpha1.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "test-name-00001-deployment",
+			},
+		},
+	}
+	tests := []struct {
+		name                string
+		spa                 *autoscalingv1.StagePodAutoscaler
+		ExpectedMinReplicas *int32
+		ExpectedMaxReplicas int32
+	}{{
+		name:                "Test without the StagePodAutoscaler",
+		ExpectedMinReplicas: ptr.Int32(2),
+		ExpectedMaxReplicas: 10,
+	}, {
+		name: "Test with the StagePodAutoscaler scaling up",
+		spa: &autoscalingv1.StagePodAutoscaler{
+			Spec: autoscalingv1.StagePodAutoscalerSpec{
+				StageMinScale: ptr.Int32(1),
+				StageMaxScale: ptr.Int32(10),
+			},
+		},
+		ExpectedMinReplicas: ptr.Int32(1),
+		ExpectedMaxReplicas: 10,
+	}, {
+		name: "Test with the StagePodAutoscaler scaling down",
+		spa: &autoscalingv1.StagePodAutoscaler{
+			Spec: autoscalingv1.StagePodAutoscalerSpec{
+				StageMinScale: ptr.Int32(0),
+				StageMaxScale: ptr.Int32(1),
+			},
+		},
+		ExpectedMaxReplicas: 1,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hpa := MakeHPA(pa, test.spa, config)
+			if !reflect.DeepEqual(hpa.Spec.MinReplicas, test.ExpectedMinReplicas) {
+				t.Fatalf("Result of MakeHPA() MinReplicas = %v, want %v", hpa.Spec.MinReplicas,
+					test.ExpectedMinReplicas)
+			}
+			if hpa.Spec.MaxReplicas != test.ExpectedMaxReplicas {
+				t.Fatalf("Result of MakeHPA() MaxReplicas = %v, want %v", hpa.Spec.MaxReplicas,
+					test.ExpectedMaxReplicas)
+			}
+			expectedMetrics := []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: ptr.Int32(80),
+					},
+				},
+			}}
+			if !reflect.DeepEqual(hpa.Spec.Metrics, expectedMetrics) {
+				t.Fatalf("Result of MakeHPA() Metrics = %v, want %v", hpa.Spec.Metrics, expectedMetrics)
+			}
+			if hpa.Spec.ScaleTargetRef.Name != pa.Spec.ScaleTargetRef.Name {
+				t.Fatalf("Result of MakeHPA() ScaleTargetRef = %v, want %v", hpa.Spec.ScaleTargetRef.Name,
+					pa.Spec.ScaleTargetRef.Name)
+			}
+		})
+	}
+}
+
+func TestMakeHPAMemory(t *testing.T) {
+	config, _ := asconfig.NewConfigFromMap(map[string]string{})
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "test-name-00001",
+			Annotations: map[string]string{
+				autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+				autoscaling.MetricAnnotationKey: autoscaling.Memory,
+				autoscaling.TargetAnnotationKey: "200",
+			},
+		},
+	}
+	hpa := MakeHPA(pa, nil, config)
+	expected := resource.NewQuantity(200*1024*1024, resource.BinarySI)
+	if hpa.Spec.Metrics[0].Resource.Target.AverageValue.Cmp(*expected) != 0 {
+		t.Fatalf("Result of MakeHPA() AverageValue = %v, want %v", hpa.Spec.Metrics[0].Resource.Target.AverageValue,
+			expected)
+	}
+}
+
+func TestGetScaleBounds(t *testing.T) {
+	config, _ := asconfig.NewConfigFromMap(map[string]string{})
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		spa         *autoscalingv1.StagePodAutoscaler
+		ExpectedMin int32
+		ExpectedMax int32
+	}{{
+		name:        "Test without any limit",
+		annotations: map[string]string{},
+		ExpectedMin: 0,
+		ExpectedMax: math.MaxInt32,
+	}, {
+		name:        "Test with the StageMaxScale and no limit from the revision",
+		annotations: map[string]string{},
+		spa: &autoscalingv1.StagePodAutoscaler{
+			Spec: autoscalingv1.StagePodAutoscalerSpec{
+				StageMinScale: ptr.Int32(2),
+				StageMaxScale: ptr.Int32(4),
+			},
+		},
+		ExpectedMin: 0,
+		ExpectedMax: 4,
+	}, {
+		name: "Test with the StageMaxScale lower than the StageMinScale",
+		annotations: map[string]string{
+			autoscaling.MinScaleAnnotationKey: "3",
+			autoscaling.MaxScaleAnnotationKey: "5",
+		},
+		spa: &autoscalingv1.StagePodAutoscaler{
+			Spec: autoscalingv1.StagePodAutoscalerSpec{
+				StageMinScale: ptr.Int32(3),
+				StageMaxScale: ptr.Int32(2),
+			},
+		},
+		ExpectedMin: 3,
+		ExpectedMax: 3,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pa := &autoscalingv1alpha1.PodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: test.annotations,
+				},
+			}
+			minR, maxR := GetScaleBounds(config, pa, test.spa)
+			if minR != test.ExpectedMin || maxR != test.ExpectedMax {
+				t.Fatalf("Result of GetScaleBounds() = %v, %v, want %v, %v", minR, maxR, test.ExpectedMin,
+					test.ExpectedMax)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	nv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
@@ -30,8 +31,10 @@ import (
 	pkgreconciler "knative.dev/pkg/reconciler"
 	autoscalingv1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
 	sprlisters "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/tracing"
 	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
 	"knative.dev/serving/pkg/autoscaler/scaling"
 	pareconciler "knative.dev/serving/pkg/client/injection/reconciler/autoscaling/v1alpha1/podautoscaler"
 	"knative.dev/serving/pkg/metrics"
@@ -127,10 +130,14 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pa *autoscalingv1alpha1.
 
 	// Get the appropriate current scale from the metric, and right size
 	// the scaleTargetRef based on it.
+	previous := pa.Status.DesiredScale
 	want, err := c.scaler.scale(ctx, pa, spa, sks, decider.Status.DesiredScale)
 	if err != nil {
 		return fmt.Errorf("error scaling target: %w", err)
 	}
+	if spa != nil && want != scaleUnknown && (previous == nil || *previous != want) {
+		traceScale(ctx, config.FromContext(ctx).Autoscaler, pa, spa, decider.Status.DesiredScale, want)
+	}
 
 	mode := nv1alpha1.SKSOperationModeProxy
 
@@ -199,6 +206,22 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pa *autoscalingv1alpha1.
 	return nil
 }
 
+// traceScale records the scaling decision for the revision of the PodAutoscaler as a span of the stage of the
+// rollout, that the StagePodAutoscaler is part of.
+func traceScale(ctx context.Context, asConfig *autoscalerconfig.Config, pa *autoscalingv1alpha1.PodAutoscaler,
+	spa *autoscalingv1.StagePodAutoscaler, desired, want int32) {
+	minR, maxR := GetScaleBounds(asConfig, pa, spa)
+	_, span := tracing.Start(ctx, spa.Annotations, tracing.StageTraceParentAnnotation, "kpa.scale",
+		trace.WithAttributes(
+			attribute.String("revision", pa.Name),
+			attribute.Int("desired", int(desired)),
+			attribute.Int("want", int(want)),
+			attribute.Int("min", int(minR)),
+			attribute.Int("max", int(maxR)),
+		))
+	span.End()
+}
+
 // ObserveDeletion implements OnDeletionInterface.ObserveDeletion.
 func (c *Reconciler) ObserveDeletion(ctx context.Context, key types.NamespacedName) error {
 	c.deciders.Delete(ctx, key.Namespace, key.Name)
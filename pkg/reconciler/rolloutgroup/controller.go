@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolloutgroup
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+	servingclient "knative.dev/serving-progressive-rollout/pkg/client/injection/client"
+	rginformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/rolloutgroup"
+	roinformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/rolloutorchestrator"
+	rgreconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/rolloutgroup"
+	configurationinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/configuration"
+)
+
+// NewController creates a new RolloutGroup controller
+func NewController(
+	ctx context.Context,
+	_ configmap.Watcher,
+) *controller.Impl {
+	logger := logging.FromContext(ctx)
+	rgInformer := rginformer.Get(ctx)
+	roInformer := roinformer.Get(ctx)
+
+	c := NewReconciler(servingclient.Get(ctx), roInformer.Lister(), configurationinformer.Get(ctx).Lister())
+
+	// Create a controller.Impl that handles queuing and feeding work from
+	// the queue through an implementation of controller.Reconciler for the RolloutGroup.
+	impl := rgreconciler.NewImpl(ctx, c)
+
+	// This reconciliation loop of the RolloutGroup will watch the changes of RolloutGroup itself.
+	rgInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	// The RolloutOrchestrators of the members are not owned by the RolloutGroup, so every change on them enqueues
+	// the RolloutGroups in the same namespace listing the service as a member.
+	roInformer.Informer().AddEventHandler(controller.HandleAll(func(obj interface{}) {
+		accessor, err := kmeta.DeletionHandlingAccessor(obj)
+		if err != nil {
+			logger.Errorw("Failed to get the accessor of the RolloutOrchestrator", zap.Error(err))
+			return
+		}
+		groups, err := rgInformer.Lister().RolloutGroups(accessor.GetNamespace()).List(labels.Everything())
+		if err != nil {
+			logger.Errorw("Failed to list the RolloutGroups", zap.Error(err))
+			return
+		}
+		for _, rg := range groups {
+			for _, member := range rg.Spec.Members {
+				if member.ServiceName == accessor.GetName() {
+					impl.Enqueue(rg)
+					break
+				}
+			}
+		}
+	}))
+
+	return impl
+}
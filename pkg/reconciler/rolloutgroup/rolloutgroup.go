@@ -0,0 +1,423 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolloutgroup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/controller"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	clientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned"
+	rgreconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/rolloutgroup"
+	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
+)
+
+// Reconciler implements controller.Reconciler for RolloutGroup resources.
+type Reconciler struct {
+	client clientset.Interface
+
+	// lister indexes properties about RolloutOrchestrator
+	rolloutOrchestratorLister listers.RolloutOrchestratorLister
+
+	// lister indexes properties about Configuration
+	configurationLister servinglisters.ConfigurationLister
+}
+
+// Check that our Reconciler implements rgreconciler.Interface and rgreconciler.Finalizer
+var (
+	_ rgreconciler.Interface = (*Reconciler)(nil)
+	_ rgreconciler.Finalizer = (*Reconciler)(nil)
+)
+
+// NewReconciler creates the reference to the Reconciler based on clientset.Interface,
+// listers.RolloutOrchestratorLister and servinglisters.ConfigurationLister.
+func NewReconciler(client clientset.Interface, rolloutOrchestratorLister listers.RolloutOrchestratorLister,
+	configurationLister servinglisters.ConfigurationLister) *Reconciler {
+	return &Reconciler{
+		client:                    client,
+		rolloutOrchestratorLister: rolloutOrchestratorLister,
+		configurationLister:       configurationLister,
+	}
+}
+
+// ReconcileKind implements Interface.ReconcileKind.
+func (r *Reconciler) ReconcileKind(ctx context.Context, rg *v1.RolloutGroup) pkgreconciler.Event {
+	ctx, cancel := context.WithTimeout(ctx, pkgreconciler.DefaultTimeout)
+	defer cancel()
+
+	// The RolloutOrchestrator has the same name as the service, as one service maps to only one RolloutOrchestrator.
+	ros, err := r.getMemberOrchestrators(rg)
+	if err != nil {
+		return err
+	}
+	latest, err := r.getLatestRevisionNames(rg)
+	if err != nil {
+		return err
+	}
+
+	wasFailed := rg.IsFailed()
+	statuses, failed := planMembers(rg, ros, latest)
+	cycle := findDependencyCycle(rg)
+	if cycle != "" {
+		// The members cannot be ordered, so none of them is allowed to move on to the next stage.
+		for i := range statuses {
+			if statuses[i].Phase == v1.RolloutGroupMemberProgressing {
+				statuses[i].Phase = v1.RolloutGroupMemberHeld
+				statuses[i].Message = fmt.Sprintf("The dependencies contain the cycle %s.", cycle)
+			}
+		}
+	}
+
+	for _, status := range statuses {
+		if ro := ros[status.ServiceName]; ro != nil {
+			if err = r.reconcileMemberAnnotations(ctx, rg, ro, status.Phase); err != nil {
+				return err
+			}
+		}
+	}
+
+	rg.Status.MemberStatuses = statuses
+	switch {
+	case cycle != "":
+		rg.Status.MarkInvalidMembers(fmt.Sprintf("the dependencies contain the cycle %s", cycle))
+	case len(failed) != 0:
+		rg.Status.MarkMemberFailed(strings.Join(failed, ", "), rg.GetFailurePolicy())
+		if !wasFailed {
+			controller.GetEventRecorder(ctx).Eventf(rg, corev1.EventTypeWarning, "MemberRolloutFailed",
+				"The rollout of the members %s failed, and the policy %s is applied to the group.",
+				strings.Join(failed, ", "), rg.GetFailurePolicy())
+		}
+	default:
+		rg.Status.MarkMembersHealthy()
+	}
+
+	var inProgress []string
+	for _, status := range statuses {
+		if status.Phase != v1.RolloutGroupMemberComplete {
+			inProgress = append(inProgress, status.ServiceName)
+		}
+	}
+	if len(inProgress) == 0 {
+		rg.Status.MarkRolloutComplete()
+	} else {
+		rg.Status.MarkRolloutInProgress(strings.Join(inProgress, ", "))
+	}
+	return nil
+}
+
+// FinalizeKind implements Finalizer.FinalizeKind. It releases all the members held by the RolloutGroup.
+func (r *Reconciler) FinalizeKind(ctx context.Context, rg *v1.RolloutGroup) pkgreconciler.Event {
+	ros, err := r.getMemberOrchestrators(rg)
+	if err != nil {
+		return err
+	}
+	for _, ro := range ros {
+		if ro == nil {
+			continue
+		}
+		if err = r.reconcileMemberAnnotations(ctx, rg, ro, v1.RolloutGroupMemberComplete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMemberOrchestrators returns the RolloutOrchestrators of the members. The value is nil for the member,
+// whose RolloutOrchestrator does not exist yet.
+func (r *Reconciler) getMemberOrchestrators(rg *v1.RolloutGroup) (map[string]*v1.RolloutOrchestrator, error) {
+	ros := make(map[string]*v1.RolloutOrchestrator, len(rg.Spec.Members))
+	for _, member := range rg.Spec.Members {
+		ro, err := r.rolloutOrchestratorLister.RolloutOrchestrators(rg.Namespace).Get(member.ServiceName)
+		if apierrs.IsNotFound(err) {
+			ros[member.ServiceName] = nil
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		ros[member.ServiceName] = ro
+	}
+	return ros, nil
+}
+
+// getLatestRevisionNames returns the names of the latest created revisions of the configurations of the members.
+// The configuration has the same name as the service. The member without the configuration is left out.
+func (r *Reconciler) getLatestRevisionNames(rg *v1.RolloutGroup) (map[string]string, error) {
+	latest := make(map[string]string, len(rg.Spec.Members))
+	for _, member := range rg.Spec.Members {
+		config, err := r.configurationLister.Configurations(rg.Namespace).Get(member.ServiceName)
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		latest[member.ServiceName] = config.Status.LatestCreatedRevisionName
+	}
+	return latest, nil
+}
+
+// reconcileMemberAnnotations sets or removes the annotations, that keep the RolloutOrchestrator of the member
+// at the current stage or roll it back, based on the phase of the member.
+func (r *Reconciler) reconcileMemberAnnotations(ctx context.Context, rg *v1.RolloutGroup,
+	ro *v1.RolloutOrchestrator, phase string) error {
+	hold, rollback := false, false
+	switch phase {
+	case v1.RolloutGroupMemberHeld:
+		hold = true
+	case v1.RolloutGroupMemberRollingBack:
+		rollback = true
+	case v1.RolloutGroupMemberFailed:
+		rollback = rg.GetFailurePolicy() == v1.RolloutGroupFailurePolicyRollback
+		hold = !rollback
+	}
+
+	annotations := map[string]interface{}{}
+	setGroupAnnotation(annotations, ro.Annotations, resources.RolloutGroupHold, rg.Name, hold)
+	setGroupAnnotation(annotations, ro.Annotations, resources.RolloutGroupRollback, rg.Name, rollback)
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	// The service reconciler updates the RolloutOrchestrator at the same time, so only the annotations are patched.
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.client.ServingV1().RolloutOrchestrators(ro.Namespace).Patch(ctx, ro.Name, types.MergePatchType, patch,
+		metav1.PatchOptions{})
+	return err
+}
+
+// setGroupAnnotation adds the annotation set to the name of the RolloutGroup into the annotations to patch, or its
+// removal if it was set by the same RolloutGroup. Nothing is added, if the current annotations need no change.
+func setGroupAnnotation(patch map[string]interface{}, annotations map[string]string, key, groupName string, set bool) {
+	value, found := annotations[key]
+	if set && value != groupName {
+		patch[key] = groupName
+	}
+	if !set && found && value == groupName {
+		patch[key] = nil
+	}
+}
+
+// planMembers decides the phase of each member, based on the progress of the RolloutOrchestrators, the ordering
+// and the failure policy. The latest maps the members to the latest created revisions of their configurations.
+// It returns the statuses of the members, and the names of the failed members.
+func planMembers(rg *v1.RolloutGroup, ros map[string]*v1.RolloutOrchestrator,
+	latest map[string]string) ([]v1.RolloutGroupMemberStatus, []string) {
+	previous := make(map[string]v1.RolloutGroupMemberStatus, len(rg.Status.MemberStatuses))
+	for _, status := range rg.Status.MemberStatuses {
+		previous[status.ServiceName] = status
+	}
+
+	var failed []string
+	statuses := make([]v1.RolloutGroupMemberStatus, 0, len(rg.Spec.Members))
+	for _, member := range rg.Spec.Members {
+		status := v1.RolloutGroupMemberStatus{ServiceName: member.ServiceName}
+		ro := ros[member.ServiceName]
+		switch {
+		case ro == nil:
+			status.Phase = v1.RolloutGroupMemberPending
+			status.Message = "The RolloutOrchestrator does not exist yet."
+		case ro.IsStageFailed():
+			status.TargetRevisionName = getTargetRevisionName(ro)
+			status.Phase = v1.RolloutGroupMemberFailed
+			status.Message = ro.Status.GetCondition(v1.SOStageReady).GetMessage()
+		case ro.IsReady():
+			status.TargetRevisionName = getTargetRevisionName(ro)
+			status.Phase = v1.RolloutGroupMemberComplete
+		default:
+			status.TargetRevisionName = getTargetRevisionName(ro)
+			status.Phase = v1.RolloutGroupMemberProgressing
+			if prev := previous[member.ServiceName]; prev.Phase == v1.RolloutGroupMemberFailed &&
+				prev.TargetRevisionName == status.TargetRevisionName {
+				// The member failed to roll out the same revision before, so the group stays failed, even after
+				// the stage of the member has been held or rolled back.
+				status.Phase = v1.RolloutGroupMemberFailed
+				status.Message = prev.Message
+			}
+		}
+		if status.Phase == v1.RolloutGroupMemberFailed {
+			failed = append(failed, member.ServiceName)
+		}
+		statuses = append(statuses, status)
+	}
+
+	if len(failed) != 0 {
+		phase := v1.RolloutGroupMemberHeld
+		if rg.GetFailurePolicy() == v1.RolloutGroupFailurePolicyRollback {
+			phase = v1.RolloutGroupMemberRollingBack
+		}
+		for i := range statuses {
+			if statuses[i].Phase == v1.RolloutGroupMemberProgressing {
+				statuses[i].Phase = phase
+				statuses[i].Message = fmt.Sprintf("The rollout of the members %s failed.", strings.Join(failed, ", "))
+			}
+		}
+		return statuses, failed
+	}
+
+	if rg.GetOrdering() == v1.RolloutGroupOrderingDAG {
+		holdDependents(rg, statuses, latest)
+	} else {
+		holdLeaders(statuses, ros)
+	}
+	return statuses, nil
+}
+
+// holdLeaders holds the members, which have shifted more traffic to the new revision than the slowest member,
+// so that all the members roll out their stages in lockstep.
+func holdLeaders(statuses []v1.RolloutGroupMemberStatus, ros map[string]*v1.RolloutOrchestrator) {
+	progress := make(map[string]int64, len(statuses))
+	minProgress := int64(-1)
+	for _, status := range statuses {
+		if status.Phase != v1.RolloutGroupMemberProgressing {
+			continue
+		}
+		progress[status.ServiceName] = getRolloutProgress(ros[status.ServiceName])
+		if minProgress < 0 || progress[status.ServiceName] < minProgress {
+			minProgress = progress[status.ServiceName]
+		}
+	}
+
+	var laggards []string
+	for _, status := range statuses {
+		if status.Phase == v1.RolloutGroupMemberProgressing && progress[status.ServiceName] == minProgress {
+			laggards = append(laggards, status.ServiceName)
+		}
+	}
+	for i := range statuses {
+		if statuses[i].Phase == v1.RolloutGroupMemberProgressing && progress[statuses[i].ServiceName] > minProgress {
+			statuses[i].Phase = v1.RolloutGroupMemberHeld
+			statuses[i].Message = fmt.Sprintf("Waiting for the members %s to catch up.", strings.Join(laggards, ", "))
+		}
+	}
+}
+
+// holdDependents holds the members, until all the members they depend on have completed their rollouts of the
+// latest revisions of their configurations.
+func holdDependents(rg *v1.RolloutGroup, statuses []v1.RolloutGroupMemberStatus, latest map[string]string) {
+	complete := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		// The RolloutOrchestrator is still ready from the previous rollout, until the service reconciler moves it on
+		// to the latest revision, e.g. when all the services of the group are updated at once.
+		complete[status.ServiceName] = status.Phase == v1.RolloutGroupMemberComplete &&
+			status.TargetRevisionName == latest[status.ServiceName]
+	}
+	for i, member := range rg.Spec.Members {
+		if statuses[i].Phase != v1.RolloutGroupMemberProgressing {
+			continue
+		}
+		var waiting []string
+		for _, dep := range member.DependsOn {
+			if !complete[dep] {
+				waiting = append(waiting, dep)
+			}
+		}
+		if len(waiting) != 0 {
+			statuses[i].Phase = v1.RolloutGroupMemberHeld
+			statuses[i].Message = fmt.Sprintf("Waiting for the members %s to complete their rollouts.",
+				strings.Join(waiting, ", "))
+		}
+	}
+}
+
+// findDependencyCycle returns the members forming a dependency cycle, joined by arrows, or an empty string
+// if there is no cycle. Only the dag ordering uses the dependencies.
+func findDependencyCycle(rg *v1.RolloutGroup) string {
+	if rg.GetOrdering() != v1.RolloutGroupOrderingDAG {
+		return ""
+	}
+	deps := make(map[string][]string, len(rg.Spec.Members))
+	names := make([]string, 0, len(rg.Spec.Members))
+	for _, member := range rg.Spec.Members {
+		deps[member.ServiceName] = member.DependsOn
+		names = append(names, member.ServiceName)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(names))
+	var path []string
+	var visit func(name string) string
+	visit = func(name string) string {
+		switch state[name] {
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return strings.Join(append(append([]string{}, path[i:]...), name), " -> ")
+				}
+			}
+		case visited:
+			return ""
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if cycle := visit(dep); cycle != "" {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return ""
+	}
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != "" {
+				return cycle
+			}
+		}
+	}
+	return ""
+}
+
+// getTargetRevisionName returns the name of the revision the RolloutOrchestrator is rolling out.
+func getTargetRevisionName(ro *v1.RolloutOrchestrator) string {
+	if len(ro.Spec.TargetRevisions) == 0 {
+		return ""
+	}
+	return ro.Spec.TargetRevisions[0].RevisionName
+}
+
+// getRolloutProgress returns the percentage of the traffic the last finished stage has shifted to the
+// revision the RolloutOrchestrator is rolling out.
+func getRolloutProgress(ro *v1.RolloutOrchestrator) int64 {
+	name := getTargetRevisionName(ro)
+	for _, rev := range ro.Status.StageRevisionStatus {
+		if rev.RevisionName == name && rev.Percent != nil {
+			return *rev.Percent
+		}
+	}
+	return 0
+}
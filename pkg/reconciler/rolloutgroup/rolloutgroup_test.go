@@ -0,0 +1,321 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolloutgroup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	fakeclientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned/fake"
+	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
+)
+
+func makeRO(name string, percent int64, conds ...apis.Condition) *v1.RolloutOrchestrator {
+	ro := &v1.RolloutOrchestrator{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.RolloutOrchestratorSpec{
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: name + "-00002", Percent: ptr.Int64(100)},
+			}},
+		},
+		Status: v1.RolloutOrchestratorStatus{
+			RolloutOrchestratorStatusFields: v1.RolloutOrchestratorStatusFields{
+				StageRevisionStatus: []v1.TargetRevision{{
+					TrafficTarget: servingv1.TrafficTarget{RevisionName: name + "-00001", Percent: ptr.Int64(100 - percent)},
+				}, {
+					TrafficTarget: servingv1.TrafficTarget{RevisionName: name + "-00002", Percent: ptr.Int64(percent)},
+				}},
+			},
+		},
+	}
+	ro.Status.InitializeConditions()
+	for _, cond := range conds {
+		ro.GetConditionSet().Manage(&ro.Status).SetCondition(cond)
+	}
+	return ro
+}
+
+var (
+	readyCond       = apis.Condition{Type: apis.ConditionReady, Status: corev1.ConditionTrue}
+	stageFailedCond = apis.Condition{Type: v1.SOStageReady, Status: corev1.ConditionFalse, Message: "timeout"}
+)
+
+func makeGroup(ordering, policy string, members ...v1.RolloutGroupMember) *v1.RolloutGroup {
+	return &v1.RolloutGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "default"},
+		Spec:       v1.RolloutGroupSpec{Members: members, Ordering: ordering, FailurePolicy: policy},
+	}
+}
+
+func phases(statuses []v1.RolloutGroupMemberStatus) map[string]string {
+	result := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		result[status.ServiceName] = status.Phase
+	}
+	return result
+}
+
+func TestPlanMembers(t *testing.T) {
+	a, b, c := v1.RolloutGroupMember{ServiceName: "a"}, v1.RolloutGroupMember{ServiceName: "b"},
+		v1.RolloutGroupMember{ServiceName: "c", DependsOn: []string{"a", "b"}}
+	tests := []struct {
+		name           string
+		rg             *v1.RolloutGroup
+		ros            map[string]*v1.RolloutOrchestrator
+		latest         map[string]string
+		expectedPhases map[string]string
+		expectedFailed []string
+	}{{
+		name: "lockstep holds the member ahead",
+		rg:   makeGroup("", "", a, b, c),
+		ros:  map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 20), "b": makeRO("b", 10), "c": nil},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberHeld,
+			"b": v1.RolloutGroupMemberProgressing,
+			"c": v1.RolloutGroupMemberPending,
+		},
+	}, {
+		name: "lockstep lets the members with the same progress advance",
+		rg:   makeGroup(v1.RolloutGroupOrderingLockstep, "", a, b),
+		ros:  map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 10), "b": makeRO("b", 10)},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberProgressing,
+			"b": v1.RolloutGroupMemberProgressing,
+		},
+	}, {
+		name: "lockstep ignores the complete members",
+		rg:   makeGroup(v1.RolloutGroupOrderingLockstep, "", a, b),
+		ros:  map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 10), "b": makeRO("b", 100, readyCond)},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberProgressing,
+			"b": v1.RolloutGroupMemberComplete,
+		},
+	}, {
+		name: "dag holds the member with incomplete dependencies",
+		rg:   makeGroup(v1.RolloutGroupOrderingDAG, "", a, b, c),
+		ros: map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 100, readyCond), "b": makeRO("b", 50),
+			"c": makeRO("c", 10)},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberComplete,
+			"b": v1.RolloutGroupMemberProgressing,
+			"c": v1.RolloutGroupMemberHeld,
+		},
+	}, {
+		name: "dag releases the member with complete dependencies",
+		rg:   makeGroup(v1.RolloutGroupOrderingDAG, "", a, b, c),
+		ros: map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 100, readyCond), "b": makeRO("b", 100, readyCond),
+			"c": makeRO("c", 10)},
+		latest: map[string]string{"a": "a-00002", "b": "b-00002", "c": "c-00002"},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberComplete,
+			"b": v1.RolloutGroupMemberComplete,
+			"c": v1.RolloutGroupMemberProgressing,
+		},
+	}, {
+		name: "dag holds the member with dependencies ready from the previous rollout",
+		rg:   makeGroup(v1.RolloutGroupOrderingDAG, "", a, b, c),
+		ros: map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 100, readyCond), "b": makeRO("b", 100, readyCond),
+			"c": makeRO("c", 10)},
+		latest: map[string]string{"a": "a-00003", "b": "b-00002", "c": "c-00002"},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberComplete,
+			"b": v1.RolloutGroupMemberComplete,
+			"c": v1.RolloutGroupMemberHeld,
+		},
+	}, {
+		name: "failed member holds the group",
+		rg:   makeGroup(v1.RolloutGroupOrderingDAG, v1.RolloutGroupFailurePolicyHold, a, b, c),
+		ros: map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 10, stageFailedCond), "b": makeRO("b", 10),
+			"c": makeRO("c", 100, readyCond)},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberFailed,
+			"b": v1.RolloutGroupMemberHeld,
+			"c": v1.RolloutGroupMemberComplete,
+		},
+		expectedFailed: []string{"a"},
+	}, {
+		name: "failed member rolls back the group",
+		rg:   makeGroup("", v1.RolloutGroupFailurePolicyRollback, a, b),
+		ros:  map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 10), "b": makeRO("b", 10, stageFailedCond)},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberRollingBack,
+			"b": v1.RolloutGroupMemberFailed,
+		},
+		expectedFailed: []string{"b"},
+	}, {
+		name: "member failed before with the same revision keeps the group failed",
+		rg: func() *v1.RolloutGroup {
+			rg := makeGroup("", "", a, b)
+			rg.Status.MemberStatuses = []v1.RolloutGroupMemberStatus{{
+				ServiceName:        "a",
+				Phase:              v1.RolloutGroupMemberFailed,
+				TargetRevisionName: "a-00002",
+			}}
+			return rg
+		}(),
+		ros: map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 0), "b": makeRO("b", 10)},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberFailed,
+			"b": v1.RolloutGroupMemberHeld,
+		},
+		expectedFailed: []string{"a"},
+	}, {
+		name: "member failed before with another revision recovers",
+		rg: func() *v1.RolloutGroup {
+			rg := makeGroup("", "", a, b)
+			rg.Status.MemberStatuses = []v1.RolloutGroupMemberStatus{{
+				ServiceName:        "a",
+				Phase:              v1.RolloutGroupMemberFailed,
+				TargetRevisionName: "a-00001",
+			}}
+			return rg
+		}(),
+		ros: map[string]*v1.RolloutOrchestrator{"a": makeRO("a", 10), "b": makeRO("b", 10)},
+		expectedPhases: map[string]string{
+			"a": v1.RolloutGroupMemberProgressing,
+			"b": v1.RolloutGroupMemberProgressing,
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statuses, failed := planMembers(test.rg, test.ros, test.latest)
+			if got := phases(statuses); !reflect.DeepEqual(got, test.expectedPhases) {
+				t.Fatalf("Result of planMembers() phases = %v, want %v", got, test.expectedPhases)
+			}
+			if !reflect.DeepEqual(failed, test.expectedFailed) {
+				t.Fatalf("Result of planMembers() failed = %v, want %v", failed, test.expectedFailed)
+			}
+		})
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		rg       *v1.RolloutGroup
+		expected string
+	}{{
+		name: "no cycle",
+		rg: makeGroup(v1.RolloutGroupOrderingDAG, "", v1.RolloutGroupMember{ServiceName: "a"},
+			v1.RolloutGroupMember{ServiceName: "b", DependsOn: []string{"a"}}),
+		expected: "",
+	}, {
+		name: "cycle",
+		rg: makeGroup(v1.RolloutGroupOrderingDAG, "",
+			v1.RolloutGroupMember{ServiceName: "a", DependsOn: []string{"c"}},
+			v1.RolloutGroupMember{ServiceName: "b", DependsOn: []string{"a"}},
+			v1.RolloutGroupMember{ServiceName: "c", DependsOn: []string{"b"}}),
+		expected: "a -> c -> b -> a",
+	}, {
+		name: "dependencies ignored by lockstep",
+		rg: makeGroup(v1.RolloutGroupOrderingLockstep, "",
+			v1.RolloutGroupMember{ServiceName: "a", DependsOn: []string{"a"}}),
+		expected: "",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := findDependencyCycle(test.rg); got != test.expected {
+				t.Fatalf("Result of findDependencyCycle() = %v, want %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestReconcileKind(t *testing.T) {
+	roA, roB := makeRO("a", 20), makeRO("b", 10)
+	roB.Annotations = map[string]string{resources.RolloutGroupHold: "group"}
+	client := fakeclientset.NewSimpleClientset(roA, roB)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(roA)
+	indexer.Add(roB)
+	r := NewReconciler(client, listers.NewRolloutOrchestratorLister(indexer),
+		servinglisters.NewConfigurationLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})))
+	rg := makeGroup("", "", v1.RolloutGroupMember{ServiceName: "a"}, v1.RolloutGroupMember{ServiceName: "b"})
+	ctx := controller.WithEventRecorder(context.Background(), record.NewFakeRecorder(10))
+
+	if err := r.ReconcileKind(ctx, rg); err != nil {
+		t.Fatalf("ReconcileKind() = %v", err)
+	}
+	a, _ := client.ServingV1().RolloutOrchestrators("default").Get(ctx, "a", metav1.GetOptions{})
+	if got := a.Annotations[resources.RolloutGroupHold]; got != "group" {
+		t.Fatalf("Result of ReconcileKind() hold annotation of a = %q, want %q", got, "group")
+	}
+	b, _ := client.ServingV1().RolloutOrchestrators("default").Get(ctx, "b", metav1.GetOptions{})
+	if _, found := b.Annotations[resources.RolloutGroupHold]; found {
+		t.Fatalf("Result of ReconcileKind() hold annotation of b found, want removed")
+	}
+	if cond := rg.Status.GetCondition(v1.RolloutGroupConditionRolloutComplete); !cond.IsUnknown() {
+		t.Fatalf("Result of ReconcileKind() RolloutComplete = %v, want Unknown", cond.Status)
+	}
+
+	indexer.Update(a)
+	if err := r.FinalizeKind(ctx, rg); err != nil {
+		t.Fatalf("FinalizeKind() = %v", err)
+	}
+	a, _ = client.ServingV1().RolloutOrchestrators("default").Get(ctx, "a", metav1.GetOptions{})
+	if _, found := a.Annotations[resources.RolloutGroupHold]; found {
+		t.Fatalf("Result of FinalizeKind() hold annotation of a found, want removed")
+	}
+}
+
+func TestSetGroupAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		set         bool
+		expected    map[string]interface{}
+	}{{
+		name:        "set",
+		annotations: map[string]string{},
+		set:         true,
+		expected:    map[string]interface{}{resources.RolloutGroupHold: "group"},
+	}, {
+		name:        "already set",
+		annotations: map[string]string{resources.RolloutGroupHold: "group"},
+		set:         true,
+		expected:    map[string]interface{}{},
+	}, {
+		name:        "remove",
+		annotations: map[string]string{resources.RolloutGroupHold: "group"},
+		expected:    map[string]interface{}{resources.RolloutGroupHold: nil},
+	}, {
+		name:        "keep the annotation of another group",
+		annotations: map[string]string{resources.RolloutGroupHold: "other"},
+		expected:    map[string]interface{}{},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch := map[string]interface{}{}
+			setGroupAnnotation(patch, test.annotations, resources.RolloutGroupHold, "group", test.set)
+			if !reflect.DeepEqual(patch, test.expected) {
+				t.Fatalf("Result of setGroupAnnotation() = %v, want %v", patch, test.expected)
+			}
+		})
+	}
+}
@@ -19,19 +19,24 @@ package rolloutorchestrator
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	deploymentinformer "knative.dev/pkg/client/injection/kube/informers/apps/v1/deployment"
+	configmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap"
+	pdbinformer "knative.dev/pkg/client/injection/kube/informers/policy/v1/poddisruptionbudget"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
 	servingclient "knative.dev/serving-progressive-rollout/pkg/client/injection/client"
 	roinformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/rolloutorchestrator"
 	spainformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/stagepodautoscaler"
 	roreconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/rolloutorchestrator"
+	"knative.dev/serving-progressive-rollout/pkg/notification"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/common"
-	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator/strategies"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
 	cfgmap "knative.dev/serving/pkg/apis/config"
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/revision"
 )
@@ -46,18 +51,14 @@ func NewController(
 	stagePodAutoscalerInformer := spainformer.Get(ctx)
 	deploymentInformer := deploymentinformer.Get(ctx)
 	revisionInformer := revisioninformer.Get(ctx)
+	configmapInformer := configmapinformer.Get(ctx)
+	pdbInformer := pdbinformer.Get(ctx)
 
 	configStore := cfgmap.NewStore(logger.Named(common.ConfigStoreName))
 	configStore.WatchConfigs(cmw)
 
-	rolloutStrategy := strategies.NewRolloutStrategy(servingclient.Get(ctx), kubeclient.Get(ctx), stagePodAutoscalerInformer.Lister())
-	c := &Reconciler{
-		client:                   servingclient.Get(ctx),
-		stagePodAutoscalerLister: stagePodAutoscalerInformer.Lister(),
-		deploymentLister:         deploymentInformer.Lister(),
-		revisionLister:           revisionInformer.Lister(),
-		rolloutStrategy:          rolloutStrategy,
-	}
+	c := NewReconciler(servingclient.Get(ctx), kubeclient.Get(ctx), stagePodAutoscalerInformer.Lister(),
+		deploymentInformer.Lister(), revisionInformer.Lister(), pdbInformer.Lister())
 
 	opts := func(*controller.Impl) controller.Options {
 		return controller.Options{ConfigStore: configStore}
@@ -68,6 +69,24 @@ func NewController(
 	impl := roreconciler.NewImpl(ctx, c, opts)
 	c.enqueueAfter = impl.EnqueueAfter
 
+	// The sink of the CloudEvents about the progress of the rollouts is set in the ConfigMap
+	// config-rolloutorchestrator.
+	notifier := notification.NewNotifier(ctx, logger.Named("notification"))
+	c.SetNotifier(notifier)
+	setSink := func(obj interface{}) {
+		if cm, ok := obj.(*corev1.ConfigMap); ok {
+			notifier.SetSink(cm.Data[notification.SinkKey])
+		}
+	}
+	configmapInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithNameAndNamespace(system.Namespace(), resources.ConfigMapName),
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    setSink,
+			UpdateFunc: controller.PassNew(setSink),
+			DeleteFunc: func(interface{}) { notifier.SetSink("") },
+		},
+	})
+
 	// This reconciliation loop of the RolloutOrchestrator will watch the changes of RolloutOrchestrator itself.
 	roInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
 
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolloutorchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/notification"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+)
+
+// notifyRollout sends the CloudEvents about the progress of the rollout of the RolloutOrchestrator, made in the
+// persisted status since the state recorded as notified, and records the persisted state as notified. If the
+// record fails to be persisted, the same CloudEvents are sent again with the same ids.
+func (r *Reconciler) notifyRollout(ro *v1.RolloutOrchestrator) {
+	for _, eventType := range rolloutNotifications(ro.Status.Notified, ro) {
+		message := ""
+		switch eventType {
+		case notification.TypeRolloutPaused:
+			message = ro.Annotations[resources.RolloutPaused]
+		case notification.TypeStageFailed:
+			if cond := ro.Status.GetCondition(v1.SOStageReady); cond != nil {
+				message = cond.Message
+			}
+		}
+		r.notifier.Notify(ro, eventType, notificationID(ro, eventType), message)
+	}
+	ro.Status.Notified = notifiedState(ro)
+}
+
+// notifiedState returns the state of the rollout of the RolloutOrchestrator to be recorded as notified.
+func notifiedState(ro *v1.RolloutOrchestrator) *v1.RolloutNotified {
+	return &v1.RolloutNotified{
+		StageReady:        conditionStatus(ro, v1.SOStageReady),
+		LastStageComplete: conditionStatus(ro, v1.SOLastStageComplete),
+		PausedBy:          ro.Annotations[resources.RolloutPaused],
+	}
+}
+
+// notificationID returns the id of the CloudEvent of the type about the RolloutOrchestrator. It is derived from the
+// UID, the generation and the transition of the rollout, so that it is the same, when the CloudEvent is sent again.
+func notificationID(ro *v1.RolloutOrchestrator, eventType string) string {
+	var transition string
+	switch eventType {
+	case notification.TypeRolloutPaused:
+		transition = ro.Annotations[resources.RolloutPaused]
+	case notification.TypeRolloutCompleted:
+		transition = transitionTime(ro, v1.SOLastStageComplete)
+	default:
+		transition = transitionTime(ro, v1.SOStageReady)
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("%s/%d/%s/%s", ro.UID, ro.Generation, eventType,
+		transition))).String()
+}
+
+func transitionTime(ro *v1.RolloutOrchestrator, condType apis.ConditionType) string {
+	if cond := ro.Status.GetCondition(condType); cond != nil {
+		return cond.LastTransitionTime.Inner.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// rolloutNotifications returns the types of the CloudEvents for the transitions of the conditions and the pause of
+// the RolloutOrchestrator since the notified state. Nothing is notified without the notified state, e.g. for the
+// RolloutOrchestrator created or reconciled the first time since the upgrade.
+func rolloutNotifications(notified *v1.RolloutNotified, ro *v1.RolloutOrchestrator) []string {
+	if notified == nil {
+		return nil
+	}
+	prevStage, stage := notified.StageReady, conditionStatus(ro, v1.SOStageReady)
+	prevLast, last := notified.LastStageComplete, conditionStatus(ro, v1.SOLastStageComplete)
+	rollback := isRollback(ro)
+
+	var eventTypes []string
+	if pausedBy := ro.Annotations[resources.RolloutPaused]; pausedBy != "" && pausedBy != notified.PausedBy {
+		eventTypes = append(eventTypes, notification.TypeRolloutPaused)
+	}
+	switch {
+	case prevStage == corev1.ConditionTrue && stage == corev1.ConditionUnknown && !rollback:
+		// A new stage has started. It is the first stage, if the last stage of the previous rollout was complete.
+		if prevLast == corev1.ConditionTrue {
+			eventTypes = append(eventTypes, notification.TypeRolloutStarted)
+		} else {
+			eventTypes = append(eventTypes, notification.TypeStageAdvanced)
+		}
+	case prevStage != corev1.ConditionFalse && stage == corev1.ConditionFalse:
+		eventTypes = append(eventTypes, notification.TypeStageFailed)
+	case prevStage != corev1.ConditionTrue && stage == corev1.ConditionTrue && rollback:
+		// The traffic is back on the initial revisions, once the stage rolling back is ready.
+		eventTypes = append(eventTypes, notification.TypeRolloutRolledBack)
+	}
+	if prevLast != corev1.ConditionTrue && last == corev1.ConditionTrue && !rollback {
+		eventTypes = append(eventTypes, notification.TypeRolloutCompleted)
+	}
+	return eventTypes
+}
+
+// isRollback returns true, if the traffic of the RolloutOrchestrator goes back to the initial revisions.
+func isRollback(ro *v1.RolloutOrchestrator) bool {
+	_, groupRollback := ro.Annotations[resources.RolloutGroupRollback]
+	return groupRollback || resources.IsRolloutAborted(ro) || resources.IsRolloutReverted(ro)
+}
+
+// conditionStatus returns the status of the condition of the RolloutOrchestrator, or empty, if it is not set.
+func conditionStatus(ro *v1.RolloutOrchestrator, condType apis.ConditionType) corev1.ConditionStatus {
+	if cond := ro.Status.GetCondition(condType); cond != nil {
+		return cond.Status
+	}
+	return ""
+}
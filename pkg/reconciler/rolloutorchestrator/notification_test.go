@@ -0,0 +1,149 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolloutorchestrator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/notification"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+)
+
+func newNotificationRO(stage, last corev1.ConditionStatus, annotations map[string]string) *v1.RolloutOrchestrator {
+	ro := &v1.RolloutOrchestrator{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test",
+		Annotations: annotations}}
+	ro.Status.Conditions = duckv1.Conditions{{
+		Type:   v1.SOStageReady,
+		Status: stage,
+	}, {
+		Type:   v1.SOLastStageComplete,
+		Status: last,
+	}}
+	return ro
+}
+
+func newNotified(stage, last corev1.ConditionStatus, pausedBy string) *v1.RolloutNotified {
+	return &v1.RolloutNotified{StageReady: stage, LastStageComplete: last, PausedBy: pausedBy}
+}
+
+func TestRolloutNotifications(t *testing.T) {
+	rollback := map[string]string{resources.RolloutGroupRollback: "group"}
+	paused := map[string]string{resources.RolloutPaused: "admin"}
+	tests := []struct {
+		name           string
+		notified       *v1.RolloutNotified
+		ro             *v1.RolloutOrchestrator
+		ExpectedResult []string
+	}{{
+		name:           "Test without the notified state",
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, paused),
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the start of the rollout",
+		notified:       newNotified(corev1.ConditionTrue, corev1.ConditionTrue, ""),
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, nil),
+		ExpectedResult: []string{notification.TypeRolloutStarted},
+	}, {
+		name:           "Test the next stage",
+		notified:       newNotified(corev1.ConditionTrue, corev1.ConditionUnknown, ""),
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, nil),
+		ExpectedResult: []string{notification.TypeStageAdvanced},
+	}, {
+		name:           "Test the stage in progress",
+		notified:       newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, ""),
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, nil),
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the failed stage",
+		notified:       newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, ""),
+		ro:             newNotificationRO(corev1.ConditionFalse, corev1.ConditionFalse, nil),
+		ExpectedResult: []string{notification.TypeStageFailed},
+	}, {
+		name:           "Test the completed rollout",
+		notified:       newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, ""),
+		ro:             newNotificationRO(corev1.ConditionTrue, corev1.ConditionTrue, nil),
+		ExpectedResult: []string{notification.TypeRolloutCompleted},
+	}, {
+		name:           "Test the start of the rollback",
+		notified:       newNotified(corev1.ConditionTrue, corev1.ConditionUnknown, ""),
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, rollback),
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the rolled back rollout",
+		notified:       newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, ""),
+		ro:             newNotificationRO(corev1.ConditionTrue, corev1.ConditionUnknown, rollback),
+		ExpectedResult: []string{notification.TypeRolloutRolledBack},
+	}, {
+		name:           "Test the rollout paused",
+		notified:       newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, ""),
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, paused),
+		ExpectedResult: []string{notification.TypeRolloutPaused},
+	}, {
+		name:           "Test the rollout still paused",
+		notified:       newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, "admin"),
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, paused),
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the rollout resumed",
+		notified:       newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, "admin"),
+		ro:             newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown, nil),
+		ExpectedResult: nil,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := rolloutNotifications(test.notified, test.ro); !reflect.DeepEqual(result,
+				test.ExpectedResult) {
+				t.Fatalf("Result of rolloutNotifications() = %v, want %v", result, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestNotifyRollout(t *testing.T) {
+	r := &Reconciler{}
+	ro := newNotificationRO(corev1.ConditionUnknown, corev1.ConditionUnknown,
+		map[string]string{resources.RolloutPaused: "admin"})
+	r.notifyRollout(ro)
+	expected := newNotified(corev1.ConditionUnknown, corev1.ConditionUnknown, "admin")
+	if !reflect.DeepEqual(ro.Status.Notified, expected) {
+		t.Fatalf("Result of notifyRollout() Notified = %v, want %v", ro.Status.Notified, expected)
+	}
+}
+
+func TestNotificationID(t *testing.T) {
+	ro := newNotificationRO(corev1.ConditionFalse, corev1.ConditionFalse, nil)
+	ro.UID = "test-uid"
+	ro.Status.Conditions[0].LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(time.Unix(100, 0))}
+	id := notificationID(ro, notification.TypeStageFailed)
+	if again := notificationID(ro.DeepCopy(), notification.TypeStageFailed); again != id {
+		t.Fatalf("Result of notificationID() for the same transition = %v, want %v", again, id)
+	}
+	if other := notificationID(ro, notification.TypeRolloutCompleted); other == id {
+		t.Fatalf("Result of notificationID() for another type = %v, want different from %v", other, id)
+	}
+	ro.Status.Conditions[0].LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(time.Unix(200, 0))}
+	if next := notificationID(ro, notification.TypeStageFailed); next == id {
+		t.Fatalf("Result of notificationID() for another transition = %v, want different from %v", next, id)
+	}
+}
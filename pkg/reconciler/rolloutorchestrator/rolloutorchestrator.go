@@ -26,7 +26,9 @@ import (
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/ptr"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -34,7 +36,11 @@ import (
 	clientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned"
 	roreconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/rolloutorchestrator"
 	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/notification"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/common"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator/strategies"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/serving"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
 )
@@ -49,11 +55,39 @@ type Reconciler struct {
 	revisionLister           servinglisters.RevisionLister
 	rolloutStrategy          map[string]*strategies.Rollout
 	enqueueAfter             func(interface{}, time.Duration)
+
+	// notifier sends the CloudEvents about the progress of the rollouts.
+	notifier *notification.Notifier
 }
 
 // Check that our Reconciler implements roreconciler.Interface
 var _ roreconciler.Interface = (*Reconciler)(nil)
 
+// NewReconciler creates the reference to the Reconciler based on clientset.Interface, kubernetes.Interface,
+// listers.StagePodAutoscalerLister, appsv1listers.DeploymentLister, servinglisters.RevisionLister and
+// policyv1listers.PodDisruptionBudgetLister.
+func NewReconciler(client clientset.Interface, kubeclient kubernetes.Interface,
+	stagePodAutoscalerLister listers.StagePodAutoscalerLister, deploymentLister appsv1listers.DeploymentLister,
+	revisionLister servinglisters.RevisionLister, pdbLister policyv1listers.PodDisruptionBudgetLister) *Reconciler {
+	return &Reconciler{
+		client:                   client,
+		stagePodAutoscalerLister: stagePodAutoscalerLister,
+		deploymentLister:         deploymentLister,
+		revisionLister:           revisionLister,
+		rolloutStrategy:          strategies.NewRolloutStrategy(client, kubeclient, stagePodAutoscalerLister, pdbLister),
+	}
+}
+
+// SetNotifier sets the Notifier to send the CloudEvents about the progress of the rollouts.
+func (r *Reconciler) SetNotifier(notifier *notification.Notifier) {
+	r.notifier = notifier
+}
+
+// SetEnqueueAfter sets the function to reconcile the RolloutOrchestrator again after a delay.
+func (r *Reconciler) SetEnqueueAfter(enqueueAfter func(interface{}, time.Duration)) {
+	r.enqueueAfter = enqueueAfter
+}
+
 // ReconcileKind implements Interface.ReconcileKind.
 func (r *Reconciler) ReconcileKind(ctx context.Context, ro *v1.RolloutOrchestrator) pkgreconciler.Event {
 	ctx, cancel := context.WithTimeout(ctx, pkgreconciler.DefaultTimeout)
@@ -65,6 +99,19 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, ro *v1.RolloutOrchestrat
 			logger.Errorf("failed to clean up the SPA %s", err.Error())
 		}
 	}()
+	// The CloudEvents are sent for the persisted status, before it is changed by this reconciliation.
+	r.notifyRollout(ro)
+
+	if ro.Spec.ProgressiveRolloutDisabled {
+		// The progressive rollout has been disabled, even if it is in the middle of a rollout. The revisions are
+		// no longer limited by the stages, and the rollout is finalized right away.
+		if err := r.relaxSPAs(ctx, ro); err != nil {
+			return err
+		}
+		ro.Status.SetStageRevisionStatus(ro.Spec.StageTargetRevisions)
+		ro.Status.MarkProgressiveRolloutDisabled()
+		return nil
+	}
 
 	// If spec.StageRevisionStatus is nil, do nothing.
 	if len(ro.Spec.StageTargetRevisions) == 0 {
@@ -95,10 +142,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, ro *v1.RolloutOrchestrat
 		return err
 	}
 	if !ready {
+		r.checkStageDeadline(ro)
 		return nil
 	}
 
-	if ro.IsStageInProgress() {
+	if ro.IsStageInProgress() || ro.IsStageFailed() {
 		// Clean up and set the status of the StageRevision. It means the orchestrator has accomplished this stage.
 		if len(ro.Spec.TargetRevisions) < len(stageTargetRevisions) {
 			stageCleaned := RemoveNonTrafficRev(stageTargetRevisions)
@@ -107,8 +155,13 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, ro *v1.RolloutOrchestrat
 			ro.Status.SetStageRevisionStatus(stageTargetRevisions)
 		}
 
+		// Record how fast this stage finished, so that the next stage can be accelerated.
+		ro.Status.UpdateStageDeltaMultiplier(ro.Spec.TargetFinishTime.Inner.Time)
 		ro.Status.MarkStageRevisionReady()
 		if LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions) {
+			// The next rollout starts without any acceleration or forced deletion.
+			ro.Status.StageDeltaMultiplier = 0
+			ro.Status.ForcedDeletions = 0
 			ro.Status.MarkLastStageRevisionComplete()
 			return nil
 		}
@@ -118,11 +171,21 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, ro *v1.RolloutOrchestrat
 
 	if ro.IsStageReady() && ro.IsInProgress() && !LastStageComplete(ro.Status.StageRevisionStatus,
 		ro.Spec.TargetRevisions) {
+		if StageAccomplished(ro) {
+			// The service has not moved on to the next stage yet, e.g. the ready stage soaks. Keep the stage ready,
+			// so that the time it became ready is kept.
+			return nil
+		}
 		// Start to move to the next stage.
 		ro.Status.LaunchNewStage()
 		return nil
 	}
 
+	// The initial revisions kept on standby after the last stage scale down to 0, once the standby is released.
+	if err = r.releaseStandbySPAs(ctx, ro); err != nil {
+		return err
+	}
+
 	ro.Status.MarkStageRevisionScaleUpReady()
 	ro.Status.MarkStageRevisionScaleDownReady()
 	ro.Status.MarkStageRevisionReady()
@@ -130,6 +193,56 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, ro *v1.RolloutOrchestrat
 	return nil
 }
 
+// checkStageDeadline marks the stage in progress as failed, if it is past the TargetFinishTime and the deployment of
+// any revision in the stage is not available, which is when the service gives up moving on to the next stage.
+// Otherwise, the RolloutOrchestrator is reconciled again at the TargetFinishTime.
+func (r *Reconciler) checkStageDeadline(ro *v1.RolloutOrchestrator) {
+	// The paused rollout does not time out.
+	if _, paused := ro.Annotations[resources.RolloutPaused]; paused || !ro.IsStageInProgress() ||
+		ro.Spec.TargetFinishTime.Inner.IsZero() {
+		return
+	}
+	if wait := time.Until(ro.Spec.TargetFinishTime.Inner.Time); wait > 0 {
+		if r.enqueueAfter != nil {
+			r.enqueueAfter(ro, wait)
+		}
+		return
+	}
+	if err := CheckDeploymentsAvailable(ro, r.deploymentLister); err != nil {
+		ro.Status.MarkStageRevisionFailed(err.Error())
+	}
+}
+
+// releaseStandbySPAs sets the StageMinScale to 0 and StageMaxScale to 1 for the initial revisions, whose traffic has
+// moved away in the last stage, if they are no longer kept on standby.
+func (r *Reconciler) releaseStandbySPAs(ctx context.Context, ro *v1.RolloutOrchestrator) error {
+	if strategies.StandbyReplicas(ro) > 0 || !LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions) {
+		return nil
+	}
+	for _, rev := range ro.Spec.StageTargetRevisions {
+		if rev.Percent != nil || !rev.IsRevScalingDown() {
+			continue
+		}
+		spa, err := r.stagePodAutoscalerLister.StagePodAutoscalers(ro.Namespace).Get(rev.RevisionName)
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if spa.Spec.StageMinScale != nil && *spa.Spec.StageMinScale == 0 &&
+			spa.Spec.StageMaxScale != nil && *spa.Spec.StageMaxScale == 1 {
+			continue
+		}
+		spa = spa.DeepCopy()
+		spa.Spec.StageMinScale = ptr.Int32(0)
+		spa.Spec.StageMaxScale = ptr.Int32(1)
+		if _, err = r.client.ServingV1().StagePodAutoscalers(ro.Namespace).Update(ctx, spa, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // resetObsoleteSPAs will set the StageMinScale to 0 and StageMaxScale to 1, if the revision with this spa is
 // not in ro.Spec.StageTargetRevisions.
 func (r *Reconciler) resetObsoleteSPAs(ctx context.Context, ro *v1.RolloutOrchestrator) error {
@@ -166,26 +279,42 @@ func (r *Reconciler) resetObsoleteSPAs(ctx context.Context, ro *v1.RolloutOrches
 	return nil
 }
 
-// cleanUpSPAs will delete the SPA associated with the revision that is deleted.
-func (r *Reconciler) cleanUpSPAs(ctx context.Context, ro *v1.RolloutOrchestrator) error {
-	records := map[string]bool{}
-	// Get the list of all the revisions for the knative service.
-	revList, err := r.revisionLister.Revisions(ro.Namespace).List(labels.SelectorFromSet(labels.Set{
-		serving.ConfigurationLabelKey: ro.Name,
-		serving.ServiceLabelKey:       ro.Name,
+// relaxSPAs sets the StageMinScale and StageMaxScale of all the SPAs for the knative service to the min and max
+// scales of their revisions, so that the SPAs no longer narrow the scale bounds of the revisions.
+func (r *Reconciler) relaxSPAs(ctx context.Context, ro *v1.RolloutOrchestrator) error {
+	spaList, err := r.stagePodAutoscalerLister.StagePodAutoscalers(ro.Namespace).List(labels.SelectorFromSet(labels.Set{
+		serving.ServiceLabelKey: ro.Name,
 	}))
-
-	if apierrs.IsNotFound(err) {
-		return nil
-	} else if err != nil {
+	if err != nil {
 		return err
 	}
-
-	for _, rev := range revList {
-		records[rev.Name] = true
+	for _, spa := range spaList {
+		// The SPA and the revision share the same name.
+		rev, err := r.revisionLister.Revisions(ro.Namespace).Get(spa.Name)
+		if apierrs.IsNotFound(err) {
+			// The SPA of the deleted revision is deleted by cleanUpSPAs.
+			continue
+		} else if err != nil {
+			return err
+		}
+		minScale := resources.ReadIntAnnotation(rev, autoscaling.MinScaleAnnotationKey)
+		maxScale := resources.ReadIntAnnotation(rev, autoscaling.MaxScaleAnnotationKey)
+		if equality.Semantic.DeepEqual(spa.Spec.StageMinScale, minScale) &&
+			equality.Semantic.DeepEqual(spa.Spec.StageMaxScale, maxScale) {
+			continue
+		}
+		spa = spa.DeepCopy()
+		spa.Spec.StageMinScale, spa.Spec.StageMaxScale = minScale, maxScale
+		if _, err = r.client.ServingV1().StagePodAutoscalers(ro.Namespace).Update(ctx, spa, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Get the list of all the SPAs for the knative service.
+// cleanUpSPAs will delete the SPA associated with the revision that is deleted.
+func (r *Reconciler) cleanUpSPAs(ctx context.Context, ro *v1.RolloutOrchestrator) error {
+	// Get the list of all the SPAs for the knative service, or the standalone route.
 	spaList, err := r.stagePodAutoscalerLister.StagePodAutoscalers(ro.Namespace).List(labels.SelectorFromSet(labels.Set{
 		serving.ServiceLabelKey: ro.Name,
 	}))
@@ -197,17 +326,29 @@ func (r *Reconciler) cleanUpSPAs(ctx context.Context, ro *v1.RolloutOrchestrator
 	}
 
 	for _, spa := range spaList {
-		// The SPA and the revision share the same name. If the revision is gone, delete the SPA.
-		if !records[spa.Name] {
-			err = r.client.ServingV1().StagePodAutoscalers(ro.Namespace).Delete(ctx, spa.Name, metav1.DeleteOptions{})
-			if err != nil {
-				return err
-			}
+		// The SPA and the revision share the same name. If the revision is gone, delete the SPA. The revision is
+		// looked up by its name, since the revisions of a standalone route are not labeled with the service.
+		_, err = r.revisionLister.Revisions(ro.Namespace).Get(spa.Name)
+		if err == nil {
+			continue
+		} else if !apierrs.IsNotFound(err) {
+			return err
+		}
+		err = r.client.ServingV1().StagePodAutoscalers(ro.Namespace).Delete(ctx, spa.Name, metav1.DeleteOptions{})
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// StageAccomplished returns true, if the StageTargetRevisions in the spec are the stage already accomplished
+// and recorded in the status.
+func StageAccomplished(ro *v1.RolloutOrchestrator) bool {
+	return equality.Semantic.DeepEqual(ro.Status.StageRevisionStatus, ro.Spec.StageTargetRevisions) ||
+		equality.Semantic.DeepEqual(ro.Status.StageRevisionStatus, RemoveNonTrafficRev(ro.Spec.StageTargetRevisions))
+}
+
 // RemoveNonTrafficRev removes the redundant TargetRevision from the list of TargetRevisions.
 func RemoveNonTrafficRev(ts []v1.TargetRevision) []v1.TargetRevision {
 	result := make([]v1.TargetRevision, 0, len(ts))
@@ -257,6 +398,26 @@ func RetrieveRevsUpDown(targetRevs []v1.TargetRevision) (map[string]*v1.TargetRe
 	return targetRevsUp, targetRevsDown, nil
 }
 
+// CheckDeploymentsAvailable returns an error, if the deployment of any revision in the StageTargetRevisions is not
+// available.
+func CheckDeploymentsAvailable(ro *v1.RolloutOrchestrator, deploymentLister appsv1listers.DeploymentLister) error {
+	for _, rev := range ro.Spec.StageTargetRevisions {
+		selector := labels.SelectorFromSet(labels.Set{
+			serving.RevisionLabelKey: rev.RevisionName,
+		})
+		deps, err := deploymentLister.Deployments(ro.Namespace).List(selector)
+		if apierrs.IsNotFound(err) {
+			return fmt.Errorf("error no deployment was not found for the revision %s", rev.RevisionName)
+		} else if err != nil {
+			return err
+		}
+		if len(deps) > 0 && !common.IsDeploymentAvailable(deps[0]) {
+			return fmt.Errorf("error the deployment for the revision %s was not ready, when the timeout limit hit", rev.RevisionName)
+		}
+	}
+	return nil
+}
+
 // LastStageComplete decides whether the last stage of the progressive upgrade is complete or not.
 func LastStageComplete(stageRevisionStatus, finalTargetRevs []v1.TargetRevision) bool {
 	return equality.Semantic.DeepEqual(stageRevisionStatus, finalTargetRevs) ||
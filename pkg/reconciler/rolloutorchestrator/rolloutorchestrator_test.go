@@ -17,13 +17,21 @@ limitations under the License.
 package rolloutorchestrator
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/ptr"
 	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	fakeclientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned/fake"
+	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
 )
 
 func TestRemoveNonTrafficRev(t *testing.T) {
@@ -94,6 +102,57 @@ func TestRemoveNonTrafficRev(t *testing.T) {
 	}
 }
 
+func TestStageAccomplished(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+		Direction:     v1.DirectionDown,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20)},
+		Direction:     v1.DirectionUp,
+	}}
+	nextStage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(60)},
+		Direction:     v1.DirectionDown,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(40)},
+		Direction:     v1.DirectionUp,
+	}}
+	tests := []struct {
+		name           string
+		spec           []v1.TargetRevision
+		status         []v1.TargetRevision
+		ExpectedResult bool
+	}{{
+		name:           "Test the stage recorded in the status",
+		spec:           stage,
+		status:         stage,
+		ExpectedResult: true,
+	}, {
+		name: "Test the stage recorded without the revision of no traffic",
+		spec: append([]v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-000", Percent: ptr.Int64(0)},
+			Direction:     v1.DirectionDown,
+		}}, stage...),
+		status:         stage,
+		ExpectedResult: true,
+	}, {
+		name:           "Test the next stage",
+		spec:           nextStage,
+		status:         stage,
+		ExpectedResult: false,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{}
+			ro.Spec.StageTargetRevisions = test.spec
+			ro.Status.StageRevisionStatus = test.status
+			if r := StageAccomplished(ro); r != test.ExpectedResult {
+				t.Fatalf("Result of StageAccomplished() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
 func TestLastStageComplete(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -326,3 +385,149 @@ func TestRetrieveRevsUpDown(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileKindProgressiveRolloutDisabled(t *testing.T) {
+	rev := &servingv1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rev-002",
+			Namespace: "default",
+			Labels: map[string]string{
+				serving.ConfigurationLabelKey: "svc",
+				serving.ServiceLabelKey:       "svc",
+			},
+			Annotations: map[string]string{
+				autoscaling.MinScaleAnnotationKey: "2",
+				autoscaling.MaxScaleAnnotationKey: "5",
+			},
+		},
+	}
+	spa := &v1.StagePodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rev-002",
+			Namespace: "default",
+			Labels:    map[string]string{serving.ServiceLabelKey: "svc"},
+		},
+		Spec: v1.StagePodAutoscalerSpec{
+			StageMinScale: ptr.Int32(1),
+			StageMaxScale: ptr.Int32(1),
+		},
+	}
+	stageTarget := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100)},
+	}}
+	ro := &v1.RolloutOrchestrator{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+		Spec: v1.RolloutOrchestratorSpec{
+			StageTarget: v1.StageTarget{
+				StageTargetRevisions:       stageTarget,
+				ProgressiveRolloutDisabled: true,
+			},
+			TargetRevisions: stageTarget,
+		},
+	}
+	ro.Status.InitializeConditions()
+	ro.Status.LaunchNewStage()
+
+	client := fakeclientset.NewSimpleClientset(spa)
+	spaIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	spaIndexer.Add(spa)
+	revIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	revIndexer.Add(rev)
+	r := &Reconciler{
+		client:                   client,
+		stagePodAutoscalerLister: listers.NewStagePodAutoscalerLister(spaIndexer),
+		revisionLister:           servinglisters.NewRevisionLister(revIndexer),
+	}
+
+	if err := r.ReconcileKind(context.Background(), ro); err != nil {
+		t.Fatalf("ReconcileKind() = %v", err)
+	}
+	got, err := client.ServingV1().StagePodAutoscalers("default").Get(context.Background(), "rev-002", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if !reflect.DeepEqual(got.Spec.StageMinScale, ptr.Int32(2)) || !reflect.DeepEqual(got.Spec.StageMaxScale, ptr.Int32(5)) {
+		t.Fatalf("Result of ReconcileKind() SPA bounds = %v, %v, want 2, 5", *got.Spec.StageMinScale,
+			*got.Spec.StageMaxScale)
+	}
+	if !ro.IsReady() || ro.Status.GetCondition(v1.SOLastStageComplete).Reason != v1.RolloutDisabled {
+		t.Fatalf("Result of ReconcileKind() status = %v, want ready with the reason %s", ro.Status.Conditions,
+			v1.RolloutDisabled)
+	}
+	if !reflect.DeepEqual(ro.Status.StageRevisionStatus, stageTarget) {
+		t.Fatalf("Result of ReconcileKind() StageRevisionStatus = %v, want %v", ro.Status.StageRevisionStatus,
+			stageTarget)
+	}
+}
+
+func TestReleaseStandbySPAs(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001"},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(0),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100)},
+		Direction:     v1.DirectionUp,
+	}}
+	tests := []struct {
+		name           string
+		standby        *v1.PostRolloutStandby
+		ExpectedResult v1.StagePodAutoscalerSpec
+	}{{
+		name:           "Test the standby kept",
+		standby:        &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+		ExpectedResult: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(2), StageMaxScale: ptr.Int32(2)},
+	}, {
+		name:           "Test the standby released",
+		standby:        &v1.PostRolloutStandby{Replicas: 0, BakeSeconds: 600},
+		ExpectedResult: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(0), StageMaxScale: ptr.Int32(1)},
+	}, {
+		name:           "Test the standby disabled",
+		ExpectedResult: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(0), StageMaxScale: ptr.Int32(1)},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spa := &v1.StagePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "rev-001", Namespace: "default"},
+				Spec:       v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(2), StageMaxScale: ptr.Int32(2)},
+			}
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+					}},
+					TargetRevisions: stage[1:],
+					StageTarget: v1.StageTarget{
+						StageTargetRevisions: stage,
+						PostRolloutStandby:   test.standby,
+					},
+				},
+				Status: v1.RolloutOrchestratorStatus{
+					RolloutOrchestratorStatusFields: v1.RolloutOrchestratorStatusFields{
+						StageRevisionStatus: stage[1:],
+					},
+				},
+			}
+
+			client := fakeclientset.NewSimpleClientset(spa)
+			spaIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			spaIndexer.Add(spa)
+			r := &Reconciler{
+				client:                   client,
+				stagePodAutoscalerLister: listers.NewStagePodAutoscalerLister(spaIndexer),
+			}
+			if err := r.releaseStandbySPAs(context.Background(), ro); err != nil {
+				t.Fatalf("releaseStandbySPAs() = %v", err)
+			}
+			got, err := client.ServingV1().StagePodAutoscalers("default").Get(context.Background(), "rev-001",
+				metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Get() = %v", err)
+			}
+			if !reflect.DeepEqual(got.Spec, test.ExpectedResult) {
+				t.Fatalf("Result of releaseStandbySPAs() = %v, want %v", got.Spec, test.ExpectedResult)
+			}
+		})
+	}
+}
@@ -18,18 +18,22 @@ package strategies
 
 import (
 	"context"
+	"maps"
 	"math"
 	"strings"
 	"time"
 
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/ptr"
 	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
 	clientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned"
 	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/tracing"
 	"knative.dev/serving/pkg/apis/serving"
 )
 
@@ -58,9 +62,10 @@ type RolloutStep interface {
 // The BaseScaleStep struct defines golang clients, that are necessary to access the kubernetes resources.
 // It also consists of the functions to create or update the SPAs for the revisions.
 type BaseScaleStep struct {
-	Client                   clientset.Interface
-	Kubeclient               kubernetes.Interface
-	StagePodAutoscalerLister listers.StagePodAutoscalerLister
+	Client                    clientset.Interface
+	Kubeclient                kubernetes.Interface
+	StagePodAutoscalerLister  listers.StagePodAutoscalerLister
+	PodDisruptionBudgetLister policyv1listers.PodDisruptionBudgetLister
 }
 
 type updateSPAForRev func(*v1.StagePodAutoscaler, *v1.TargetRevision, bool) *v1.StagePodAutoscaler
@@ -74,17 +79,34 @@ func (r *BaseScaleStep) CreateOrUpdateSPARev(ctx context.Context, ro *v1.Rollout
 	if err != nil {
 		return spa, err
 	}
-	return r.Client.ServingV1().StagePodAutoscalers(ro.Namespace).Update(ctx,
-		fn(spa, targetRev, scaleUpReady), metav1.UpdateOptions{})
+	spa = fn(spa, targetRev, scaleUpReady)
+	setStageTraceParent(spa, ro)
+	return r.Client.ServingV1().StagePodAutoscalers(ro.Namespace).Update(ctx, spa, metav1.UpdateOptions{})
 }
 
 func (r *BaseScaleStep) createStagePA(ctx context.Context, ro *v1.RolloutOrchestrator, revision *v1.TargetRevision,
 	scaleUpReady bool, fn updateSPAForRev) (*v1.StagePodAutoscaler, error) {
 	spa := CreateBaseStagePodAutoscaler(ro, revision)
 	spa = fn(spa, revision, scaleUpReady)
+	setStageTraceParent(spa, ro)
 	return r.Client.ServingV1().StagePodAutoscalers(ro.Namespace).Create(ctx, spa, metav1.CreateOptions{})
 }
 
+// setStageTraceParent copies the trace context of the current stage from the RolloutOrchestrator to the SPA, so
+// that the scaling decisions of the autoscaler are traced as part of the stage.
+func setStageTraceParent(spa *v1.StagePodAutoscaler, ro *v1.RolloutOrchestrator) {
+	traceParent := ro.Annotations[tracing.StageTraceParentAnnotation]
+	if spa.Annotations[tracing.StageTraceParentAnnotation] == traceParent {
+		return
+	}
+	annotations := maps.Clone(spa.Annotations)
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	tracing.SetAnnotation(annotations, tracing.StageTraceParentAnnotation, traceParent)
+	spa.Annotations = annotations
+}
+
 // CreateBaseStagePodAutoscaler returns the basic spa(StagePodAutoscaler), base
 // on the RolloutOrchestrator and the revision.
 func CreateBaseStagePodAutoscaler(ro *v1.RolloutOrchestrator, revision *v1.TargetRevision) (spa *v1.StagePodAutoscaler) {
@@ -135,6 +157,78 @@ func UpdateSPAForRevUp(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision,
 	return spa
 }
 
+// StageWarmUpReplicas returns the number of replicas the revision scaling up is pre-scaled to in the current stage
+// of the RolloutOrchestrator. 0 means the revision is not pre-scaled.
+func StageWarmUpReplicas(ro *v1.RolloutOrchestrator) int32 {
+	if ro.Spec.StageWarmUp == nil {
+		return 0
+	}
+	return ro.Spec.StageWarmUp.Replicas
+}
+
+// warmUpSPAForRevUp returns the function to update the SPA(StagePodAutoscaler) for the revision scaling up, which
+// keeps at least the pre-scaled number of replicas for the revision, so that the revision is able to handle its
+// traffic share, before the traffic moves.
+func warmUpSPAForRevUp(replicas int32) updateSPAForRev {
+	return func(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision, scaleUpReady bool) *v1.StagePodAutoscaler {
+		spa = UpdateSPAForRevUp(spa, revision, scaleUpReady)
+		warmUp := min(replicas, getMaxScale(revision))
+		if spa.Spec.StageMinScale == nil || *spa.Spec.StageMinScale < warmUp {
+			spa.Spec.StageMinScale = ptr.Int32(warmUp)
+		}
+		return spa
+	}
+}
+
+// StandbyReplicas returns the number of replicas the initial revisions of the RolloutOrchestrator keep on standby,
+// once their traffic has moved away. 0 means the initial revisions scale down to 0.
+func StandbyReplicas(ro *v1.RolloutOrchestrator) int32 {
+	if ro.Spec.PostRolloutStandby == nil {
+		return 0
+	}
+	return ro.Spec.PostRolloutStandby.Replicas
+}
+
+// standbyReplicasForRev returns the number of replicas the revision scaling down keeps on standby. Only an initial
+// revision, whose traffic has been reduced down to 0%, is kept on standby.
+func standbyReplicasForRev(ro *v1.RolloutOrchestrator, revision *v1.TargetRevision) int32 {
+	replicas := StandbyReplicas(ro)
+	if replicas <= 0 || revision.Percent != nil {
+		return 0
+	}
+	for _, initRev := range ro.Spec.InitialRevisions {
+		if initRev.RevisionName == revision.RevisionName {
+			return min(replicas, getMaxScale(revision))
+		}
+	}
+	return 0
+}
+
+// standbyRevision returns the revision scaling down with the number of replicas it keeps on standby as the target,
+// so that the scaling down is ready with the replicas on standby still running.
+func standbyRevision(ro *v1.RolloutOrchestrator, revision *v1.TargetRevision) *v1.TargetRevision {
+	replicas := standbyReplicasForRev(ro, revision)
+	if replicas == 0 {
+		return revision
+	}
+	standby := revision.DeepCopy()
+	standby.TargetReplicas = ptr.Int32(replicas)
+	return standby
+}
+
+// standbySPAForRevDown returns the function to update the SPA(StagePodAutoscaler) for the revision scaling down,
+// which keeps the replicas on standby for the initial revision reduced down to 0% of the traffic.
+func standbySPAForRevDown(ro *v1.RolloutOrchestrator) updateSPAForRev {
+	return func(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision, scaleUpReady bool) *v1.StagePodAutoscaler {
+		spa = UpdateSPAForRevDown(spa, revision, scaleUpReady)
+		if replicas := standbyReplicasForRev(ro, revision); scaleUpReady && replicas > 0 {
+			spa.Spec.StageMinScale = ptr.Int32(replicas)
+			spa.Spec.StageMaxScale = ptr.Int32(replicas)
+		}
+		return spa
+	}
+}
+
 // UpdateSPAForRevDown update the SPA(StagePodAutoscaler) for the revision scaling down, based on the TargetReplicas
 // min & max scales defined in the Knative Service, if the scaleUpReady is true.
 //
@@ -206,16 +300,18 @@ func getMaxScale(revision *v1.TargetRevision) (maxR int32) {
 }
 
 // IsStageScaleUpReady decides whether the scaling up has completed or on the way for the current stage, based
-// on the revision and the spa(StagePodAutoscaler).
-func IsStageScaleUpReady(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision) bool {
+// on the revision and the spa(StagePodAutoscaler). The actual replicas may fall short of the required replicas
+// within the tolerance, so that a few pods failing to start do not block the stage.
+func IsStageScaleUpReady(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision, tolerance *intstr.IntOrString) bool {
 	if spa.Status.DesiredScale == nil || spa.Status.ActualScale == nil {
 		return false
 	}
 	minR := getMinScale(revision)
 	maxR := getMaxScale(revision)
+	tolerated := ScaleUpTolerance(tolerance, requiredScaleUpReplicas(revision))
 	if revision.TargetReplicas == nil {
 		// For revision scaling up without TargetReplicas, it means this revision will be assigned 100% of the traffic.
-		return actualScaleBetweenMinMax(spa, minR, maxR)
+		return actualScaleBetweenMinMax(spa, minR-tolerated, maxR)
 	}
 
 	// There are two modes to scale up and down the replicas of the revisions:
@@ -230,11 +326,58 @@ func IsStageScaleUpReady(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision
 	// new revision is on the way of scaling up, we are able to start the scaling down phase as well.
 	if minR >= *revision.TargetReplicas {
 		// This is for the first mode.
-		return *spa.Status.DesiredScale >= *revision.TargetReplicas && *spa.Status.ActualScale >= *revision.TargetReplicas
+		return *spa.Status.DesiredScale >= *revision.TargetReplicas &&
+			*spa.Status.ActualScale >= *revision.TargetReplicas-tolerated
 	}
 
 	// This is for the second mode.
-	return *spa.Status.DesiredScale >= minR && *spa.Status.ActualScale >= minR
+	return *spa.Status.DesiredScale >= minR && *spa.Status.ActualScale >= minR-tolerated
+}
+
+// ScaleUpTolerance returns the number of the replicas out of the required replicas, that the revision scaling up
+// may still miss, when the stage is considered scaled up. At least one replica is always required.
+func ScaleUpTolerance(tolerance *intstr.IntOrString, required int32) int32 {
+	if tolerance == nil || required <= 1 {
+		return 0
+	}
+	// The percentage is rounded down, so that no more replicas are tolerated than configured.
+	tolerated, err := intstr.GetScaledValueFromIntOrPercent(tolerance, int(required), false)
+	if err != nil || tolerated <= 0 {
+		return 0
+	}
+	return min(int32(tolerated), required-1)
+}
+
+// ScaleUpShortfall returns the number of the replicas the revision scaling up still misses in the current stage,
+// based on the revision and the spa(StagePodAutoscaler).
+func ScaleUpShortfall(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision) int32 {
+	required := requiredScaleUpReplicas(revision)
+	if spa.Status.ActualScale == nil {
+		return required
+	}
+	return max(required-*spa.Status.ActualScale, 0)
+}
+
+// requiredScaleUpReplicas returns the number of the replicas the revision scaling up has to run, before the stage
+// is scaled up. It is the TargetReplicas for the revision without traffic, and the minScale otherwise.
+func requiredScaleUpReplicas(revision *v1.TargetRevision) int32 {
+	minR := getMinScale(revision)
+	if revision.TargetReplicas != nil && minR >= *revision.TargetReplicas {
+		return *revision.TargetReplicas
+	}
+	return minR
+}
+
+// IsStageWarmedUp decides whether the revision scaling up runs at least the pre-scaled number of replicas, based
+// on the revision and the spa(StagePodAutoscaler).
+func IsStageWarmedUp(spa *v1.StagePodAutoscaler, revision *v1.TargetRevision, replicas int32) bool {
+	if replicas <= 0 {
+		return true
+	}
+	if spa.Status.ActualScale == nil {
+		return false
+	}
+	return *spa.Status.ActualScale >= min(replicas, getMaxScale(revision))
 }
 
 // IsStageScaleDownReady decides whether the scaling down has completed for the current stage, based
@@ -21,9 +21,11 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/ptr"
 	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/tracing"
 	"knative.dev/serving/pkg/apis/serving"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
@@ -33,6 +35,7 @@ func TestIsStageScaleUpReady(t *testing.T) {
 		name           string
 		spa            *v1.StagePodAutoscaler
 		revision       *v1.TargetRevision
+		tolerance      *intstr.IntOrString
 		ExpectedResult bool
 	}{{
 		name:           "Test when both of StagePodAutoscaler and TargetRevision are empty",
@@ -292,11 +295,73 @@ func TestIsStageScaleUpReady(t *testing.T) {
 			MaxScale:  ptr.Int32(9),
 		},
 		ExpectedResult: false,
+	}, {
+		name: "Test ActualScale < TargetReplicas <= MinScale without tolerance",
+		spa: &v1.StagePodAutoscaler{
+			Status: v1.StagePodAutoscalerStatus{
+				DesiredScale: ptr.Int32(10),
+				ActualScale:  ptr.Int32(9),
+			},
+		},
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(50)},
+			Direction:      "up",
+			TargetReplicas: ptr.Int32(10),
+			MinScale:       ptr.Int32(10),
+		},
+		ExpectedResult: false,
+	}, {
+		name: "Test ActualScale < TargetReplicas <= MinScale within the percentage tolerance",
+		spa: &v1.StagePodAutoscaler{
+			Status: v1.StagePodAutoscalerStatus{
+				DesiredScale: ptr.Int32(10),
+				ActualScale:  ptr.Int32(9),
+			},
+		},
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(50)},
+			Direction:      "up",
+			TargetReplicas: ptr.Int32(10),
+			MinScale:       ptr.Int32(10),
+		},
+		tolerance:      intOrString("10%"),
+		ExpectedResult: true,
+	}, {
+		name: "Test ActualScale < TargetReplicas <= MinScale beyond the tolerance",
+		spa: &v1.StagePodAutoscaler{
+			Status: v1.StagePodAutoscalerStatus{
+				DesiredScale: ptr.Int32(10),
+				ActualScale:  ptr.Int32(8),
+			},
+		},
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(50)},
+			Direction:      "up",
+			TargetReplicas: ptr.Int32(10),
+			MinScale:       ptr.Int32(10),
+		},
+		tolerance:      intOrString("1"),
+		ExpectedResult: false,
+	}, {
+		name: "Test ActualScale < MinScale without TargetReplicas within the tolerance",
+		spa: &v1.StagePodAutoscaler{
+			Status: v1.StagePodAutoscalerStatus{
+				DesiredScale: ptr.Int32(3),
+				ActualScale:  ptr.Int32(2),
+			},
+		},
+		revision: &v1.TargetRevision{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(100)},
+			Direction:     "up",
+			MinScale:      ptr.Int32(3),
+		},
+		tolerance:      intOrString("1"),
+		ExpectedResult: true,
 	}}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			r := IsStageScaleUpReady(test.spa, test.revision)
+			r := IsStageScaleUpReady(test.spa, test.revision, test.tolerance)
 			if r != test.ExpectedResult {
 				t.Fatalf("Result of IsStageScaleUpReady() = %v, want %v", r, test.ExpectedResult)
 			}
@@ -538,6 +603,251 @@ func TestIsStageScaleDownReady(t *testing.T) {
 	}
 }
 
+func intOrString(val string) *intstr.IntOrString {
+	value := intstr.Parse(val)
+	return &value
+}
+
+func TestScaleUpTolerance(t *testing.T) {
+	tests := []struct {
+		name           string
+		tolerance      *intstr.IntOrString
+		required       int32
+		ExpectedResult int32
+	}{{
+		name:           "Test without tolerance",
+		required:       10,
+		ExpectedResult: 0,
+	}, {
+		name:           "Test the percentage rounded down",
+		tolerance:      intOrString("15%"),
+		required:       10,
+		ExpectedResult: 1,
+	}, {
+		name:           "Test the absolute number",
+		tolerance:      intOrString("2"),
+		required:       10,
+		ExpectedResult: 2,
+	}, {
+		name:           "Test the tolerance keeping one replica required",
+		tolerance:      intOrString("5"),
+		required:       3,
+		ExpectedResult: 2,
+	}, {
+		name:           "Test the single replica",
+		tolerance:      intOrString("50%"),
+		required:       1,
+		ExpectedResult: 0,
+	}, {
+		name:           "Test the invalid tolerance",
+		tolerance:      intOrString("many"),
+		required:       10,
+		ExpectedResult: 0,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := ScaleUpTolerance(test.tolerance, test.required); r != test.ExpectedResult {
+				t.Fatalf("Result of ScaleUpTolerance() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestIsStageWarmedUp(t *testing.T) {
+	tests := []struct {
+		name           string
+		spa            *v1.StagePodAutoscaler
+		revision       *v1.TargetRevision
+		replicas       int32
+		ExpectedResult bool
+	}{{
+		name:           "Test the revision not pre-scaled",
+		spa:            &v1.StagePodAutoscaler{},
+		revision:       &v1.TargetRevision{},
+		replicas:       0,
+		ExpectedResult: true,
+	}, {
+		name:           "Test when the StagePodAutoscaler status is empty",
+		spa:            &v1.StagePodAutoscaler{},
+		revision:       &v1.TargetRevision{},
+		replicas:       3,
+		ExpectedResult: false,
+	}, {
+		name: "Test ActualScale < pre-scaled replicas",
+		spa: &v1.StagePodAutoscaler{
+			Status: v1.StagePodAutoscalerStatus{ActualScale: ptr.Int32(2)},
+		},
+		revision:       &v1.TargetRevision{},
+		replicas:       3,
+		ExpectedResult: false,
+	}, {
+		name: "Test ActualScale == pre-scaled replicas",
+		spa: &v1.StagePodAutoscaler{
+			Status: v1.StagePodAutoscalerStatus{ActualScale: ptr.Int32(3)},
+		},
+		revision:       &v1.TargetRevision{},
+		replicas:       3,
+		ExpectedResult: true,
+	}, {
+		name: "Test ActualScale == MaxScale < pre-scaled replicas",
+		spa: &v1.StagePodAutoscaler{
+			Status: v1.StagePodAutoscalerStatus{ActualScale: ptr.Int32(2)},
+		},
+		revision:       &v1.TargetRevision{MaxScale: ptr.Int32(2)},
+		replicas:       3,
+		ExpectedResult: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := IsStageWarmedUp(test.spa, test.revision, test.replicas); r != test.ExpectedResult {
+				t.Fatalf("Result of IsStageWarmedUp() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestWarmUpSPAForRevUp(t *testing.T) {
+	tests := []struct {
+		name           string
+		revision       *v1.TargetRevision
+		replicas       int32
+		ExpectedResult *v1.StagePodAutoscaler
+	}{{
+		name: "Test the pre-scaled replicas above TargetReplicas",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(20)},
+			TargetReplicas: ptr.Int32(1),
+			MinScale:       ptr.Int32(5),
+			MaxScale:       ptr.Int32(10),
+		},
+		replicas: 3,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(3), StageMaxScale: ptr.Int32(10)},
+		},
+	}, {
+		name: "Test the pre-scaled replicas below TargetReplicas",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(20)},
+			TargetReplicas: ptr.Int32(4),
+			MinScale:       ptr.Int32(5),
+			MaxScale:       ptr.Int32(10),
+		},
+		replicas: 3,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(4), StageMaxScale: ptr.Int32(10)},
+		},
+	}, {
+		name: "Test the pre-scaled replicas bounded by MaxScale",
+		revision: &v1.TargetRevision{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(100)},
+			MinScale:      ptr.Int32(1),
+			MaxScale:      ptr.Int32(10),
+		},
+		replicas: 12,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(10), StageMaxScale: ptr.Int32(10)},
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := warmUpSPAForRevUp(test.replicas)(&v1.StagePodAutoscaler{}, test.revision, true)
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of warmUpSPAForRevUp() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestStandbySPAForRevDown(t *testing.T) {
+	initialRevisions := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(100)},
+	}}
+	tests := []struct {
+		name           string
+		revision       *v1.TargetRevision
+		standby        *v1.PostRolloutStandby
+		scaleUpReady   bool
+		ExpectedResult *v1.StagePodAutoscaler
+	}{{
+		name: "Test the initial revision reduced down to 0% without standby",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001"},
+			TargetReplicas: ptr.Int32(0),
+		},
+		scaleUpReady: true,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(0), StageMaxScale: ptr.Int32(1)},
+		},
+	}, {
+		name: "Test the initial revision reduced down to 0% on standby",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001"},
+			TargetReplicas: ptr.Int32(0),
+		},
+		standby:      &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+		scaleUpReady: true,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(2), StageMaxScale: ptr.Int32(2)},
+		},
+	}, {
+		name: "Test the replicas on standby bounded by MaxScale",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001"},
+			TargetReplicas: ptr.Int32(0),
+			MaxScale:       ptr.Int32(1),
+		},
+		standby:      &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+		scaleUpReady: true,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(1), StageMaxScale: ptr.Int32(1)},
+		},
+	}, {
+		name: "Test the initial revision still taking traffic",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(40)},
+			TargetReplicas: ptr.Int32(4),
+		},
+		standby:      &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+		scaleUpReady: true,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMaxScale: ptr.Int32(4)},
+		},
+	}, {
+		name: "Test the revision not in the initial revisions",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0002"},
+			TargetReplicas: ptr.Int32(0),
+		},
+		standby:      &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+		scaleUpReady: true,
+		ExpectedResult: &v1.StagePodAutoscaler{
+			Spec: v1.StagePodAutoscalerSpec{StageMinScale: ptr.Int32(0), StageMaxScale: ptr.Int32(1)},
+		},
+	}, {
+		name: "Test the scaling up not ready",
+		revision: &v1.TargetRevision{
+			TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0001"},
+			TargetReplicas: ptr.Int32(0),
+		},
+		standby:        &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+		ExpectedResult: &v1.StagePodAutoscaler{},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: initialRevisions,
+					StageTarget:      v1.StageTarget{PostRolloutStandby: test.standby},
+				},
+			}
+			r := standbySPAForRevDown(ro)(&v1.StagePodAutoscaler{}, test.revision, test.scaleUpReady)
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of standbySPAForRevDown() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
 func TestUpdateWithTargetReplicasRevUp(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -882,3 +1192,34 @@ func TestCreateBaseStagePodAutoscaler(t *testing.T) {
 		t.Fatalf("Result of CreateBaseStagePodAutoscaler() = %v, want %v", spa, expectedSPA)
 	}
 }
+
+func TestSetStageTraceParent(t *testing.T) {
+	traceParent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	tests := []struct {
+		name           string
+		spaAnnotations map[string]string
+		roAnnotations  map[string]string
+		ExpectedResult map[string]string
+	}{{
+		name:           "Test the trace context of the stage copied",
+		spaAnnotations: map[string]string{"other": "value"},
+		roAnnotations:  map[string]string{tracing.StageTraceParentAnnotation: traceParent},
+		ExpectedResult: map[string]string{"other": "value", tracing.StageTraceParentAnnotation: traceParent},
+	}, {
+		name:           "Test the trace context of the stage removed",
+		spaAnnotations: map[string]string{tracing.StageTraceParentAnnotation: traceParent},
+		ExpectedResult: map[string]string{},
+	}, {
+		name: "Test the stage not traced",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spa := &v1.StagePodAutoscaler{ObjectMeta: metav1.ObjectMeta{Annotations: test.spaAnnotations}}
+			ro := &v1.RolloutOrchestrator{ObjectMeta: metav1.ObjectMeta{Annotations: test.roAnnotations}}
+			setStageTraceParent(spa, ro)
+			if !reflect.DeepEqual(spa.Annotations, test.ExpectedResult) {
+				t.Fatalf("Result of setStageTraceParent() = %v, want %v", spa.Annotations, test.ExpectedResult)
+			}
+		})
+	}
+}
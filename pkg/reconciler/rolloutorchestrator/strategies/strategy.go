@@ -18,17 +18,26 @@ package strategies
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/ptr"
 	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
 	clientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned"
 	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/tracing"
 	"knative.dev/serving/pkg/apis/serving"
 )
 
@@ -42,15 +51,24 @@ type Rollout struct {
 func (r *Rollout) Reconcile(ctx context.Context, ro *v1.RolloutOrchestrator, revScalingUp,
 	revScalingDown map[string]*v1.TargetRevision, enqueueAfter func(interface{}, time.Duration)) (bool, error) {
 	for index, step := range r.RolloutSteps {
-		if ro.IsStageInProgress() || index == 0 {
-			err := step.Execute(ctx, ro, revScalingUp, revScalingDown)
+		name := strings.TrimPrefix(fmt.Sprintf("%T", step), "*strategies.")
+		// The failed stage is still verified, so that it recovers, once it becomes ready.
+		unfinished := ro.IsStageInProgress() || ro.IsStageFailed()
+		if unfinished || index == 0 {
+			// The steps are traced as the children of the span of the stage.
+			stepCtx, span := tracing.Start(ctx, ro.Annotations, tracing.StageTraceParentAnnotation, name+".Execute")
+			err := step.Execute(stepCtx, ro, revScalingUp, revScalingDown)
+			endSpan(span, err)
 			if err != nil {
 				return false, err
 			}
 		}
 		// If spec.StageRevisionStatus is nil, check on if the number of replicas meets the conditions.
-		if ro.IsStageInProgress() {
-			ready, err := step.Verify(ctx, ro, revScalingUp, revScalingDown, enqueueAfter)
+		if unfinished {
+			stepCtx, span := tracing.Start(ctx, ro.Annotations, tracing.StageTraceParentAnnotation, name+".Verify")
+			ready, err := step.Verify(stepCtx, ro, revScalingUp, revScalingDown, enqueueAfter)
+			span.SetAttributes(attribute.Bool("ready", ready))
+			endSpan(span, err)
 			if err != nil {
 				return false, err
 			}
@@ -64,6 +82,15 @@ func (r *Rollout) Reconcile(ctx context.Context, ro *v1.RolloutOrchestrator, rev
 	return true, nil
 }
 
+// endSpan records the error of the step, if any, and ends the span of the step.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 // The ScaleUpStep struct is responsible for scaling up the pods for the new revision.
 type ScaleUpStep struct {
 	BaseScaleStep
@@ -72,9 +99,15 @@ type ScaleUpStep struct {
 // Execute for ScaleUpStep scales up the number of the pods for new revision by changing the minScale and maxScale for
 // the SPA.
 func (s *ScaleUpStep) Execute(ctx context.Context, ro *v1.RolloutOrchestrator, revScalingUp, _ map[string]*v1.TargetRevision) error {
+	// If the warm-up is enabled, the revision scaling up is pre-scaled to the load of its traffic share, until the
+	// boost is released after the stage is stable.
+	fn := UpdateSPAForRevUp
+	if warmUp := StageWarmUpReplicas(ro); warmUp > 0 {
+		fn = warmUpSPAForRevUp(warmUp)
+	}
 	// Create or update the StagePodAutoscaler for the revision scale up
 	for _, revUp := range revScalingUp {
-		if _, err := s.CreateOrUpdateSPARev(ctx, ro, revUp, true, UpdateSPAForRevUp); err != nil {
+		if _, err := s.CreateOrUpdateSPARev(ctx, ro, revUp, true, fn); err != nil {
 			return err
 		}
 	}
@@ -84,30 +117,59 @@ func (s *ScaleUpStep) Execute(ctx context.Context, ro *v1.RolloutOrchestrator, r
 // Verify for ScaleUpStep verified if the number of pods for the new revision has scaled up to the expected number.
 func (s *ScaleUpStep) Verify(ctx context.Context, ro *v1.RolloutOrchestrator, revScalingUp, revScalingDown map[string]*v1.TargetRevision,
 	_ func(interface{}, time.Duration)) (bool, error) {
+	var shortfall int32
+	var lastPodReadyTime *metav1.Time
 	for _, revUp := range revScalingUp {
 		spa, err := s.StagePodAutoscalerLister.StagePodAutoscalers(ro.Namespace).Get(revUp.RevisionName)
 		if err != nil {
 			return false, err
 		}
+		shortfall += ScaleUpShortfall(spa, revUp)
+		if t := spa.Status.LastPodReadyTime; t != nil && (lastPodReadyTime == nil || lastPodReadyTime.Before(t)) {
+			lastPodReadyTime = t
+		}
 
 		// spa.IsStageScaleInReady() returns true, as long as both DesireScale and ActualScale are available.
-		if !spa.IsStageScaleInReady() || !IsStageScaleUpReady(spa, revUp) {
+		if !spa.IsStageScaleInReady() || !IsStageScaleUpReady(spa, revUp, ro.Spec.StageScaleUpTolerance) ||
+			!IsStageWarmedUp(spa, revUp, StageWarmUpReplicas(ro)) {
 			// Create the stage pod autoscaler with the new maxScale set to
 			// maxScale defined in the revision traffic, because scale up phase is not over, we cannot
 			// scale down the old revision.
 			// Create or update the stagePodAutoscaler for the revision to be scaled down, even if the scaling up
 			// phase is not over.
 			for _, valDown := range revScalingDown {
-				if _, err = s.CreateOrUpdateSPARev(ctx, ro, valDown, false, UpdateSPAForRevDown); err != nil {
+				if _, err = s.CreateOrUpdateSPARev(ctx, ro, valDown, false, standbySPAForRevDown(ro)); err != nil {
 					return false, err
 				}
 			}
+			ro.Status.ScaleUpShortfall = shortfall
 			return false, nil
 		}
 	}
+	ro.Status.ScaleUpShortfall = shortfall
+	recordScaleUpDuration(ro, lastPodReadyTime)
+	if recorder := controller.GetEventRecorder(ctx); recorder != nil && shortfall > 0 && !ro.IsStageScaleUpReady() {
+		// The Event is only recorded, when the stage is scaled up with the shortfall for the first time.
+		recorder.Eventf(ro, corev1.EventTypeWarning, "StageScaledUpWithShortfall",
+			"The stage was scaled up with %d replicas missing, within the scale up tolerance %s.", shortfall,
+			ro.Spec.StageScaleUpTolerance.String())
+	}
 	return true, nil
 }
 
+// recordScaleUpDuration records how long the scaling up phase in progress took, from the time it started until the
+// last pod of the revisions scaling up became ready, as observed by their StagePodAutoscalers. Nothing is recorded,
+// if no pod became ready during the scaling up phase, e.g. the revisions already had enough replicas.
+func recordScaleUpDuration(ro *v1.RolloutOrchestrator, lastPodReadyTime *metav1.Time) {
+	cond := ro.Status.GetCondition(v1.SOStageScaleUpReady)
+	if cond == nil || !cond.IsUnknown() || cond.LastTransitionTime.Inner.IsZero() || lastPodReadyTime == nil {
+		return
+	}
+	if duration := lastPodReadyTime.Sub(cond.LastTransitionTime.Inner.Time); duration > 0 {
+		ro.Status.RecordScaleUpDuration(duration)
+	}
+}
+
 // ModifyStatus for ScaleUpStep modifies the status of the rolloutOrchestrator after the new revision has scaled up to
 // the expected number of pods.
 func (s *ScaleUpStep) ModifyStatus(ro *v1.RolloutOrchestrator, ready bool) {
@@ -115,6 +177,10 @@ func (s *ScaleUpStep) ModifyStatus(ro *v1.RolloutOrchestrator, ready bool) {
 		ro.Status.MarkStageRevisionScaleUpReady()
 	} else {
 		ro.Status.MarkStageRevisionScaleUpInProgress(v1.StageRevisionStart, v1.RolloutNewStage)
+		if ro.IsStageFailed() {
+			// The stage stays failed, until it becomes ready.
+			return
+		}
 		ro.Status.MarkStageRevisionInProgress(v1.StageRevisionStart, v1.RolloutNewStage)
 		ro.Status.MarkLastStageRevisionInComplete()
 	}
@@ -174,7 +240,7 @@ func (s *ScaleDownStep) Execute(ctx context.Context, ro *v1.RolloutOrchestrator,
 
 	if len(revScalingDown) != 0 {
 		for _, valDown := range revScalingDown {
-			_, err := s.CreateOrUpdateSPARev(ctx, ro, valDown, true, UpdateSPAForRevDown)
+			_, err := s.CreateOrUpdateSPARev(ctx, ro, valDown, true, standbySPAForRevDown(ro))
 			if err != nil {
 				return err
 			}
@@ -188,7 +254,7 @@ func (s *ScaleDownStep) Execute(ctx context.Context, ro *v1.RolloutOrchestrator,
 				// If this revision is not found in the map for revisions scaling up, we need to scale down.
 				revScaleDown := initRev.DeepCopy()
 				revScaleDown.Percent = nil
-				_, err := s.CreateOrUpdateSPARev(ctx, ro, revScaleDown, true, UpdateSPAForRevDown)
+				_, err := s.CreateOrUpdateSPARev(ctx, ro, revScaleDown, true, standbySPAForRevDown(ro))
 				if err != nil {
 					return err
 				}
@@ -203,8 +269,12 @@ func (s *ScaleDownStep) Execute(ctx context.Context, ro *v1.RolloutOrchestrator,
 func (s *ScaleDownStep) Verify(ctx context.Context, ro *v1.RolloutOrchestrator, revScalingUp, revScalingDown map[string]*v1.TargetRevision,
 	enqueueAfter func(interface{}, time.Duration)) (bool, error) {
 	if len(revScalingDown) != 0 {
+		// The PodDisruptionBudgets are only read once for all the revisions scaling down, when the first terminating
+		// pods are checked.
+		var budgets []disruptionBudget
+		budgetsLoaded := false
 		for _, valDown := range revScalingDown {
-			_, err := s.CreateOrUpdateSPARev(ctx, ro, valDown, true, UpdateSPAForRevDown)
+			_, err := s.CreateOrUpdateSPARev(ctx, ro, valDown, true, standbySPAForRevDown(ro))
 			if err != nil {
 				return false, err
 			}
@@ -216,11 +286,13 @@ func (s *ScaleDownStep) Verify(ctx context.Context, ro *v1.RolloutOrchestrator,
 
 			if spa.Status.ReplicasTerminating == nil ||
 				(spa.Status.ReplicasTerminating != nil && *spa.Status.ReplicasTerminating > 0) ||
-				!IsStageScaleDownReady(spa, valDown) {
-				// There are two circumstances that we need to force-delete the pods with terminating status.
+				!IsStageScaleDownReady(spa, standbyRevision(ro, valDown)) {
+				// With the default pod termination policy, there are two circumstances that we need to force-delete
+				// the pods with terminating status.
 				// 1. If the rollout mode is in resourceUtil mode and it is in the first stage of the rollout, force-delete the
 				// pods for the old revisions.
 				// 2. The pods stuck on terminating status have timed out.
+				// The policy can also disable the forced deletion, or force-delete the pods immediately.
 				for _, valUp := range revScalingUp {
 					spa, err := s.StagePodAutoscalerLister.StagePodAutoscalers(ro.Namespace).Get(valUp.RevisionName)
 					// The SPA for the revision scaling up must exist.
@@ -238,27 +310,18 @@ func (s *ScaleDownStep) Verify(ctx context.Context, ro *v1.RolloutOrchestrator,
 						return false, nil
 					}
 
-					for _, pod := range pods.Items {
-						if pod.DeletionTimestamp != nil {
-							now := metav1.NewTime(time.Now())
-							// If the time of DeletionTimestamp is before now or it is the first stage of the resourceUtil Mode,
-							// then it times out and delete the pods immediately.
-							if pod.DeletionTimestamp.Before(&now) || (ro.Spec.RolloutStrategy == ResourceUtilStrategy &&
-								*spa.Spec.StageMinScale == 0 && *spa.Spec.StageMaxScale == 0) {
-								err = s.Kubeclient.CoreV1().Pods(ro.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
-									GracePeriodSeconds: ptr.Int64(0),
-								})
-								if err != nil {
-									return false, err
-								}
-							} else {
-								// Check whether DeletionTimestamp for the pods have timed out or not.
-								// If not, re-enqueue ro in the reconcile loop.
-								if pod.GetDeletionGracePeriodSeconds() != nil {
-									enqueueAfter(ro, time.Duration(float64(*pod.GetDeletionGracePeriodSeconds())*float64(time.Second)))
-								}
-							}
+					// Delete the terminating pods, following the pod termination policy.
+					firstResourceUtilStage := ro.Spec.RolloutStrategy == ResourceUtilStrategy &&
+						*spa.Spec.StageMinScale == 0 && *spa.Spec.StageMaxScale == 0
+					if !budgetsLoaded {
+						if budgets, err = getDisruptionBudgets(s.PodDisruptionBudgetLister, ro.Namespace); err != nil {
+							return false, err
 						}
+						budgetsLoaded = true
+					}
+					if err = s.terminatePods(ctx, ro, pods.Items, budgets, firstResourceUtilStage,
+						enqueueAfter); err != nil {
+						return false, err
 					}
 					// There is only one revision scaling up at a time, so there is no need to go to the further iteration.
 					break
@@ -278,17 +341,22 @@ func (s *ScaleDownStep) ModifyStatus(ro *v1.RolloutOrchestrator, ready bool) {
 		ro.Status.MarkStageRevisionScaleDownReady()
 	} else {
 		ro.Status.MarkStageRevisionScaleDownInProgress(v1.StageRevisionStart, v1.RolloutNewStage)
+		if ro.IsStageFailed() {
+			return
+		}
 		ro.Status.MarkStageRevisionInProgress(v1.StageRevisionStart, v1.RolloutNewStage)
 		ro.Status.MarkLastStageRevisionInComplete()
 	}
 }
 
-func NewRolloutStrategy(client clientset.Interface, kubeclient kubernetes.Interface, stagePodAutoscalerLister listers.StagePodAutoscalerLister) map[string]*Rollout {
+func NewRolloutStrategy(client clientset.Interface, kubeclient kubernetes.Interface, stagePodAutoscalerLister listers.StagePodAutoscalerLister,
+	pdbLister policyv1listers.PodDisruptionBudgetLister) map[string]*Rollout {
 	rolloutMode := map[string]*Rollout{}
 	baseScaleStep := BaseScaleStep{
-		Client:                   client,
-		Kubeclient:               kubeclient,
-		StagePodAutoscalerLister: stagePodAutoscalerLister,
+		Client:                    client,
+		Kubeclient:                kubeclient,
+		StagePodAutoscalerLister:  stagePodAutoscalerLister,
+		PodDisruptionBudgetLister: pdbLister,
 	}
 	scaleUpStep := &ScaleUpStep{
 		BaseScaleStep: baseScaleStep,
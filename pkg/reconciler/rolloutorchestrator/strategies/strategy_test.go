@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestScaleUpStepVerifyShortfall(t *testing.T) {
+	tests := []struct {
+		name              string
+		actualScale       int32
+		tolerance         *intstr.IntOrString
+		scaledUp          bool
+		ExpectedResult    bool
+		ExpectedShortfall int32
+		ExpectedEvents    int
+	}{{
+		name:              "Test the shortfall without tolerance",
+		actualScale:       9,
+		ExpectedResult:    false,
+		ExpectedShortfall: 1,
+	}, {
+		name:              "Test the shortfall within the tolerance",
+		actualScale:       9,
+		tolerance:         intOrString("10%"),
+		ExpectedResult:    true,
+		ExpectedShortfall: 1,
+		ExpectedEvents:    1,
+	}, {
+		name:              "Test the shortfall within the tolerance after the stage scaled up",
+		actualScale:       9,
+		tolerance:         intOrString("10%"),
+		scaledUp:          true,
+		ExpectedResult:    true,
+		ExpectedShortfall: 1,
+	}, {
+		name:              "Test no shortfall",
+		actualScale:       10,
+		tolerance:         intOrString("10%"),
+		ExpectedResult:    true,
+		ExpectedShortfall: 0,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spa := &v1.StagePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "rev-0002", Namespace: "test-ns"},
+				Status: v1.StagePodAutoscalerStatus{
+					DesiredScale: ptr.Int32(10),
+					ActualScale:  ptr.Int32(test.actualScale),
+				},
+			}
+			spa.Status.MarkPodAutoscalerStageReady()
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			indexer.Add(spa)
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-name", Namespace: "test-ns"},
+				Spec: v1.RolloutOrchestratorSpec{
+					StageTarget: v1.StageTarget{StageScaleUpTolerance: test.tolerance},
+				},
+			}
+			ro.Status.InitializeConditions()
+			if test.scaledUp {
+				ro.Status.MarkStageRevisionScaleUpReady()
+			}
+			revUp := &v1.TargetRevision{
+				TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(20)},
+				Direction:      v1.DirectionUp,
+				TargetReplicas: ptr.Int32(10),
+				MinScale:       ptr.Int32(10),
+			}
+			step := &ScaleUpStep{BaseScaleStep: BaseScaleStep{
+				StagePodAutoscalerLister: listers.NewStagePodAutoscalerLister(indexer),
+			}}
+			recorder := record.NewFakeRecorder(10)
+			ctx := controller.WithEventRecorder(context.Background(), recorder)
+
+			r, err := step.Verify(ctx, ro, map[string]*v1.TargetRevision{revUp.RevisionName: revUp}, nil,
+				func(interface{}, time.Duration) {})
+			if err != nil {
+				t.Fatalf("Verify() returned error: %v", err)
+			}
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of Verify() = %v, want %v", r, test.ExpectedResult)
+			}
+			if ro.Status.ScaleUpShortfall != test.ExpectedShortfall {
+				t.Fatalf("Result of Verify() ScaleUpShortfall = %v, want %v", ro.Status.ScaleUpShortfall,
+					test.ExpectedShortfall)
+			}
+			if len(recorder.Events) != test.ExpectedEvents {
+				t.Fatalf("Result of Verify() Events = %v, want %v", len(recorder.Events), test.ExpectedEvents)
+			}
+		})
+	}
+}
+
+func TestRecordScaleUpDuration(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name             string
+		status           corev1.ConditionStatus
+		lastPodReadyTime *metav1.Time
+		ExpectedResult   *v1.ScaleUpObservation
+	}{{
+		name:             "Test the pod ready during the scaling up phase",
+		status:           corev1.ConditionUnknown,
+		lastPodReadyTime: &metav1.Time{Time: start.Add(30 * time.Second)},
+		ExpectedResult: &v1.ScaleUpObservation{
+			LastMilliseconds:    30000,
+			AverageMilliseconds: 30000,
+			MaxMilliseconds:     30000,
+			Samples:             1,
+			WindowMilliseconds:  []int64{30000},
+		},
+	}, {
+		name:             "Test the pod ready before the scaling up phase",
+		status:           corev1.ConditionUnknown,
+		lastPodReadyTime: &metav1.Time{Time: start.Add(-30 * time.Second)},
+	}, {
+		name:   "Test without any pod ready",
+		status: corev1.ConditionUnknown,
+	}, {
+		name:             "Test the scaling up phase already finished",
+		status:           corev1.ConditionTrue,
+		lastPodReadyTime: &metav1.Time{Time: start.Add(30 * time.Second)},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{}
+			ro.Status.Conditions = []apis.Condition{{
+				Type:               v1.SOStageScaleUpReady,
+				Status:             test.status,
+				LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(start)},
+			}}
+			recordScaleUpDuration(ro, test.lastPodReadyTime)
+			if !reflect.DeepEqual(ro.Status.ScaleUpObservation, test.ExpectedResult) {
+				t.Fatalf("Result of recordScaleUpDuration() = %v, want %v", ro.Status.ScaleUpObservation,
+					test.ExpectedResult)
+			}
+		})
+	}
+}
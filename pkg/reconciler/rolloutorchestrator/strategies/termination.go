@@ -0,0 +1,183 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+)
+
+var (
+	// PodTerminationGraceful is the pod termination policy, that never force-deletes the terminating pods.
+	PodTerminationGraceful = "graceful"
+	// PodTerminationForceAfterTimeout is the pod termination policy, that force-deletes the terminating pods when
+	// their grace period has expired, or in the first stage of the resourceUtil strategy.
+	PodTerminationForceAfterTimeout = "force-after-timeout"
+	// PodTerminationForceImmediately is the pod termination policy, that force-deletes the terminating pods as soon
+	// as they start terminating.
+	PodTerminationForceImmediately = "force-immediately"
+)
+
+// getPodTerminationMode returns the pod termination mode of the RolloutOrchestrator. It falls back to
+// force-after-timeout, if the mode is not set or not recognized.
+func getPodTerminationMode(ro *v1.RolloutOrchestrator) string {
+	if ro.Spec.PodTerminationPolicy == nil {
+		return PodTerminationForceAfterTimeout
+	}
+	mode := strings.ToLower(strings.TrimSpace(ro.Spec.PodTerminationPolicy.Mode))
+	if mode == PodTerminationGraceful || mode == PodTerminationForceImmediately {
+		return mode
+	}
+	return PodTerminationForceAfterTimeout
+}
+
+// shouldForceDelete decides whether the terminating pod needs to be force-deleted, based on the pod termination mode.
+// firstResourceUtilStage indicates whether it is the first stage of the resourceUtil strategy.
+func shouldForceDelete(mode string, pod *corev1.Pod, firstResourceUtilStage bool, now time.Time) bool {
+	switch mode {
+	case PodTerminationGraceful:
+		return false
+	case PodTerminationForceImmediately:
+		return true
+	default:
+		// If the time of DeletionTimestamp is before now or it is the first stage of the resourceUtil Mode,
+		// then it times out and delete the pods immediately.
+		return pod.DeletionTimestamp.Time.Before(now) || firstResourceUtilStage
+	}
+}
+
+// forcedDeletionAllowed checks whether one more pod can be force-deleted in the current stage, under the limit of
+// the pod termination policy.
+func forcedDeletionAllowed(ro *v1.RolloutOrchestrator) bool {
+	if ro.Spec.PodTerminationPolicy == nil || ro.Spec.PodTerminationPolicy.MaxForcedDeletionsPerStage <= 0 {
+		return true
+	}
+	return ro.Status.ForcedDeletions < ro.Spec.PodTerminationPolicy.MaxForcedDeletionsPerStage
+}
+
+// disruptionBudget is a PodDisruptionBudget with its selector parsed.
+type disruptionBudget struct {
+	name               string
+	selector           labels.Selector
+	disruptionsAllowed int32
+}
+
+// getDisruptionBudgets returns the PodDisruptionBudgets in the namespace, with their selectors parsed once for all
+// the pods checked against them.
+func getDisruptionBudgets(pdbLister policyv1listers.PodDisruptionBudgetLister,
+	namespace string) ([]disruptionBudget, error) {
+	if pdbLister == nil {
+		return nil, nil
+	}
+	pdbs, err := pdbLister.PodDisruptionBudgets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	budgets := make([]disruptionBudget, 0, len(pdbs))
+	for _, pdb := range pdbs {
+		// In policy/v1, a nil selector selects no pods, and an empty selector selects all the pods.
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of the PodDisruptionBudget %s: %w", pdb.Name, err)
+		}
+		budgets = append(budgets, disruptionBudget{name: pdb.Name, selector: selector,
+			disruptionsAllowed: pdb.Status.DisruptionsAllowed})
+	}
+	return budgets, nil
+}
+
+// disruptionBlocked checks whether any PodDisruptionBudget matching the pod disallows further disruptions.
+// It returns the name of the blocking PodDisruptionBudget.
+func disruptionBlocked(budgets []disruptionBudget, pod *corev1.Pod) string {
+	for _, budget := range budgets {
+		if budget.disruptionsAllowed <= 0 && budget.selector.Matches(labels.Set(pod.Labels)) {
+			return budget.name
+		}
+	}
+	return ""
+}
+
+// terminatePods deletes the terminating pods of the revision scaling down, following the pod termination policy
+// of the RolloutOrchestrator and the PodDisruptionBudgets in its namespace. An event is recorded for every deletion.
+// If the pods are left terminating, the RolloutOrchestrator is enqueued to check again.
+func (r *BaseScaleStep) terminatePods(ctx context.Context, ro *v1.RolloutOrchestrator, pods []corev1.Pod,
+	budgets []disruptionBudget, firstResourceUtilStage bool, enqueueAfter func(interface{}, time.Duration)) error {
+	recorder := controller.GetEventRecorder(ctx)
+	mode := getPodTerminationMode(ro)
+	now := time.Now()
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp == nil {
+			continue
+		}
+		if !shouldForceDelete(mode, pod, firstResourceUtilStage, now) {
+			// Check whether DeletionTimestamp for the pods have timed out or not.
+			// If not, re-enqueue ro in the reconcile loop.
+			if pod.GetDeletionGracePeriodSeconds() != nil {
+				enqueueAfter(ro, time.Duration(float64(*pod.GetDeletionGracePeriodSeconds())*float64(time.Second)))
+			}
+			continue
+		}
+
+		if !forcedDeletionAllowed(ro) {
+			if recorder != nil {
+				recorder.Eventf(ro, corev1.EventTypeWarning, "ForcedDeletionLimitReached",
+					"The pod %s was not force-deleted, because %d pods have been force-deleted in this stage.",
+					pod.Name, ro.Status.ForcedDeletions)
+			}
+			continue
+		}
+
+		if pdbName := disruptionBlocked(budgets, pod); pdbName != "" {
+			if recorder != nil {
+				recorder.Eventf(ro, corev1.EventTypeWarning, "ForcedDeletionBlocked",
+					"The pod %s was not force-deleted, because the PodDisruptionBudget %s allows no disruption.",
+					pod.Name, pdbName)
+			}
+			if pod.GetDeletionGracePeriodSeconds() != nil {
+				enqueueAfter(ro, time.Duration(float64(*pod.GetDeletionGracePeriodSeconds())*float64(time.Second)))
+			}
+			continue
+		}
+
+		err := r.Kubeclient.CoreV1().Pods(ro.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
+			GracePeriodSeconds: ptr.Int64(0),
+		})
+		if err != nil {
+			return err
+		}
+		ro.Status.ForcedDeletions++
+		if recorder != nil {
+			recorder.Eventf(ro, corev1.EventTypeNormal, "PodForceDeleted",
+				"The terminating pod %s was force-deleted with the %s pod termination policy.", pod.Name, mode)
+		}
+	}
+	return nil
+}
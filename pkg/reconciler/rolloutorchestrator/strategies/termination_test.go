@@ -0,0 +1,288 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func TestShouldForceDelete(t *testing.T) {
+	now := time.Now()
+	expired := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &metav1.Time{Time: now.Add(-time.Minute)},
+		},
+	}
+	notExpired := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &metav1.Time{Time: now.Add(time.Minute)},
+		},
+	}
+	tests := []struct {
+		name                   string
+		mode                   string
+		pod                    *corev1.Pod
+		firstResourceUtilStage bool
+		ExpectedResult         bool
+	}{{
+		name:           "Test the graceful mode with the expired pod",
+		mode:           PodTerminationGraceful,
+		pod:            expired,
+		ExpectedResult: false,
+	}, {
+		name:                   "Test the graceful mode in the first stage of resourceUtil",
+		mode:                   PodTerminationGraceful,
+		pod:                    notExpired,
+		firstResourceUtilStage: true,
+		ExpectedResult:         false,
+	}, {
+		name:           "Test the force-after-timeout mode with the expired pod",
+		mode:           PodTerminationForceAfterTimeout,
+		pod:            expired,
+		ExpectedResult: true,
+	}, {
+		name:           "Test the force-after-timeout mode with the pod not expired",
+		mode:           PodTerminationForceAfterTimeout,
+		pod:            notExpired,
+		ExpectedResult: false,
+	}, {
+		name:                   "Test the force-after-timeout mode in the first stage of resourceUtil",
+		mode:                   PodTerminationForceAfterTimeout,
+		pod:                    notExpired,
+		firstResourceUtilStage: true,
+		ExpectedResult:         true,
+	}, {
+		name:           "Test the force-immediately mode with the pod not expired",
+		mode:           PodTerminationForceImmediately,
+		pod:            notExpired,
+		ExpectedResult: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := shouldForceDelete(test.mode, test.pod, test.firstResourceUtilStage, now)
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of shouldForceDelete() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestTerminatePods(t *testing.T) {
+	expired := metav1.NewTime(time.Now().Add(-time.Minute))
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pod-1",
+			Namespace:         "test-ns",
+			Labels:            map[string]string{serving.RevisionLabelKey: "rev-001"},
+			DeletionTimestamp: &expired,
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pod-2",
+			Namespace:         "test-ns",
+			Labels:            map[string]string{serving.RevisionLabelKey: "rev-001"},
+			DeletionTimestamp: &expired,
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-3",
+			Namespace: "test-ns",
+			Labels:    map[string]string{serving.RevisionLabelKey: "rev-001"},
+		},
+	}}
+	blockingPDB := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pdb",
+			Namespace: "test-ns",
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{serving.RevisionLabelKey: "rev-001"},
+			},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{
+			DisruptionsAllowed: 0,
+		},
+	}
+	tests := []struct {
+		name                    string
+		policy                  *v1.PodTerminationPolicy
+		pdbs                    []*policyv1.PodDisruptionBudget
+		ExpectedForcedDeletions int32
+		ExpectedEvents          int
+	}{{
+		name:                    "Test with the default policy",
+		ExpectedForcedDeletions: 2,
+		ExpectedEvents:          2,
+	}, {
+		name: "Test with the graceful policy",
+		policy: &v1.PodTerminationPolicy{
+			Mode: PodTerminationGraceful,
+		},
+		ExpectedForcedDeletions: 0,
+		ExpectedEvents:          0,
+	}, {
+		name: "Test with the limit of the forced deletions",
+		policy: &v1.PodTerminationPolicy{
+			Mode:                       PodTerminationForceAfterTimeout,
+			MaxForcedDeletionsPerStage: 1,
+		},
+		ExpectedForcedDeletions: 1,
+		ExpectedEvents:          2,
+	}, {
+		name:                    "Test with the PodDisruptionBudget allowing no disruption",
+		pdbs:                    []*policyv1.PodDisruptionBudget{blockingPDB},
+		ExpectedForcedDeletions: 0,
+		ExpectedEvents:          2,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objs := make([]runtime.Object, 0, len(pods))
+			for i := range pods {
+				objs = append(objs, pods[i].DeepCopy())
+			}
+			kubeclient := fakekube.NewSimpleClientset(objs...)
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, pdb := range test.pdbs {
+				indexer.Add(pdb)
+			}
+			budgets, err := getDisruptionBudgets(policyv1listers.NewPodDisruptionBudgetLister(indexer), "test-ns")
+			if err != nil {
+				t.Fatalf("getDisruptionBudgets() returned error: %v", err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			ctx := controller.WithEventRecorder(context.Background(), recorder)
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "test-ns",
+				},
+				Spec: v1.RolloutOrchestratorSpec{
+					StageTarget: v1.StageTarget{
+						PodTerminationPolicy: test.policy,
+					},
+				},
+			}
+			step := &BaseScaleStep{
+				Kubeclient: kubeclient,
+			}
+			err = step.terminatePods(ctx, ro, pods, budgets, false, func(interface{}, time.Duration) {})
+			if err != nil {
+				t.Fatalf("terminatePods() returned error: %v", err)
+			}
+			if ro.Status.ForcedDeletions != test.ExpectedForcedDeletions {
+				t.Fatalf("Result of terminatePods() ForcedDeletions = %v, want %v", ro.Status.ForcedDeletions,
+					test.ExpectedForcedDeletions)
+			}
+			if len(recorder.Events) != test.ExpectedEvents {
+				t.Fatalf("Result of terminatePods() Events = %v, want %v", len(recorder.Events), test.ExpectedEvents)
+			}
+			remaining, _ := kubeclient.CoreV1().Pods("test-ns").List(ctx, metav1.ListOptions{})
+			if int32(len(remaining.Items)) != int32(len(pods))-test.ExpectedForcedDeletions {
+				t.Fatalf("Result of terminatePods() remaining pods = %v, want %v", len(remaining.Items),
+					int32(len(pods))-test.ExpectedForcedDeletions)
+			}
+		})
+	}
+}
+
+func TestGetDisruptionBudgets(t *testing.T) {
+	pdb := func(name, namespace string, selector *metav1.LabelSelector) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: selector,
+			},
+		}
+	}
+	revisionSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{serving.RevisionLabelKey: "rev-001"},
+	}
+	tests := []struct {
+		name           string
+		pdbs           []*policyv1.PodDisruptionBudget
+		expectedErr    bool
+		ExpectedResult []string
+	}{{
+		name:           "Test without PodDisruptionBudgets",
+		ExpectedResult: []string{},
+	}, {
+		name: "Test with the PodDisruptionBudgets in the namespace",
+		pdbs: []*policyv1.PodDisruptionBudget{
+			pdb("pdb-1", "test-ns", revisionSelector),
+			pdb("pdb-2", "other-ns", revisionSelector),
+		},
+		ExpectedResult: []string{"pdb-1"},
+	}, {
+		name: "Test with the PodDisruptionBudget without selector",
+		pdbs: []*policyv1.PodDisruptionBudget{
+			pdb("pdb-1", "test-ns", nil),
+		},
+		ExpectedResult: []string{},
+	}, {
+		name: "Test with the PodDisruptionBudget with an invalid selector",
+		pdbs: []*policyv1.PodDisruptionBudget{
+			pdb("pdb-1", "test-ns", &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      serving.RevisionLabelKey,
+					Operator: "invalid",
+				}},
+			}),
+		},
+		expectedErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, pdb := range test.pdbs {
+				indexer.Add(pdb)
+			}
+			budgets, err := getDisruptionBudgets(policyv1listers.NewPodDisruptionBudgetLister(indexer), "test-ns")
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("getDisruptionBudgets() returned error: %v, want error %v", err, test.expectedErr)
+			}
+			if test.expectedErr {
+				return
+			}
+			names := make([]string, 0, len(budgets))
+			for _, budget := range budgets {
+				names = append(names, budget.name)
+			}
+			if !reflect.DeepEqual(names, test.ExpectedResult) {
+				t.Fatalf("Result of getDisruptionBudgets() = %v, want %v", names, test.ExpectedResult)
+			}
+		})
+	}
+}
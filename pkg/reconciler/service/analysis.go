@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+)
+
+// analysisQueryTimeout is the timeout of each query of the rollout analysis, so that a slow metric provider does
+// not hold the reconciliation back.
+const analysisQueryTimeout = 5 * time.Second
+
+// stageAnalysis holds the Analyzer built from the analysis configuration in config-rolloutorchestrator. The
+// Analyzer, and the results it has cached, are replaced when the configuration changes.
+type stageAnalysis struct {
+	mu       sync.Mutex
+	config   *analysis.Config
+	analyzer *analysis.Analyzer
+}
+
+// get returns the Analyzer for the configuration. It returns nil, if no metric provider is configured.
+func (s *stageAnalysis) get(cfg *analysis.Config) (*analysis.Analyzer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cfg == nil {
+		s.config, s.analyzer = nil, nil
+		return nil, nil
+	}
+	if s.analyzer != nil && reflect.DeepEqual(s.config, cfg) {
+		return s.analyzer, nil
+	}
+	provider, err := analysis.NewProvider(cfg, &http.Client{Timeout: analysisQueryTimeout})
+	if err != nil {
+		return nil, err
+	}
+	s.config, s.analyzer = cfg, analysis.NewAnalyzer(provider, cfg.Queries)
+	return s.analyzer, nil
+}
+
+// forget drops the cached results of the service, once its rollout is over.
+func (s *stageAnalysis) forget(namespace, service string) {
+	s.mu.Lock()
+	analyzer := s.analyzer
+	s.mu.Unlock()
+	if analyzer != nil {
+		analyzer.Forget(namespace, service)
+	}
+}
+
+// analyzeStage queries the metrics configured for the rollout analysis for the revision scaling up in the stage,
+// which has just finished, and records their values in an Event on the owner. The failed queries are logged, and
+// do not hold the rollout back.
+func (c *Reconciler) analyzeStage(ctx context.Context, owner rolloutOwner, ro *v1.RolloutOrchestrator) {
+	if c.rolloutConfig == nil || c.rolloutConfig.Analysis == nil || len(ro.Spec.TargetRevisions) == 0 ||
+		len(ro.Spec.StageTargetRevisions) == 0 {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	analyzer, err := c.analysis.get(c.rolloutConfig.Analysis)
+	if err != nil {
+		logger.Warnw("Failed to create the metric provider for the rollout analysis", "error", err)
+		return
+	}
+	if analyzer == nil {
+		return
+	}
+
+	names := make([]string, 0, len(c.rolloutConfig.Analysis.Queries))
+	for name := range c.rolloutConfig.Analysis.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	target := analysis.Target{Namespace: ro.Namespace, Service: ro.Name,
+		Revision: ro.Spec.TargetRevisions[0].RevisionName}
+	stage := analysis.StageKey(ro)
+	results := make([]string, 0, len(names))
+	for _, name := range names {
+		value, err := analyzer.Query(ctx, name, target, stage)
+		if err != nil {
+			logger.Warnw("Failed to query the metric for the rollout analysis", "query", name,
+				"revision", target.Revision, "error", err)
+			continue
+		}
+		results = append(results, fmt.Sprintf("%s=%g", name, value))
+	}
+	if len(results) == 0 {
+		return
+	}
+	controller.GetEventRecorder(ctx).Eventf(owner, corev1.EventTypeNormal, "StageAnalyzed",
+		"The revision %q in the stage %s has the metrics %s.", target.Revision, stage, strings.Join(results, ", "))
+}
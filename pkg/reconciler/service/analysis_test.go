@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestAnalyzeStage(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "metrics.yaml")
+	if err := os.WriteFile(file, []byte("default/test-00002:\n  error-rate: 0.01\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		analysis      *analysis.Config
+		target        string
+		ExpectedEvent string
+	}{{
+		name:   "Test without the analysis",
+		target: "test-00002",
+	}, {
+		name: "Test the revision with the metrics",
+		analysis: &analysis.Config{Provider: analysis.StaticProviderName, StaticFile: file,
+			Queries: map[string]string{"error-rate": "errors", "latency": "latency"}},
+		target: "test-00002",
+		ExpectedEvent: "Normal StageAnalyzed The revision \"test-00002\" in the stage test-00001=80,test-00002=20 " +
+			"has the metrics error-rate=0.01.",
+	}, {
+		name: "Test the revision without any metric",
+		analysis: &analysis.Config{Provider: analysis.StaticProviderName, StaticFile: file,
+			Queries: map[string]string{"latency": "latency"}},
+		target: "test-00002",
+	}, {
+		name: "Test the metric provider failing to start",
+		analysis: &analysis.Config{Provider: analysis.StaticProviderName, StaticFile: file + ".missing",
+			Queries: map[string]string{"error-rate": "errors"}},
+		target: "test-00002",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: v1.RolloutOrchestratorSpec{
+					TargetRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: test.target, Percent: ptr.Int64(100)},
+					}},
+					StageTarget: v1.StageTarget{
+						StageTargetRevisions: []v1.TargetRevision{{
+							TrafficTarget: servingv1.TrafficTarget{RevisionName: "test-00001", Percent: ptr.Int64(80)},
+						}, {
+							TrafficTarget: servingv1.TrafficTarget{RevisionName: "test-00002", Percent: ptr.Int64(20)},
+						}},
+					},
+				},
+			}
+			recorder := record.NewFakeRecorder(10)
+			c := &Reconciler{rolloutConfig: &RolloutConfig{Analysis: test.analysis}}
+			c.analyzeStage(controller.WithEventRecorder(context.Background(), recorder), ro, ro)
+
+			event := ""
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if event != test.ExpectedEvent {
+				t.Fatalf("Result of analyzeStage() event = %q, want %q", event, test.ExpectedEvent)
+			}
+		})
+	}
+}
+
+func TestStageAnalysisGet(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "metrics.yaml")
+	if err := os.WriteFile(file, []byte("default/test-00002:\n  error-rate: 0.01\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &analysis.Config{Provider: analysis.StaticProviderName, StaticFile: file,
+		Queries: map[string]string{"error-rate": "errors"}}
+	s := &stageAnalysis{}
+
+	analyzer, err := s.get(cfg)
+	if err != nil || analyzer == nil {
+		t.Fatalf("Result of get() = %v, %v, want an Analyzer", analyzer, err)
+	}
+	same := *cfg
+	if r, err := s.get(&same); err != nil || r != analyzer {
+		t.Fatalf("Result of get() with the same configuration = %v, %v, want %v", r, err, analyzer)
+	}
+	changed := same
+	changed.Queries = map[string]string{"latency": "latency"}
+	if r, err := s.get(&changed); err != nil || r == analyzer {
+		t.Fatalf("Result of get() with the changed configuration = %v, %v, want a new Analyzer", r, err)
+	}
+	if r, err := s.get(nil); err != nil || r != nil {
+		t.Fatalf("Result of get() without the configuration = %v, %v, want nil", r, err)
+	}
+}
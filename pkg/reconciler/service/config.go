@@ -20,9 +20,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	cm "knative.dev/pkg/configmap"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator/strategies"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
 	"knative.dev/serving/pkg/apis/serving"
@@ -34,12 +39,90 @@ type RolloutConfig struct {
 	// to accomplish the rolling upgrade.
 	OverConsumptionRatio int
 
+	// OverConsumptionBasis determines whether OverConsumptionRatio applies to the replicas or to the cpu and memory
+	// requested by the replicas. It is either replicas or resources.
+	OverConsumptionBasis string
+
 	// ProgressiveRolloutEnabled is boolean value that determines whether progressive rollout feature is enabled or not.
 	ProgressiveRolloutEnabled bool
 
+	// DeleteRolloutOnDisable determines whether the RolloutOrchestrator and the StagePodAutoscalers of the service
+	// are deleted, when the progressive rollout is disabled.
+	DeleteRolloutOnDisable bool
+
 	// StageRolloutTimeoutMinutes contains the timeout value of minutes to use for each stage to accomplish in the rollout process.
 	StageRolloutTimeoutMinutes int
 
+	// StageRolloutTimeoutFloorSeconds contains the lower bound in seconds of the stage timeout, calculated from the
+	// observed durations of scaling up.
+	StageRolloutTimeoutFloorSeconds int
+
+	// StageRolloutTimeoutCeilingSeconds contains the upper bound in seconds of the stage timeout, calculated from the
+	// observed durations of scaling up. If it is 0, StageRolloutTimeoutMinutes is used as the upper bound.
+	StageRolloutTimeoutCeilingSeconds int
+
+	// StageAccelerationEnabled determines whether the size of the stages grows geometrically after each stage
+	// finished well before its deadline.
+	StageAccelerationEnabled bool
+
+	// StageMaxSurgeRatio sets the upper bound of the percentage of the traffic shifted in one stage, when the stages
+	// are accelerated.
+	StageMaxSurgeRatio int
+
+	// PodTerminationPolicy determines how the terminating pods of the revisions scaling down are deleted. It is
+	// graceful, force-after-timeout or force-immediately.
+	PodTerminationPolicy string
+
+	// StageFastAdvanceEnabled determines whether the next stage starts as soon as the current stage is ready.
+	// If it is false, the next stage starts at the deadline of the current stage.
+	StageFastAdvanceEnabled bool
+
+	// StageMinSoakSeconds contains the minimum duration in seconds, for which a ready stage is kept before
+	// the next stage starts.
+	StageMinSoakSeconds int
+
+	// StageWarmUpEnabled determines whether the revision scaling up is pre-scaled to the load of its traffic share,
+	// calculated from the desired scale of the revisions scaling down, before the traffic moves.
+	StageWarmUpEnabled bool
+
+	// StageWarmUpStabilizationSeconds contains the duration in seconds, for which the pre-scaled replicas are kept
+	// after the stage is ready.
+	StageWarmUpStabilizationSeconds int
+
+	// StageScaleUpTolerance is the number, e.g. 2, or the percentage, e.g. 10%, of the target replicas of the
+	// revision scaling up, that may still be missing, when the stage is considered scaled up. Empty means all the
+	// target replicas are required.
+	StageScaleUpTolerance string
+
+	// MaxForcedDeletionsPerStage sets the upper bound for the number of the pods force-deleted in one stage.
+	// 0 means no limit.
+	MaxForcedDeletionsPerStage int
+
+	// TrafficEditPolicy determines how the traffic of the service edited in the middle of a rollout is handled.
+	// It is adopt, restart or reject.
+	TrafficEditPolicy string
+
+	// SupersedingPolicy determines how the revision created in the middle of a rollout is rolled out. It is
+	// supersede, queue or debounce.
+	SupersedingPolicy string
+
+	// SupersedingDebounceSeconds contains the duration in seconds, for which no further revision has to be created,
+	// before the debounce policy supersedes the rollout in progress.
+	SupersedingDebounceSeconds int
+
+	// CandidateTag is the tag of the revision scaling up, and StableTag is the tag of the revision scaling down,
+	// added to the traffic for the duration of a rollout. No tag is added, if it is empty.
+	CandidateTag string
+	StableTag    string
+
+	// PostRolloutStandbyReplicas sets the number of replicas each initial revision keeps on standby, once its traffic
+	// has moved away, so that the rollout is able to be reverted without a cold start. 0 disables the standby.
+	PostRolloutStandbyReplicas int
+
+	// PostRolloutStandbySeconds contains the bake period in seconds, for which the initial revisions are kept on
+	// standby after the last stage is complete.
+	PostRolloutStandbySeconds int
+
 	// RolloutDuration contains the minimal duration in seconds over which the Configuration traffic targets are
 	// rolled out to the newest revision
 	RolloutDuration string
@@ -47,28 +130,84 @@ type RolloutConfig struct {
 	// ProgressiveRolloutStrategy determines the mode to roll out the new revision progressively. It is either availability
 	// or resourceUtil.
 	ProgressiveRolloutStrategy string
+
+	// Analysis contains the metric provider and the queries for the rollout analysis. It is nil, if no metric
+	// provider is configured.
+	Analysis *analysis.Config
 }
 
-// NewConfigFromConfigMapFunc reads the configurations: OverConsumptionRatio, ProgressiveRolloutEnabled and
-// StageRolloutTimeoutMinutes available in the configmap.
+// NewConfigFromConfigMapFunc reads the configurations: OverConsumptionRatio, ProgressiveRolloutEnabled,
+// StageRolloutTimeoutMinutes and the bounds of the stage timeout available in the configmap.
 func NewConfigFromConfigMapFunc(configMap *corev1.ConfigMap, configMapN *corev1.ConfigMap) (*RolloutConfig, error) {
 	rolloutConfig := &RolloutConfig{
-		OverConsumptionRatio:       resources.OverSubRatio,
-		ProgressiveRolloutEnabled:  true,
-		StageRolloutTimeoutMinutes: resources.DefaultStageRolloutTimeoutMinutes,
-		RolloutDuration:            "0",
-		ProgressiveRolloutStrategy: strategies.AvailabilityStrategy,
+		OverConsumptionRatio:            resources.OverSubRatio,
+		OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+		ProgressiveRolloutEnabled:       true,
+		StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+		RolloutDuration:                 "0",
+		ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+		StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+		StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+		PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+		StageFastAdvanceEnabled:         true,
+		StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+		TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+		PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+		SupersedingPolicy:               resources.SupersedingPolicySupersede,
+		SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
 	}
 
 	if configMap != nil && len(configMap.Data) != 0 {
 		if err := cm.Parse(configMap.Data,
 			cm.AsInt("over-consumption-ratio", &rolloutConfig.OverConsumptionRatio),
+			cm.AsString("over-consumption-basis", &rolloutConfig.OverConsumptionBasis),
 			cm.AsBool("progressive-rollout-enabled", &rolloutConfig.ProgressiveRolloutEnabled),
+			cm.AsBool("delete-rollout-on-disable", &rolloutConfig.DeleteRolloutOnDisable),
 			cm.AsInt("stage-rollout-timeout-minutes", &rolloutConfig.StageRolloutTimeoutMinutes),
+			cm.AsInt("stage-rollout-timeout-floor-seconds", &rolloutConfig.StageRolloutTimeoutFloorSeconds),
+			cm.AsInt("stage-rollout-timeout-ceiling-seconds", &rolloutConfig.StageRolloutTimeoutCeilingSeconds),
+			cm.AsBool("stage-acceleration-enabled", &rolloutConfig.StageAccelerationEnabled),
+			cm.AsInt("stage-max-surge-ratio", &rolloutConfig.StageMaxSurgeRatio),
+			cm.AsString("pod-termination-policy", &rolloutConfig.PodTerminationPolicy),
+			cm.AsBool("stage-fast-advance-enabled", &rolloutConfig.StageFastAdvanceEnabled),
+			cm.AsInt("stage-min-soak-seconds", &rolloutConfig.StageMinSoakSeconds),
+			cm.AsBool("stage-warm-up-enabled", &rolloutConfig.StageWarmUpEnabled),
+			cm.AsInt("stage-warm-up-stabilization-seconds", &rolloutConfig.StageWarmUpStabilizationSeconds),
+			cm.AsString("stage-scale-up-tolerance", &rolloutConfig.StageScaleUpTolerance),
+			cm.AsInt("max-forced-deletions-per-stage", &rolloutConfig.MaxForcedDeletionsPerStage),
+			cm.AsString("traffic-edit-policy", &rolloutConfig.TrafficEditPolicy),
+			cm.AsString("superseding-policy", &rolloutConfig.SupersedingPolicy),
+			cm.AsInt("superseding-debounce-seconds", &rolloutConfig.SupersedingDebounceSeconds),
 			cm.AsString("progressive-rollout-strategy", &rolloutConfig.ProgressiveRolloutStrategy),
+			cm.AsString("rollout-candidate-tag", &rolloutConfig.CandidateTag),
+			cm.AsString("rollout-stable-tag", &rolloutConfig.StableTag),
+			cm.AsInt("post-rollout-standby-replicas", &rolloutConfig.PostRolloutStandbyReplicas),
+			cm.AsInt("post-rollout-standby-seconds", &rolloutConfig.PostRolloutStandbySeconds),
 		); err != nil {
 			return nil, fmt.Errorf("failed to parse data: %w", err)
 		}
+		for _, tag := range []string{rolloutConfig.CandidateTag, rolloutConfig.StableTag} {
+			if !isValidTag(tag) {
+				return nil, fmt.Errorf("failed to parse data: the tag %q is not a valid DNS-1035 label", tag)
+			}
+		}
+		if !isValidTolerance(rolloutConfig.StageScaleUpTolerance) {
+			return nil, fmt.Errorf("failed to parse data: the tolerance %q is neither a number nor a percentage",
+				rolloutConfig.StageScaleUpTolerance)
+		}
+		if !isValidTrafficEditPolicy(rolloutConfig.TrafficEditPolicy) {
+			return nil, fmt.Errorf("failed to parse data: the traffic edit policy %q is not one of %s, %s and %s",
+				rolloutConfig.TrafficEditPolicy, resources.TrafficEditPolicyAdopt, resources.TrafficEditPolicyRestart,
+				resources.TrafficEditPolicyReject)
+		}
+
+		analysisConfig, err := analysis.NewConfigFromMap(configMap.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the analysis data: %w", err)
+		}
+		if analysisConfig.Provider != "" {
+			rolloutConfig.Analysis = analysisConfig
+		}
 	}
 
 	if configMapN != nil && len(configMapN.Data) != 0 {
@@ -82,8 +221,8 @@ func NewConfigFromConfigMapFunc(configMap *corev1.ConfigMap, configMapN *corev1.
 	return rolloutConfig, nil
 }
 
-// LoadConfigFromService reads the configurations: OverConsumptionRatio and
-// StageRolloutTimeoutMinutes available in the annotation of the knative service.
+// LoadConfigFromService reads the configurations: OverConsumptionRatio, StageRolloutTimeoutMinutes and
+// the bounds of the stage timeout available in the annotation of the knative service.
 func LoadConfigFromService(annotation map[string]string, serviceAnnotation map[string]string, rolloutConfig *RolloutConfig) {
 	if val, ok := annotation[resources.OverConsumptionRatioKey]; ok {
 		ratio, err := strconv.Atoi(val)
@@ -92,6 +231,10 @@ func LoadConfigFromService(annotation map[string]string, serviceAnnotation map[s
 		}
 	}
 
+	if basis, ok := annotation[resources.OverConsumptionBasis]; ok {
+		rolloutConfig.OverConsumptionBasis = basis
+	}
+
 	if val, ok := annotation[resources.ProgressiveRolloutEnabled]; ok {
 		progressiveRolloutEnabled, err := strconv.ParseBool(val)
 		if err == nil {
@@ -99,6 +242,13 @@ func LoadConfigFromService(annotation map[string]string, serviceAnnotation map[s
 		}
 	}
 
+	if val, ok := annotation[resources.DeleteRolloutOnDisable]; ok {
+		deleteRolloutOnDisable, err := strconv.ParseBool(val)
+		if err == nil {
+			rolloutConfig.DeleteRolloutOnDisable = deleteRolloutOnDisable
+		}
+	}
+
 	if val, ok := annotation[resources.StageRolloutTimeoutMinutes]; ok {
 		timeout, err := strconv.Atoi(val)
 		if err == nil {
@@ -106,6 +256,114 @@ func LoadConfigFromService(annotation map[string]string, serviceAnnotation map[s
 		}
 	}
 
+	if val, ok := annotation[resources.StageRolloutTimeoutFloorSeconds]; ok {
+		floor, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.StageRolloutTimeoutFloorSeconds = floor
+		}
+	}
+
+	if val, ok := annotation[resources.StageRolloutTimeoutCeilingSeconds]; ok {
+		ceiling, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.StageRolloutTimeoutCeilingSeconds = ceiling
+		}
+	}
+
+	if val, ok := annotation[resources.StageAccelerationEnabled]; ok {
+		stageAccelerationEnabled, err := strconv.ParseBool(val)
+		if err == nil {
+			rolloutConfig.StageAccelerationEnabled = stageAccelerationEnabled
+		}
+	}
+
+	if val, ok := annotation[resources.StageMaxSurgeRatio]; ok {
+		ratio, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.StageMaxSurgeRatio = ratio
+		}
+	}
+
+	if policy, ok := annotation[resources.PodTerminationPolicy]; ok {
+		rolloutConfig.PodTerminationPolicy = policy
+	}
+
+	if val, ok := annotation[resources.StageFastAdvanceEnabled]; ok {
+		stageFastAdvanceEnabled, err := strconv.ParseBool(val)
+		if err == nil {
+			rolloutConfig.StageFastAdvanceEnabled = stageFastAdvanceEnabled
+		}
+	}
+
+	if val, ok := annotation[resources.StageMinSoakSeconds]; ok {
+		soak, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.StageMinSoakSeconds = soak
+		}
+	}
+
+	if val, ok := annotation[resources.StageWarmUpEnabled]; ok {
+		stageWarmUpEnabled, err := strconv.ParseBool(val)
+		if err == nil {
+			rolloutConfig.StageWarmUpEnabled = stageWarmUpEnabled
+		}
+	}
+
+	if val, ok := annotation[resources.StageWarmUpStabilizationSeconds]; ok {
+		stabilization, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.StageWarmUpStabilizationSeconds = stabilization
+		}
+	}
+
+	if tolerance, ok := annotation[resources.StageScaleUpTolerance]; ok && isValidTolerance(tolerance) {
+		rolloutConfig.StageScaleUpTolerance = tolerance
+	}
+
+	if val, ok := annotation[resources.MaxForcedDeletionsPerStage]; ok {
+		maxDeletions, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.MaxForcedDeletionsPerStage = maxDeletions
+		}
+	}
+
+	if policy, ok := annotation[resources.TrafficEditPolicy]; ok && isValidTrafficEditPolicy(policy) {
+		rolloutConfig.TrafficEditPolicy = policy
+	}
+
+	if policy, ok := annotation[resources.SupersedingPolicy]; ok {
+		rolloutConfig.SupersedingPolicy = policy
+	}
+
+	if val, ok := annotation[resources.SupersedingDebounceSeconds]; ok {
+		debounce, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.SupersedingDebounceSeconds = debounce
+		}
+	}
+
+	if tag, ok := annotation[resources.CandidateTag]; ok && isValidTag(tag) {
+		rolloutConfig.CandidateTag = tag
+	}
+
+	if tag, ok := annotation[resources.StableTag]; ok && isValidTag(tag) {
+		rolloutConfig.StableTag = tag
+	}
+
+	if val, ok := annotation[resources.PostRolloutStandbyReplicas]; ok {
+		replicas, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.PostRolloutStandbyReplicas = replicas
+		}
+	}
+
+	if val, ok := annotation[resources.PostRolloutStandbySeconds]; ok {
+		bake, err := strconv.Atoi(val)
+		if err == nil {
+			rolloutConfig.PostRolloutStandbySeconds = bake
+		}
+	}
+
 	if mode, ok := annotation[resources.ProgressiveRolloutStrategy]; ok {
 		// As long as ResourceUtil is defined in the service or in the configMap, we will use it as the strategy
 		// to roll out the services.
@@ -120,3 +378,93 @@ func LoadConfigFromService(annotation map[string]string, serviceAnnotation map[s
 		rolloutConfig.RolloutDuration = val
 	}
 }
+
+// isValidTag returns true, if the tag is empty, or can be the tag of a traffic target.
+func isValidTag(tag string) bool {
+	return tag == "" || len(validation.IsDNS1035Label(tag)) == 0
+}
+
+// isValidTolerance returns true, if the tolerance is empty, or a non-negative number or percentage.
+func isValidTolerance(tolerance string) bool {
+	if tolerance == "" {
+		return true
+	}
+	value := intstr.Parse(tolerance)
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&value, 100, false)
+	return err == nil && scaled >= 0
+}
+
+// isValidTrafficEditPolicy returns true, if the policy is one of the TrafficEditPolicies, regardless of the case.
+func isValidTrafficEditPolicy(policy string) bool {
+	switch strings.ToLower(policy) {
+	case resources.TrafficEditPolicyAdopt, resources.TrafficEditPolicyRestart, resources.TrafficEditPolicyReject:
+		return true
+	}
+	return false
+}
+
+// GetStageScaleUpTolerance returns the StageScaleUpTolerance set in the RolloutOrchestrator. It is nil, if all the
+// target replicas are required.
+func (rolloutConfig *RolloutConfig) GetStageScaleUpTolerance() *intstr.IntOrString {
+	if !isValidTolerance(rolloutConfig.StageScaleUpTolerance) {
+		return nil
+	}
+	tolerance := intstr.Parse(rolloutConfig.StageScaleUpTolerance)
+	if scaled, _ := intstr.GetScaledValueFromIntOrPercent(&tolerance, 100, false); scaled == 0 {
+		return nil
+	}
+	return &tolerance
+}
+
+// GetStageRolloutTimeout returns the timeout for the current stage. If the durations of scaling up have been
+// observed for the RolloutOrchestrator, the timeout is calculated from them within the floor and the ceiling.
+// Otherwise, StageRolloutTimeoutMinutes is used.
+func (rolloutConfig *RolloutConfig) GetStageRolloutTimeout(ro *v1.RolloutOrchestrator) time.Duration {
+	timeout := time.Duration(float64(time.Minute) * float64(rolloutConfig.StageRolloutTimeoutMinutes))
+	if ro == nil || ro.Status.ScaleUpObservation == nil || ro.Status.ScaleUpObservation.Samples == 0 {
+		return timeout
+	}
+
+	observation := ro.Status.ScaleUpObservation
+	observed := max(observation.AverageMilliseconds*int64(resources.StageRolloutTimeoutObservedFactor),
+		observation.MaxMilliseconds)
+	adaptive := time.Duration(observed) * time.Millisecond
+
+	ceiling := timeout
+	if rolloutConfig.StageRolloutTimeoutCeilingSeconds > 0 {
+		ceiling = time.Duration(rolloutConfig.StageRolloutTimeoutCeilingSeconds) * time.Second
+	}
+	floor := time.Duration(rolloutConfig.StageRolloutTimeoutFloorSeconds) * time.Second
+	return max(min(adaptive, ceiling), floor)
+}
+
+// GetStageHold returns how long the ready stage of the RolloutOrchestrator is kept before the next stage starts.
+// The stage is kept for StageMinSoakSeconds since it became ready, and until its deadline, if the fast advance
+// is disabled.
+func (rolloutConfig *RolloutConfig) GetStageHold(ro *v1.RolloutOrchestrator, now time.Time) time.Duration {
+	cond := ro.Status.GetCondition(v1.SOStageReady)
+	if cond == nil || !cond.IsTrue() || ro.Spec.StageTargetRevisions == nil {
+		return 0
+	}
+	var hold time.Duration
+	if !cond.LastTransitionTime.Inner.IsZero() {
+		soakEnd := cond.LastTransitionTime.Inner.Add(time.Duration(rolloutConfig.StageMinSoakSeconds) * time.Second)
+		hold = soakEnd.Sub(now)
+	}
+	if !rolloutConfig.StageFastAdvanceEnabled && !ro.Spec.TargetFinishTime.Inner.IsZero() {
+		hold = max(hold, ro.Spec.TargetFinishTime.Inner.Sub(now))
+	}
+	return max(hold, 0)
+}
+
+// GetStageRatio returns the percentage of the traffic to shift in the next stage. If the stages are accelerated,
+// OverConsumptionRatio is multiplied by the StageDeltaMultiplier of the RolloutOrchestrator, bounded by
+// StageMaxSurgeRatio.
+func (rolloutConfig *RolloutConfig) GetStageRatio(ro *v1.RolloutOrchestrator) int {
+	ratio := rolloutConfig.OverConsumptionRatio
+	if !rolloutConfig.StageAccelerationEnabled || ro == nil || ro.Status.StageDeltaMultiplier <= 1 ||
+		ratio >= rolloutConfig.StageMaxSurgeRatio {
+		return ratio
+	}
+	return min(ratio*int(ro.Status.StageDeltaMultiplier), rolloutConfig.StageMaxSurgeRatio)
+}
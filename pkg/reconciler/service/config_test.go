@@ -20,10 +20,18 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving-progressive-rollout/pkg/analysis"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator/strategies"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 func TestNewConfigFromConfigMapFunc(t *testing.T) {
@@ -36,11 +44,21 @@ func TestNewConfigFromConfigMapFunc(t *testing.T) {
 		name:  "Test the RolloutConfig with empty ConfigMap as input",
 		input: nil,
 		ExpectedResult: &RolloutConfig{
-			OverConsumptionRatio:       resources.OverSubRatio,
-			ProgressiveRolloutEnabled:  true,
-			StageRolloutTimeoutMinutes: resources.DefaultStageRolloutTimeoutMinutes,
-			RolloutDuration:            "0",
-			ProgressiveRolloutStrategy: strategies.AvailabilityStrategy,
+			OverConsumptionRatio:            resources.OverSubRatio,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       true,
+			StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
 		},
 		ExpectedError: nil,
 	}, {
@@ -49,11 +67,21 @@ func TestNewConfigFromConfigMapFunc(t *testing.T) {
 			Data: nil,
 		},
 		ExpectedResult: &RolloutConfig{
-			OverConsumptionRatio:       resources.OverSubRatio,
-			ProgressiveRolloutEnabled:  true,
-			StageRolloutTimeoutMinutes: resources.DefaultStageRolloutTimeoutMinutes,
-			RolloutDuration:            "0",
-			ProgressiveRolloutStrategy: strategies.AvailabilityStrategy,
+			OverConsumptionRatio:            resources.OverSubRatio,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       true,
+			StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
 		},
 		ExpectedError: nil,
 	}, {
@@ -67,11 +95,21 @@ func TestNewConfigFromConfigMapFunc(t *testing.T) {
 			},
 		},
 		ExpectedResult: &RolloutConfig{
-			OverConsumptionRatio:       15,
-			ProgressiveRolloutEnabled:  false,
-			StageRolloutTimeoutMinutes: 4,
-			RolloutDuration:            "0",
-			ProgressiveRolloutStrategy: strategies.ResourceUtilStrategy,
+			OverConsumptionRatio:            15,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       false,
+			StageRolloutTimeoutMinutes:      4,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.ResourceUtilStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
 		},
 		ExpectedError: nil,
 	}, {
@@ -84,11 +122,21 @@ func TestNewConfigFromConfigMapFunc(t *testing.T) {
 			},
 		},
 		ExpectedResult: &RolloutConfig{
-			OverConsumptionRatio:       15,
-			ProgressiveRolloutEnabled:  false,
-			StageRolloutTimeoutMinutes: 4,
-			RolloutDuration:            "0",
-			ProgressiveRolloutStrategy: strategies.AvailabilityStrategy,
+			OverConsumptionRatio:            15,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       false,
+			StageRolloutTimeoutMinutes:      4,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
 		},
 		ExpectedError: nil,
 	}, {
@@ -113,6 +161,185 @@ func TestNewConfigFromConfigMapFunc(t *testing.T) {
 		},
 		ExpectedResult: nil,
 		ExpectedError:  fmt.Errorf("failed to parse data: %s", "failed to parse \"progressive-rollout-enabled\": strconv.ParseBool: parsing \"invalid-false\": invalid syntax"),
+	}, {
+		name: "Test the RolloutConfig with the analysis provider",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"analysis-provider":           "prometheus",
+				"analysis-prometheus-address": "http://prometheus:9090",
+				"analysis-query.error-rate":   "sum(errors)",
+			},
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:            resources.OverSubRatio,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       true,
+			StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
+			Analysis: &analysis.Config{
+				Provider:            analysis.PrometheusProviderName,
+				PrometheusAddress:   "http://prometheus:9090",
+				AutoscalerWindow:    analysis.DefaultAutoscalerWindow,
+				AutoscalerTokenFile: analysis.DefaultAutoscalerTokenFile,
+				Queries:             map[string]string{"error-rate": "sum(errors)"},
+			},
+		},
+		ExpectedError: nil,
+	}, {
+		name: "Test the RolloutConfig with the invalid analysis provider",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"analysis-provider": "unknown",
+			},
+		},
+		ExpectedResult: nil,
+		ExpectedError:  fmt.Errorf("failed to parse the analysis data: %s", "unknown analysis-provider \"unknown\""),
+	}, {
+		name: "Test the RolloutConfig with the rollout tags",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"rollout-candidate-tag": "candidate",
+				"rollout-stable-tag":    "stable",
+			},
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:            resources.OverSubRatio,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       true,
+			StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
+			CandidateTag:                    "candidate",
+			StableTag:                       "stable",
+		},
+		ExpectedError: nil,
+	}, {
+		name: "Test the RolloutConfig with an invalid rollout tag",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"rollout-candidate-tag": "Candidate_1",
+			},
+		},
+		ExpectedResult: nil,
+		ExpectedError:  fmt.Errorf("failed to parse data: the tag %q is not a valid DNS-1035 label", "Candidate_1"),
+	}, {
+		name: "Test the RolloutConfig with the post-rollout standby",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"post-rollout-standby-replicas": "2",
+				"post-rollout-standby-seconds":  "300",
+			},
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:            resources.OverSubRatio,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       true,
+			StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbyReplicas:      2,
+			PostRolloutStandbySeconds:       300,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
+		},
+		ExpectedError: nil,
+	}, {
+		name: "Test the RolloutConfig with the superseding policy",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"superseding-policy":           "debounce",
+				"superseding-debounce-seconds": "60",
+			},
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:            resources.OverSubRatio,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       true,
+			StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicyDebounce,
+			SupersedingDebounceSeconds:      60,
+		},
+		ExpectedError: nil,
+	}, {
+		name: "Test the RolloutConfig with the stage scale up tolerance",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"stage-scale-up-tolerance": "10%",
+			},
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:            resources.OverSubRatio,
+			OverConsumptionBasis:            resources.OverConsumptionBasisReplicas,
+			ProgressiveRolloutEnabled:       true,
+			StageRolloutTimeoutMinutes:      resources.DefaultStageRolloutTimeoutMinutes,
+			RolloutDuration:                 "0",
+			ProgressiveRolloutStrategy:      strategies.AvailabilityStrategy,
+			StageRolloutTimeoutFloorSeconds: resources.DefaultStageRolloutTimeoutFloorSeconds,
+			StageMaxSurgeRatio:              resources.DefaultStageMaxSurgeRatio,
+			PodTerminationPolicy:            strategies.PodTerminationForceAfterTimeout,
+			StageFastAdvanceEnabled:         true,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+			StageScaleUpTolerance:           "10%",
+			TrafficEditPolicy:               resources.TrafficEditPolicyRestart,
+			PostRolloutStandbySeconds:       resources.DefaultPostRolloutStandbySeconds,
+			SupersedingPolicy:               resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds:      resources.DefaultSupersedingDebounceSeconds,
+		},
+		ExpectedError: nil,
+	}, {
+		name: "Test the RolloutConfig with the invalid stage scale up tolerance",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"stage-scale-up-tolerance": "few",
+			},
+		},
+		ExpectedResult: nil,
+		ExpectedError:  fmt.Errorf("failed to parse data: the tolerance %q is neither a number nor a percentage", "few"),
+	}, {
+		name: "Test the RolloutConfig with the unknown traffic edit policy",
+		input: &corev1.ConfigMap{
+			Data: map[string]string{
+				"traffic-edit-policy": "ignore",
+			},
+		},
+		ExpectedResult: nil,
+		ExpectedError: fmt.Errorf("failed to parse data: the traffic edit policy %q is not one of %s, %s and %s",
+			"ignore", resources.TrafficEditPolicyAdopt, resources.TrafficEditPolicyRestart,
+			resources.TrafficEditPolicyReject),
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -228,6 +455,152 @@ func TestLoadConfigFromService(t *testing.T) {
 			StageRolloutTimeoutMinutes: 10,
 			ProgressiveRolloutStrategy: strategies.AvailabilityStrategy,
 		},
+	}, {
+		name: "Test the RolloutConfig with the rollout disabled and deleted",
+		annotationInput: map[string]string{
+			resources.ProgressiveRolloutEnabled: "false",
+			resources.DeleteRolloutOnDisable:    "true",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio:       resources.OverSubRatio,
+			ProgressiveRolloutEnabled:  true,
+			StageRolloutTimeoutMinutes: resources.DefaultStageRolloutTimeoutMinutes,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:       resources.OverSubRatio,
+			ProgressiveRolloutEnabled:  false,
+			DeleteRolloutOnDisable:     true,
+			StageRolloutTimeoutMinutes: resources.DefaultStageRolloutTimeoutMinutes,
+		},
+	}, {
+		name: "Test the RolloutConfig with the over consumption basis as input",
+		annotationInput: map[string]string{
+			resources.OverConsumptionBasis: resources.OverConsumptionBasisResources,
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			OverConsumptionBasis: resources.OverConsumptionBasisReplicas,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			OverConsumptionBasis: resources.OverConsumptionBasisResources,
+		},
+	}, {
+		name: "Test the RolloutConfig with the fast advance and the soak as input",
+		annotationInput: map[string]string{
+			resources.StageFastAdvanceEnabled: "false",
+			resources.StageMinSoakSeconds:     "45",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio:    resources.OverSubRatio,
+			StageFastAdvanceEnabled: true,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:    resources.OverSubRatio,
+			StageFastAdvanceEnabled: false,
+			StageMinSoakSeconds:     45,
+		},
+	}, {
+		name: "Test the RolloutConfig with the warm-up as input",
+		annotationInput: map[string]string{
+			resources.StageWarmUpEnabled:              "true",
+			resources.StageWarmUpStabilizationSeconds: "90",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio:            resources.OverSubRatio,
+			StageWarmUpStabilizationSeconds: resources.DefaultStageWarmUpStabilizationSeconds,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:            resources.OverSubRatio,
+			StageWarmUpEnabled:              true,
+			StageWarmUpStabilizationSeconds: 90,
+		},
+	}, {
+		name: "Test the RolloutConfig with the post-rollout standby as input",
+		annotationInput: map[string]string{
+			resources.PostRolloutStandbyReplicas: "3",
+			resources.PostRolloutStandbySeconds:  "invalid",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio:      resources.OverSubRatio,
+			PostRolloutStandbySeconds: resources.DefaultPostRolloutStandbySeconds,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:       resources.OverSubRatio,
+			PostRolloutStandbyReplicas: 3,
+			PostRolloutStandbySeconds:  resources.DefaultPostRolloutStandbySeconds,
+		},
+	}, {
+		name: "Test the RolloutConfig with the stage scale up tolerance as input",
+		annotationInput: map[string]string{
+			resources.StageScaleUpTolerance: "2",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio:  resources.OverSubRatio,
+			StageScaleUpTolerance: "10%",
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:  resources.OverSubRatio,
+			StageScaleUpTolerance: "2",
+		},
+	}, {
+		name: "Test the RolloutConfig with the superseding policy as input",
+		annotationInput: map[string]string{
+			resources.SupersedingPolicy:          resources.SupersedingPolicyQueue,
+			resources.SupersedingDebounceSeconds: "invalid",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio:       resources.OverSubRatio,
+			SupersedingPolicy:          resources.SupersedingPolicySupersede,
+			SupersedingDebounceSeconds: resources.DefaultSupersedingDebounceSeconds,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio:       resources.OverSubRatio,
+			SupersedingPolicy:          resources.SupersedingPolicyQueue,
+			SupersedingDebounceSeconds: resources.DefaultSupersedingDebounceSeconds,
+		},
+	}, {
+		name: "Test the RolloutConfig with the traffic edit policy as input",
+		annotationInput: map[string]string{
+			resources.TrafficEditPolicy: resources.TrafficEditPolicyReject,
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			TrafficEditPolicy:    resources.TrafficEditPolicyRestart,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			TrafficEditPolicy:    resources.TrafficEditPolicyReject,
+		},
+	}, {
+		name: "Test the RolloutConfig with the unknown traffic edit policy as input",
+		annotationInput: map[string]string{
+			resources.TrafficEditPolicy: "ignore",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			TrafficEditPolicy:    resources.TrafficEditPolicyAdopt,
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			TrafficEditPolicy:    resources.TrafficEditPolicyAdopt,
+		},
+	}, {
+		name: "Test the RolloutConfig with the rollout tags as input",
+		annotationInput: map[string]string{
+			resources.CandidateTag: "canary",
+			resources.StableTag:    "Stable!",
+		},
+		configInput: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			CandidateTag:         "candidate",
+			StableTag:            "stable",
+		},
+		ExpectedResult: &RolloutConfig{
+			OverConsumptionRatio: resources.OverSubRatio,
+			CandidateTag:         "canary",
+			StableTag:            "stable",
+		},
 	}, {
 		name: "Test the RolloutConfig with invalid annotation as input",
 		annotationInput: map[string]string{
@@ -270,3 +643,349 @@ func TestLoadConfigFromService(t *testing.T) {
 		})
 	}
 }
+
+func TestGetStageScaleUpTolerance(t *testing.T) {
+	tests := []struct {
+		name           string
+		tolerance      string
+		ExpectedResult *intstr.IntOrString
+	}{{
+		name:           "Test the empty tolerance",
+		tolerance:      "",
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the zero tolerance",
+		tolerance:      "0%",
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the invalid tolerance",
+		tolerance:      "-1",
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the absolute tolerance",
+		tolerance:      "2",
+		ExpectedResult: &intstr.IntOrString{Type: intstr.Int, IntVal: 2},
+	}, {
+		name:           "Test the percentage tolerance",
+		tolerance:      "10%",
+		ExpectedResult: &intstr.IntOrString{Type: intstr.String, StrVal: "10%"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &RolloutConfig{StageScaleUpTolerance: test.tolerance}
+			if r := config.GetStageScaleUpTolerance(); !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of GetStageScaleUpTolerance() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestGetStageRolloutTimeout(t *testing.T) {
+	config := &RolloutConfig{
+		StageRolloutTimeoutMinutes:      2,
+		StageRolloutTimeoutFloorSeconds: 30,
+	}
+	tests := []struct {
+		name           string
+		config         *RolloutConfig
+		observation    *v1.ScaleUpObservation
+		ExpectedResult time.Duration
+	}{{
+		name:           "Test without any observation",
+		config:         config,
+		ExpectedResult: 2 * time.Minute,
+	}, {
+		name:   "Test with a fast scaling up, bounded by the floor",
+		config: config,
+		observation: &v1.ScaleUpObservation{
+			AverageMilliseconds: 5000,
+			MaxMilliseconds:     8000,
+			Samples:             3,
+		},
+		ExpectedResult: 30 * time.Second,
+	}, {
+		name:   "Test with the average scaling up",
+		config: config,
+		observation: &v1.ScaleUpObservation{
+			AverageMilliseconds: 20000,
+			MaxMilliseconds:     30000,
+			Samples:             3,
+		},
+		ExpectedResult: 40 * time.Second,
+	}, {
+		name:   "Test with the longest scaling up more than double of the average",
+		config: config,
+		observation: &v1.ScaleUpObservation{
+			AverageMilliseconds: 20000,
+			MaxMilliseconds:     50000,
+			Samples:             3,
+		},
+		ExpectedResult: 50 * time.Second,
+	}, {
+		name:   "Test with a slow scaling up, bounded by stage-rollout-timeout-minutes",
+		config: config,
+		observation: &v1.ScaleUpObservation{
+			AverageMilliseconds: 100000,
+			MaxMilliseconds:     150000,
+			Samples:             3,
+		},
+		ExpectedResult: 2 * time.Minute,
+	}, {
+		name: "Test with a slow scaling up, bounded by the ceiling",
+		config: &RolloutConfig{
+			StageRolloutTimeoutMinutes:        2,
+			StageRolloutTimeoutFloorSeconds:   30,
+			StageRolloutTimeoutCeilingSeconds: 600,
+		},
+		observation: &v1.ScaleUpObservation{
+			AverageMilliseconds: 100000,
+			MaxMilliseconds:     150000,
+			Samples:             3,
+		},
+		ExpectedResult: 200 * time.Second,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				Status: v1.RolloutOrchestratorStatus{
+					RolloutOrchestratorStatusFields: v1.RolloutOrchestratorStatusFields{
+						ScaleUpObservation: test.observation,
+					},
+				},
+			}
+			r := test.config.GetStageRolloutTimeout(ro)
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of GetStageRolloutTimeout() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestGetStageHold(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name           string
+		config         *RolloutConfig
+		ready          bool
+		readySince     time.Duration
+		finishIn       time.Duration
+		ExpectedResult time.Duration
+	}{{
+		name:           "Test the stage not ready",
+		config:         &RolloutConfig{StageFastAdvanceEnabled: true, StageMinSoakSeconds: 60},
+		finishIn:       time.Minute,
+		ExpectedResult: 0,
+	}, {
+		name:           "Test the fast advance without soak",
+		config:         &RolloutConfig{StageFastAdvanceEnabled: true},
+		ready:          true,
+		finishIn:       time.Minute,
+		ExpectedResult: 0,
+	}, {
+		name:           "Test the fast advance with the soak in progress",
+		config:         &RolloutConfig{StageFastAdvanceEnabled: true, StageMinSoakSeconds: 60},
+		ready:          true,
+		readySince:     20 * time.Second,
+		finishIn:       2 * time.Minute,
+		ExpectedResult: 40 * time.Second,
+	}, {
+		name:           "Test the fast advance with the soak over",
+		config:         &RolloutConfig{StageFastAdvanceEnabled: true, StageMinSoakSeconds: 60},
+		ready:          true,
+		readySince:     90 * time.Second,
+		finishIn:       2 * time.Minute,
+		ExpectedResult: 0,
+	}, {
+		name:           "Test the fast advance disabled before the deadline",
+		config:         &RolloutConfig{StageMinSoakSeconds: 60},
+		ready:          true,
+		readySince:     20 * time.Second,
+		finishIn:       2 * time.Minute,
+		ExpectedResult: 2 * time.Minute,
+	}, {
+		name:           "Test the fast advance disabled after the deadline",
+		config:         &RolloutConfig{},
+		ready:          true,
+		finishIn:       -time.Second,
+		ExpectedResult: 0,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				Spec: v1.RolloutOrchestratorSpec{
+					StageTarget: v1.StageTarget{
+						StageTargetRevisions: []v1.TargetRevision{{}},
+						TargetFinishTime:     apis.VolatileTime{Inner: metav1.NewTime(now.Add(test.finishIn))},
+					},
+				},
+			}
+			ro.Status.InitializeConditions()
+			if test.ready {
+				ro.Status.MarkStageRevisionReady()
+				for i := range ro.Status.Conditions {
+					if ro.Status.Conditions[i].Type == v1.SOStageReady {
+						ro.Status.Conditions[i].LastTransitionTime =
+							apis.VolatileTime{Inner: metav1.NewTime(now.Add(-test.readySince))}
+					}
+				}
+			}
+			r := test.config.GetStageHold(ro, now)
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of GetStageHold() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestUpdateRolloutOrchestratorStageHold(t *testing.T) {
+	now := time.Now()
+	stage := []v1.TargetRevision{{
+		TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(4),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20),
+			LatestRevision: ptr.Bool(true)},
+		Direction:      v1.DirectionUp,
+		TargetReplicas: ptr.Int32(1),
+	}}
+	tests := []struct {
+		name         string
+		config       *RolloutConfig
+		annotations  map[string]string
+		readySince   time.Duration
+		ExpectedHeld bool
+	}{{
+		name:         "Test the stage held by the minimum soak",
+		config:       &RolloutConfig{StageFastAdvanceEnabled: true, StageMinSoakSeconds: 60},
+		readySince:   20 * time.Second,
+		ExpectedHeld: true,
+	}, {
+		name:         "Test the stage advanced after the minimum soak",
+		config:       &RolloutConfig{StageFastAdvanceEnabled: true, StageMinSoakSeconds: 60},
+		readySince:   90 * time.Second,
+		ExpectedHeld: false,
+	}, {
+		name:         "Test the stage promoted during the minimum soak",
+		config:       &RolloutConfig{StageFastAdvanceEnabled: true, StageMinSoakSeconds: 60},
+		annotations:  map[string]string{resources.RolloutPromoted: "admin"},
+		readySince:   20 * time.Second,
+		ExpectedHeld: false,
+	}, {
+		name:         "Test the stage held until its deadline without the fast advance",
+		config:       &RolloutConfig{},
+		readySince:   90 * time.Second,
+		ExpectedHeld: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(false)},
+					}},
+					TargetRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(true)},
+					}},
+					StageTarget: v1.StageTarget{
+						StageTargetRevisions: stage,
+						TargetFinishTime:     apis.VolatileTime{Inner: metav1.NewTime(now.Add(time.Minute))},
+					},
+				},
+			}
+			ro.Status.InitializeConditions()
+			ro.Status.SetStageRevisionStatus(stage)
+			ro.Status.MarkStageRevisionReady()
+			ro.Status.MarkLastStageRevisionInComplete()
+			for i := range ro.Status.Conditions {
+				if ro.Status.Conditions[i].Type == v1.SOStageReady {
+					ro.Status.Conditions[i].LastTransitionTime =
+						apis.VolatileTime{Inner: metav1.NewTime(now.Add(-test.readySince))}
+				}
+			}
+			config := test.config
+			config.ProgressiveRolloutEnabled, config.StageRolloutTimeoutMinutes = true, 2
+			if err := updateRolloutOrchestrator(ro, &MockPodAutoscalerLister{}, nil, config); err != nil {
+				t.Fatalf("updateRolloutOrchestrator() = %v", err)
+			}
+			held := reflect.DeepEqual(ro.Spec.StageTargetRevisions, stage)
+			if held != test.ExpectedHeld {
+				t.Fatalf("Result of updateRolloutOrchestrator() held = %v, want %v, stage %v", held,
+					test.ExpectedHeld, ro.Spec.StageTargetRevisions)
+			}
+			if _, found := ro.Annotations[resources.RolloutPromoted]; found && !held {
+				t.Fatalf("Result of updateRolloutOrchestrator() keeps the annotation %s", resources.RolloutPromoted)
+			}
+		})
+	}
+}
+
+func TestGetStageRatio(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         *RolloutConfig
+		multiplier     int32
+		ExpectedResult int
+	}{{
+		name: "Test with the acceleration disabled",
+		config: &RolloutConfig{
+			OverConsumptionRatio: 10,
+			StageMaxSurgeRatio:   50,
+		},
+		multiplier:     4,
+		ExpectedResult: 10,
+	}, {
+		name: "Test with the acceleration enabled and no fast stage",
+		config: &RolloutConfig{
+			OverConsumptionRatio:     10,
+			StageAccelerationEnabled: true,
+			StageMaxSurgeRatio:       50,
+		},
+		ExpectedResult: 10,
+	}, {
+		name: "Test with the acceleration enabled after fast stages",
+		config: &RolloutConfig{
+			OverConsumptionRatio:     10,
+			StageAccelerationEnabled: true,
+			StageMaxSurgeRatio:       50,
+		},
+		multiplier:     4,
+		ExpectedResult: 40,
+	}, {
+		name: "Test with the acceleration bounded by the max surge",
+		config: &RolloutConfig{
+			OverConsumptionRatio:     10,
+			StageAccelerationEnabled: true,
+			StageMaxSurgeRatio:       50,
+		},
+		multiplier:     8,
+		ExpectedResult: 50,
+	}, {
+		name: "Test with the max surge lower than the over consumption ratio",
+		config: &RolloutConfig{
+			OverConsumptionRatio:     20,
+			StageAccelerationEnabled: true,
+			StageMaxSurgeRatio:       10,
+		},
+		multiplier:     8,
+		ExpectedResult: 20,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				Status: v1.RolloutOrchestratorStatus{
+					RolloutOrchestratorStatusFields: v1.RolloutOrchestratorStatusFields{
+						StageDeltaMultiplier: test.multiplier,
+					},
+				},
+			}
+			r := test.config.GetStageRatio(ro)
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of GetStageRatio() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
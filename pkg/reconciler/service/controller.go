@@ -19,6 +19,7 @@ package service
 import (
 	"context"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	spainformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/stagepodautoscaler"
 
@@ -30,6 +31,7 @@ import (
 	"knative.dev/serving-progressive-rollout/pkg/client/injection/client"
 	roinformer "knative.dev/serving-progressive-rollout/pkg/client/injection/informers/serving/v1/rolloutorchestrator"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/common"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
 	cfgmap "knative.dev/serving/pkg/apis/config"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	servingclient "knative.dev/serving/pkg/client/injection/client"
@@ -38,9 +40,13 @@ import (
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/revision"
 	routeinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/route"
 	kserviceinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/service"
+	routereconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/route"
 	ksvcreconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/service"
 )
 
+// routeAgentName is the name of the agent recording the Events about the rollout of the standalone Routes.
+const routeAgentName = "route-rollout-controller"
+
 // NewController initializes the controller and is called by the generated code
 // Registers eventhandlers to enqueue events
 func NewController(
@@ -94,3 +100,68 @@ func NewController(
 
 	return impl
 }
+
+// NewRouteController initializes the controller rolling out the standalone Routes, that have opted in to the
+// progressive rollout. It runs next to the Route controller of Knative Serving, and never updates the status.
+func NewRouteController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+	logger := logging.FromContext(ctx)
+	routeInformer := routeinformer.Get(ctx)
+	configurationInformer := configurationinformer.Get(ctx)
+	roInformer := roinformer.Get(ctx)
+
+	configStore := cfgmap.NewStore(logger.Named(common.ConfigStoreName))
+	configStore.WatchConfigs(cmw)
+
+	c := NewReconciler(
+		client.Get(ctx),
+		servingclient.Get(ctx),
+		configurationInformer.Lister(),
+		revisioninformer.Get(ctx).Lister(),
+		routeInformer.Lister(),
+		roInformer.Lister(),
+		painformer.Get(ctx).Lister(),
+		spainformer.Get(ctx).Lister(),
+		configmapinformer.Get(ctx).Lister(),
+		deploymentinformer.Get(ctx).Lister(),
+	)
+
+	opts := func(*controller.Impl) controller.Options {
+		return controller.Options{
+			ConfigStore:       configStore,
+			AgentName:         routeAgentName,
+			SkipStatusUpdates: true,
+		}
+	}
+	impl := routereconciler.NewImpl(ctx, NewRouteReconciler(c), opts)
+	c.enqueueAfter = impl.EnqueueAfter
+
+	routeInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	// The new revisions of the Configuration are rolled out by the Routes following it.
+	configurationInformer.Informer().AddEventHandler(controller.HandleAll(func(obj interface{}) {
+		config, ok := obj.(*v1.Configuration)
+		if !ok {
+			return
+		}
+		routes, err := routeInformer.Lister().Routes(config.Namespace).List(labels.Everything())
+		if err != nil {
+			return
+		}
+		for _, route := range routes {
+			if resources.IsRouteRolloutEnabled(route) &&
+				resources.GetRouteConfigurationName(resources.GetRouteTargetTraffic(route)) == config.Name {
+				impl.Enqueue(route)
+			}
+		}
+	}))
+
+	roInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&v1.Route{}),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	return impl
+}
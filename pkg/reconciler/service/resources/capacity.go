@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
+	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
+)
+
+// GetPodCapacity calculates how much load one replica is able to handle, based on the autoscaling annotations
+// and the spec of the revision.
+//
+// For the concurrency and rps metrics, the capacity is the target the autoscaler aims for per replica, which
+// takes containerConcurrency, the target annotation and the target utilization into account.
+// For the cpu metric, the capacity is the share of the cpu requests defined by the target utilization.
+// For the memory metric, the capacity is the target value of the memory for each replica.
+// If the capacity cannot be determined, nil is returned.
+func GetPodCapacity(annotations map[string]string, spec *servingv1.RevisionSpec,
+	asConfig *autoscalerconfig.Config) *v1.PodCapacity {
+	if asConfig == nil || spec == nil {
+		return nil
+	}
+
+	// Build a PodAutoscaler in memory, so that the metric and its target are resolved the same way as the
+	// autoscaler does for the revision.
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: annotations,
+		},
+	}
+	if _, ok := annotations[autoscaling.ClassAnnotationKey]; !ok {
+		// The class annotation is not available, if it is not configured explicitly. Fall back to the default
+		// class of the cluster.
+		annotations = copyAnnotations(annotations)
+		annotations[autoscaling.ClassAnnotationKey] = asConfig.PodAutoscalerClass
+		pa.Annotations = annotations
+	}
+	if spec.ContainerConcurrency != nil {
+		pa.Spec.ContainerConcurrency = *spec.ContainerConcurrency
+	}
+
+	metric := pa.Metric()
+	var capacity float64
+	switch metric {
+	case autoscaling.Concurrency, autoscaling.RPS:
+		capacity, _ = aresources.ResolveMetricTarget(pa, asConfig)
+	case autoscaling.CPU:
+		target, ok := pa.Target()
+		if !ok {
+			return nil
+		}
+		cpu := containerRequests(spec, corev1.ResourceCPU)
+		if cpu == 0 {
+			return nil
+		}
+		// The target of the cpu metric is the percentage of the cpu requests.
+		capacity = float64(cpu) * target / 100 / 1000
+	case autoscaling.Memory:
+		target, ok := pa.Target()
+		if !ok {
+			return nil
+		}
+		capacity = target
+	default:
+		return nil
+	}
+
+	if capacity <= 0 {
+		return nil
+	}
+	return &v1.PodCapacity{
+		Metric:     metric,
+		MilliValue: int64(math.Round(capacity * 1000)),
+	}
+}
+
+// CapacityRatio returns the ratio, that converts a number of replicas of the revision with the capacity "from"
+// into the number of replicas of the revision with the capacity "to", handling the same load.
+//
+// If either of the capacities is unknown, or they are measured in different metrics, the replicas are
+// considered equivalent, and 1 is returned.
+func CapacityRatio(from, to *v1.PodCapacity) float64 {
+	if from == nil || to == nil || from.Metric != to.Metric || from.MilliValue <= 0 || to.MilliValue <= 0 {
+		return 1
+	}
+	return float64(from.MilliValue) / float64(to.MilliValue)
+}
+
+// GetPodResources returns the cpu and memory requested by one replica of the revision. If neither of them is
+// requested, nil is returned.
+func GetPodResources(spec *servingv1.RevisionSpec) *v1.PodResources {
+	if spec == nil {
+		return nil
+	}
+	cpu := containerRequests(spec, corev1.ResourceCPU)
+	memory := containerRequests(spec, corev1.ResourceMemory) / 1000
+	if cpu == 0 && memory == 0 {
+		return nil
+	}
+	return &v1.PodResources{
+		CPUMilliValue: cpu,
+		MemoryValue:   memory,
+	}
+}
+
+// containerRequests sums up the requests of the resource for all the containers in the revision, in milli units.
+func containerRequests(spec *servingv1.RevisionSpec, name corev1.ResourceName) int64 {
+	var total int64
+	for _, container := range spec.Containers {
+		if request, ok := container.Resources.Requests[name]; ok {
+			total += request.MilliValue()
+		}
+	}
+	return total
+}
+
+func copyAnnotations(annotations map[string]string) map[string]string {
+	result := make(map[string]string, len(annotations)+1)
+	for key, val := range annotations {
+		result[key] = val
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	asconfig "knative.dev/serving/pkg/autoscaler/config"
+)
+
+func TestGetPodCapacity(t *testing.T) {
+	asConfig, _ := asconfig.NewConfigFromMap(map[string]string{})
+	cpuSpec := servingv1.RevisionSpec{
+		PodSpec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("500m"),
+					},
+				},
+			}},
+		},
+	}
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		spec           *servingv1.RevisionSpec
+		ExpectedResult *v1.PodCapacity
+	}{{
+		name:        "Test with the default concurrency target",
+		annotations: map[string]string{},
+		spec:        &servingv1.RevisionSpec{},
+		ExpectedResult: &v1.PodCapacity{
+			Metric:     autoscaling.Concurrency,
+			MilliValue: 70000,
+		},
+	}, {
+		name:        "Test with the containerConcurrency",
+		annotations: map[string]string{},
+		spec: &servingv1.RevisionSpec{
+			ContainerConcurrency: ptr.Int64(10),
+		},
+		ExpectedResult: &v1.PodCapacity{
+			Metric:     autoscaling.Concurrency,
+			MilliValue: 7000,
+		},
+	}, {
+		name: "Test with the target annotation and the target utilization",
+		annotations: map[string]string{
+			autoscaling.TargetAnnotationKey:            "50",
+			autoscaling.TargetUtilizationPercentageKey: "100",
+		},
+		spec: &servingv1.RevisionSpec{
+			ContainerConcurrency: ptr.Int64(20),
+		},
+		ExpectedResult: &v1.PodCapacity{
+			Metric:     autoscaling.Concurrency,
+			MilliValue: 20000,
+		},
+	}, {
+		name: "Test with the rps metric",
+		annotations: map[string]string{
+			autoscaling.MetricAnnotationKey: autoscaling.RPS,
+		},
+		spec: &servingv1.RevisionSpec{},
+		ExpectedResult: &v1.PodCapacity{
+			Metric:     autoscaling.RPS,
+			MilliValue: 140000,
+		},
+	}, {
+		name: "Test with the cpu metric",
+		annotations: map[string]string{
+			autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+			autoscaling.TargetAnnotationKey: "80",
+		},
+		spec: &cpuSpec,
+		ExpectedResult: &v1.PodCapacity{
+			Metric:     autoscaling.CPU,
+			MilliValue: 400,
+		},
+	}, {
+		name: "Test with the cpu metric without cpu requests",
+		annotations: map[string]string{
+			autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+			autoscaling.TargetAnnotationKey: "80",
+		},
+		spec:           &servingv1.RevisionSpec{},
+		ExpectedResult: nil,
+	}, {
+		name: "Test with the memory metric",
+		annotations: map[string]string{
+			autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+			autoscaling.MetricAnnotationKey: autoscaling.Memory,
+			autoscaling.TargetAnnotationKey: "256",
+		},
+		spec: &servingv1.RevisionSpec{},
+		ExpectedResult: &v1.PodCapacity{
+			Metric:     autoscaling.Memory,
+			MilliValue: 256000,
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := GetPodCapacity(test.annotations, test.spec, asConfig)
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of GetPodCapacity() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestGetPodResources(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           *servingv1.RevisionSpec
+		ExpectedResult *v1.PodResources
+	}{{
+		name:           "Test without spec",
+		ExpectedResult: nil,
+	}, {
+		name: "Test without requests",
+		spec: &servingv1.RevisionSpec{
+			PodSpec: corev1.PodSpec{Containers: []corev1.Container{{}}},
+		},
+		ExpectedResult: nil,
+	}, {
+		name: "Test with the requests of multiple containers",
+		spec: &servingv1.RevisionSpec{
+			PodSpec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				}, {
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("250m"),
+						},
+					},
+				}},
+			},
+		},
+		ExpectedResult: &v1.PodResources{CPUMilliValue: 750, MemoryValue: 128 * 1024 * 1024},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := GetPodResources(test.spec)
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of GetPodResources() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestCapacityRatio(t *testing.T) {
+	tests := []struct {
+		name           string
+		from           *v1.PodCapacity
+		to             *v1.PodCapacity
+		ExpectedResult float64
+	}{{
+		name:           "Test with unknown capacities",
+		ExpectedResult: 1,
+	}, {
+		name:           "Test with different metrics",
+		from:           &v1.PodCapacity{Metric: autoscaling.Concurrency, MilliValue: 70000},
+		to:             &v1.PodCapacity{Metric: autoscaling.RPS, MilliValue: 140000},
+		ExpectedResult: 1,
+	}, {
+		name:           "Test with the capacity reduced by half",
+		from:           &v1.PodCapacity{Metric: autoscaling.Concurrency, MilliValue: 70000},
+		to:             &v1.PodCapacity{Metric: autoscaling.Concurrency, MilliValue: 35000},
+		ExpectedResult: 2,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := CapacityRatio(test.from, test.to)
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of CapacityRatio() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
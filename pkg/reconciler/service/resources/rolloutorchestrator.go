@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmeta"
@@ -39,24 +41,201 @@ var (
 	// from the old to the new revision during each stage in the progressive rollout.
 	OverSubRatio = 10
 
+	// OverConsumptionBasisReplicas is the basis of the over-consumption budget, that limits the replicas added
+	// in each stage to the ratio of the replicas.
+	OverConsumptionBasisReplicas = "replicas"
+
+	// OverConsumptionBasisResources is the basis of the over-consumption budget, that limits the cpu and memory
+	// requested by the replicas added in each stage to the ratio of the requested resources.
+	OverConsumptionBasisResources = "resources"
+
+	// TrafficEditPolicyAdopt is the policy, that adopts the traffic edited in the middle of a rollout as the new
+	// target. The current stage goes on, and the next stages move from the current traffic split to the new target.
+	TrafficEditPolicyAdopt = "adopt"
+
+	// TrafficEditPolicyRestart is the policy, that restarts the rollout from the current traffic split to the traffic
+	// edited in the middle of a rollout.
+	TrafficEditPolicyRestart = "restart"
+
+	// TrafficEditPolicyReject is the policy, that keeps the rollout going to its target, when the traffic is edited
+	// in the middle of it. The edited traffic is rolled out after the current rollout completes.
+	TrafficEditPolicyReject = "reject"
+
+	// SupersedingPolicySupersede is the policy, that rolls out the revision created in the middle of a rollout right
+	// away. The revision the rollout was going to is drained first, and then the other initial revisions.
+	SupersedingPolicySupersede = "supersede"
+
+	// SupersedingPolicyQueue is the policy, that finishes the rollout in progress first, and then rolls out the
+	// revision created in the middle of it.
+	SupersedingPolicyQueue = "queue"
+
+	// SupersedingPolicyDebounce is the policy, that waits for further revisions to be created in the middle of a
+	// rollout, and supersedes the rollout with the latest revision, once no revision has been created for the
+	// debounce period.
+	SupersedingPolicyDebounce = "debounce"
+
+	// DefaultSupersedingDebounceSeconds is the default debounce period in seconds of the debounce superseding policy.
+	DefaultSupersedingDebounceSeconds = 30
+
 	// DefaultStageRolloutTimeoutMinutes is the default timeout for stage to accomplish during the rollout.
 	DefaultStageRolloutTimeoutMinutes = 2
 
+	// DefaultStageRolloutTimeoutFloorSeconds is the default lower bound of the stage timeout, calculated from
+	// the observed durations of scaling up.
+	DefaultStageRolloutTimeoutFloorSeconds = 30
+
+	// DefaultStageMaxSurgeRatio is the default upper bound of the percentage of the traffic shifted in one stage,
+	// when the stages are accelerated.
+	DefaultStageMaxSurgeRatio = 50
+
+	// DefaultStageWarmUpStabilizationSeconds is the default duration in seconds, for which the pre-scaled replicas
+	// of the revision scaling up are kept after the stage is ready. It matches the stable window of the autoscaler.
+	DefaultStageWarmUpStabilizationSeconds = 60
+
+	// DefaultPostRolloutStandbySeconds is the default bake period in seconds, for which the initial revisions are
+	// kept on standby after the last stage is complete.
+	DefaultPostRolloutStandbySeconds = 600
+
+	// StageRolloutTimeoutObservedFactor is the factor applied to the average observed duration of scaling up,
+	// to calculate the stage timeout.
+	StageRolloutTimeoutObservedFactor = 2
+
 	// GroupName is the group name.
 	GroupName = "rollout.knative.dev"
 
 	// OverConsumptionRatioKey is the annotation key Knative Service can use to specify the over consumption ratio.
 	OverConsumptionRatioKey = GroupName + "/over-consumption-ratio"
 
+	// OverConsumptionBasis is the annotation key Knative Service can use to specify whether the over consumption
+	// ratio applies to the replicas or to the requested resources.
+	OverConsumptionBasis = GroupName + "/over-consumption-basis"
+
 	// StageRolloutTimeoutMinutes is the annotation key Knative Service can use to specify the stage rollout timeout.
 	StageRolloutTimeoutMinutes = GroupName + "/stage-rollout-timeout-minutes"
 
+	// StageRolloutTimeoutFloorSeconds is the annotation key Knative Service can use to specify the lower bound of
+	// the stage rollout timeout, calculated from the observed durations of scaling up.
+	StageRolloutTimeoutFloorSeconds = GroupName + "/stage-rollout-timeout-floor-seconds"
+
+	// StageRolloutTimeoutCeilingSeconds is the annotation key Knative Service can use to specify the upper bound of
+	// the stage rollout timeout, calculated from the observed durations of scaling up.
+	StageRolloutTimeoutCeilingSeconds = GroupName + "/stage-rollout-timeout-ceiling-seconds"
+
 	// ProgressiveRolloutEnabled is the annotation key Knative Service can use to enable or disable the progressive rollout.
 	ProgressiveRolloutEnabled = GroupName + "/progressive-rollout-enabled"
 
+	// DeleteRolloutOnDisable is the annotation key Knative Service can use to delete the RolloutOrchestrator and
+	// the StagePodAutoscalers, when the progressive rollout is disabled.
+	DeleteRolloutOnDisable = GroupName + "/delete-rollout-on-disable"
+
+	// StageAccelerationEnabled is the annotation key Knative Service can use to enable or disable growing the size
+	// of the stages after the healthy stages.
+	StageAccelerationEnabled = GroupName + "/stage-acceleration-enabled"
+
+	// StageMaxSurgeRatio is the annotation key Knative Service can use to specify the upper bound of the percentage
+	// of the traffic shifted in one stage, when the stages are accelerated.
+	StageMaxSurgeRatio = GroupName + "/stage-max-surge-ratio"
+
+	// PodTerminationPolicy is the annotation key Knative Service can use to specify how the terminating pods
+	// are deleted during the rollout.
+	PodTerminationPolicy = GroupName + "/pod-termination-policy"
+
+	// MaxForcedDeletionsPerStage is the annotation key Knative Service can use to specify the upper bound for
+	// the number of the pods force-deleted in one stage.
+	MaxForcedDeletionsPerStage = GroupName + "/max-forced-deletions-per-stage"
+
+	// StageFastAdvanceEnabled is the annotation key Knative Service can use to enable or disable starting the next
+	// stage as soon as the current stage is ready, instead of at the deadline of the current stage.
+	StageFastAdvanceEnabled = GroupName + "/stage-fast-advance-enabled"
+
+	// StageMinSoakSeconds is the annotation key Knative Service can use to specify the minimum duration in seconds,
+	// for which a ready stage is kept before the next stage starts.
+	StageMinSoakSeconds = GroupName + "/stage-min-soak-seconds"
+
+	// StageWarmUpEnabled is the annotation key Knative Service can use to enable or disable pre-scaling the revision
+	// scaling up to the load of its traffic share, before the traffic moves.
+	StageWarmUpEnabled = GroupName + "/stage-warm-up-enabled"
+
+	// StageWarmUpStabilizationSeconds is the annotation key Knative Service can use to specify the duration in
+	// seconds, for which the pre-scaled replicas are kept after the stage is ready.
+	StageWarmUpStabilizationSeconds = GroupName + "/stage-warm-up-stabilization-seconds"
+
+	// StageScaleUpTolerance is the annotation key Knative Service can use to specify the number or the percentage
+	// of the target replicas of the revision scaling up, that may still be missing, when the stage is scaled up.
+	StageScaleUpTolerance = GroupName + "/stage-scale-up-tolerance"
+
+	// SupersedingPolicy is the annotation key Knative Service can use to specify how the revision created in the
+	// middle of a rollout is rolled out.
+	SupersedingPolicy = GroupName + "/superseding-policy"
+
+	// SupersedingDebounceSeconds is the annotation key Knative Service can use to specify the debounce period in
+	// seconds of the debounce superseding policy.
+	SupersedingDebounceSeconds = GroupName + "/superseding-debounce-seconds"
+
+	// PostRolloutStandbyReplicas is the annotation key Knative Service can use to specify the number of replicas
+	// each initial revision keeps on standby, once its traffic has moved away.
+	PostRolloutStandbyReplicas = GroupName + "/post-rollout-standby-replicas"
+
+	// PostRolloutStandbySeconds is the annotation key Knative Service can use to specify the bake period in seconds,
+	// for which the initial revisions are kept on standby after the last stage is complete.
+	PostRolloutStandbySeconds = GroupName + "/post-rollout-standby-seconds"
+
+	// TrafficEditPolicy is the annotation key Knative Service can use to specify how the traffic of the service
+	// edited in the middle of a rollout is handled.
+	TrafficEditPolicy = GroupName + "/traffic-edit-policy"
+
+	// CandidateTag is the annotation key Knative Service can use to specify the tag of the revision scaling up
+	// during a rollout.
+	CandidateTag = GroupName + "/rollout-candidate-tag"
+
+	// StableTag is the annotation key Knative Service can use to specify the tag of the revision scaling down
+	// during a rollout.
+	StableTag = GroupName + "/rollout-stable-tag"
+
 	// ProgressiveRolloutStrategy determines the mode to roll out the new revision progressively.
 	ProgressiveRolloutStrategy = GroupName + "/progressive-rollout-strategy"
 
+	// RolloutGroupHold is the annotation key the RolloutGroup sets on the RolloutOrchestrator of a member, to keep
+	// it from moving on to the next stage. The value is the name of the RolloutGroup.
+	RolloutGroupHold = GroupName + "/rollout-group-hold"
+
+	// RolloutGroupRollback is the annotation key the RolloutGroup sets on the RolloutOrchestrator of a member, to
+	// shift the traffic back to the initial revisions. The value is the name of the RolloutGroup.
+	RolloutGroupRollback = GroupName + "/rollout-group-rollback"
+
+	// RolloutPaused is the annotation key set on the RolloutOrchestrator to keep it at the current stage, until the
+	// annotation is removed. The value is the user who paused the rollout.
+	RolloutPaused = GroupName + "/rollout-paused"
+
+	// RolloutPromoted is the annotation key set on the RolloutOrchestrator to start the next stage as soon as the
+	// current stage is ready, without waiting for the soak or the deadline of the stage. The annotation is removed,
+	// when the next stage starts. The value is the user who promoted the rollout.
+	RolloutPromoted = GroupName + "/rollout-promoted"
+
+	// RolloutPromotedToFinal is the annotation key set on the RolloutOrchestrator to skip the remaining stages, and
+	// move all the traffic to the target revisions in the final stage. The annotation is removed, and the promotion
+	// is recorded in the spec, as soon as the service reconciler accepts it. The value is the user who promoted the
+	// rollout.
+	RolloutPromotedToFinal = GroupName + "/rollout-promoted-to-final"
+
+	// RolloutAborted is the annotation key set on the RolloutOrchestrator to shift the traffic back to the initial
+	// revisions. The value is the comma-separated names of the target revisions of the aborted rollout, so that
+	// the next rollout is not aborted.
+	RolloutAborted = GroupName + "/rollout-aborted"
+
+	// RolloutReverted is the annotation key set on the RolloutOrchestrator to shift the traffic back to the initial
+	// revisions after the last stage, while they are still on standby. The value is the comma-separated names of the
+	// target revisions of the reverted rollout, so that the next rollout is not reverted.
+	RolloutReverted = GroupName + "/rollout-reverted"
+
+	// RouteTargetTraffic is the annotation key set on the standalone Route rolled out progressively, to keep the
+	// traffic defined by the user, while the spec of the Route carries the traffic of the current stage.
+	RouteTargetTraffic = GroupName + "/route-target-traffic"
+
+	// RouteStageTraffic is the annotation key set on the standalone Route rolled out progressively, to keep the
+	// traffic of the stage last written into the spec, so that the traffic edited by the user is told apart.
+	RouteStageTraffic = GroupName + "/route-stage-traffic"
+
 	// ConfigMapName is the name of the ConfigMap, that saves the configuration information about the rollout orchestrator.
 	ConfigMapName = "config-rolloutorchestrator"
 
@@ -64,11 +243,27 @@ var (
 	ConfigMapNetworkName = "config-network"
 )
 
-// RevisionRecord is a struct that hosts the name, minScale and maxScale for the revision.
+// TrafficEditAccepted is the condition of the Knative Service, that is set to False, when the traffic of the service
+// edited in the middle of a rollout is rejected.
+const TrafficEditAccepted apis.ConditionType = "TrafficEditAccepted"
+
+// RouteTrafficInSync is the condition of the Knative Service, that is set to False, while the traffic of its route
+// edited out of band is reverted to the traffic of the rollout.
+const RouteTrafficInSync apis.ConditionType = "RouteTrafficInSync"
+
+// LatestRevisionRollout is the condition of the Knative Service, that reports the superseding policy applied to the
+// revision created in the middle of a rollout. It is set to False, when the rollout of the revision is queued or
+// debounced, and to True, when the revision supersedes the rollout.
+const LatestRevisionRollout apis.ConditionType = "LatestRevisionRollout"
+
+// RevisionRecord is a struct that hosts the name, minScale, maxScale, the capacity and the requested resources
+// of one replica for the revision.
 type RevisionRecord struct {
-	MinScale *int32
-	MaxScale *int32
-	Name     string
+	MinScale     *int32
+	MaxScale     *int32
+	Name         string
+	PodCapacity  *v1.PodCapacity
+	PodResources *v1.PodResources
 }
 
 // ReadIntAnnotation reads the int value of a specific key in the annotation of the revision.
@@ -125,6 +320,8 @@ func initializeTargetRevisions(revisionTarget *[]v1.TargetRevision, traffic *ser
 	target.URL = traffic.URL
 	if val, ok := records[target.RevisionName]; ok {
 		target.MinScale, target.MaxScale = ReadIntRevisionRecord(val)
+		target.PodCapacity = val.PodCapacity.DeepCopy()
+		target.PodResources = val.PodResources.DeepCopy()
 	} else {
 		// Get min and max scales from the service
 		target.MinScale = ReadIntServiceAnnotation(service, autoscaling.MinScaleAnnotationKey)
@@ -139,8 +336,8 @@ func initializeTargetRevisions(revisionTarget *[]v1.TargetRevision, traffic *ser
 func GetFinalTargetRevision(service *servingv1.Service, config *servingv1.Configuration,
 	records map[string]RevisionRecord) []v1.TargetRevision {
 	var ultimateRevisionTarget []v1.TargetRevision
-	// This is how the last revision is named after the service generation.
-	lastRevName := kmeta.ChildName(service.Name, fmt.Sprintf("-%05d", config.Generation))
+	// This is how the last revision is named after the configuration generation.
+	lastRevName := kmeta.ChildName(config.Name, fmt.Sprintf("-%05d", config.Generation))
 	if len(service.Spec.Traffic) == 0 {
 		// If the Traffic information is empty in the service spec, no traffic split is defined. There is only
 		// one element in the TargetRevision list.
@@ -181,7 +378,7 @@ func latestRevisionZero(trafficTarget servingv1.TrafficTarget) bool {
 func GetInitialTargetRevision(service *servingv1.Service, config *servingv1.Configuration,
 	records map[string]RevisionRecord, route *servingv1.Route) []v1.TargetRevision {
 	var initialTargetRevision []v1.TargetRevision
-	lastRevName := kmeta.ChildName(service.Name, fmt.Sprintf("-%05d", config.Generation))
+	lastRevName := kmeta.ChildName(config.Name, fmt.Sprintf("-%05d", config.Generation))
 	if (route != nil) && len(route.Status.Traffic) > 0 {
 		// initialTargetRevision is only needed when this function is called to create the RolloutOrchestrator.
 		// If there is route and the route status contains the traffic information, initialTargetRevision will be
@@ -227,16 +424,22 @@ func consolidateTraffic(trafficTargets []servingv1.TrafficTarget) []servingv1.Tr
 	return res
 }
 
-// NewInitialFinalTargetRev creates a RolloutOrchestrator with InitialRevisions and TargetRevisions.
+// NewInitialFinalTargetRev creates a RolloutOrchestrator with InitialRevisions and TargetRevisions. The owner is
+// the Knative Service, or the standalone Route rolled out progressively.
 func NewInitialFinalTargetRev(initialRevisionStatus, ultimateRevisionTarget []v1.TargetRevision,
-	service *servingv1.Service) *v1.RolloutOrchestrator {
+	owner kmeta.OwnerRefable) *v1.RolloutOrchestrator {
+	meta := owner.GetObjectMeta()
+	labelKey := serving.ServiceLabelKey
+	if _, ok := owner.(*servingv1.Route); ok {
+		labelKey = serving.RouteLabelKey
+	}
 	return &v1.RolloutOrchestrator{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      service.Name,
-			Namespace: service.Namespace,
-			Labels:    map[string]string{serving.ServiceLabelKey: service.Name},
+			Name:      meta.GetName(),
+			Namespace: meta.GetNamespace(),
+			Labels:    map[string]string{labelKey: meta.GetName()},
 			OwnerReferences: []metav1.OwnerReference{
-				*kmeta.NewControllerRef(service),
+				*kmeta.NewControllerRef(owner),
 			},
 		},
 		Spec: v1.RolloutOrchestratorSpec{
@@ -271,7 +474,6 @@ func UpdateInitialFinalTargetRev(ultimateRevisionTarget []v1.TargetRevision, ro
 						if rev.IsRevScalingUp() {
 							// Locate the index of the revision scaling up, using the selector
 							selector = labels.SelectorFromSet(labels.Set{
-								serving.ServiceLabelKey:  ro.Name,
 								serving.RevisionLabelKey: rev.RevisionName,
 							})
 							break
@@ -298,12 +500,94 @@ func UpdateInitialFinalTargetRev(ultimateRevisionTarget []v1.TargetRevision, ro
 		// Reset the StageTargetRevisions
 		ro.Spec.StageTargetRevisions = nil
 		ro.Spec.TargetFinishTime = apis.VolatileTime{}
+		// The promotion only applies to the rollout it has been accepted for.
+		ro.Spec.Promotion = nil
 	}
 
 	// If ultimateRevisionTarget is equal to the TargetRevisions in the spec(), it means no update happened on the ksvc,
 	// and it is still in the progress of rolling out the new revision. No need to change the RolloutOrchestrator.
 }
 
+// IsTrafficEdit returns true, if ultimateRevisionTarget only changes the traffic among the revisions known to the
+// RolloutOrchestrator. This is the case, when the traffic block of the service is edited instead of the template,
+// because a change to the template always creates a new revision.
+func IsTrafficEdit(ultimateRevisionTarget []v1.TargetRevision, ro *v1.RolloutOrchestrator) bool {
+	if trafficEqual(ro.Spec.TargetRevisions, ultimateRevisionTarget) {
+		return false
+	}
+	known := knownRevisions(ro)
+	for _, target := range ultimateRevisionTarget {
+		if !known.Has(target.RevisionName) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsNewRevision returns true, if ultimateRevisionTarget includes a revision unknown to the RolloutOrchestrator. This
+// is the case, when a new revision has been created.
+func IsNewRevision(ultimateRevisionTarget []v1.TargetRevision, ro *v1.RolloutOrchestrator) bool {
+	return GetNewRevisionName(ultimateRevisionTarget, ro) != ""
+}
+
+// GetNewRevisionName returns the name of the first revision in ultimateRevisionTarget unknown to the
+// RolloutOrchestrator. It returns an empty string, if there is no such revision.
+func GetNewRevisionName(ultimateRevisionTarget []v1.TargetRevision, ro *v1.RolloutOrchestrator) string {
+	if trafficEqual(ro.Spec.TargetRevisions, ultimateRevisionTarget) {
+		return ""
+	}
+	known := knownRevisions(ro)
+	for _, target := range ultimateRevisionTarget {
+		if !known.Has(target.RevisionName) {
+			return target.RevisionName
+		}
+	}
+	return ""
+}
+
+// AdoptTrafficEdit sets ultimateRevisionTarget as the TargetRevisions of the RolloutOrchestrator, without resetting
+// the current stage. The next stages start from the traffic split of the current stage.
+func AdoptTrafficEdit(ultimateRevisionTarget []v1.TargetRevision, ro *v1.RolloutOrchestrator) {
+	if len(ro.Status.StageRevisionStatus) != 0 {
+		ro.Spec.InitialRevisions = append([]v1.TargetRevision{}, ro.Status.StageRevisionStatus...)
+	}
+	ro.Spec.TargetRevisions = ultimateRevisionTarget
+}
+
+// GetUnknownRouteRevisions returns the names of the revisions, that receive traffic from the route, but are neither
+// known to the RolloutOrchestrator nor in the traffic of the service. It means the traffic of the route has been
+// edited out of band.
+func GetUnknownRouteRevisions(route *servingv1.Route, service *servingv1.Service, ro *v1.RolloutOrchestrator) []string {
+	if route == nil {
+		return nil
+	}
+	known := knownRevisions(ro)
+	for _, traffic := range service.Spec.Traffic {
+		known.Insert(traffic.RevisionName)
+	}
+	var unknown []string
+	for _, traffic := range route.Spec.Traffic {
+		if traffic.RevisionName == "" || traffic.Percent == nil || *traffic.Percent == 0 ||
+			known.Has(traffic.RevisionName) {
+			continue
+		}
+		unknown = append(unknown, traffic.RevisionName)
+	}
+	return unknown
+}
+
+// knownRevisions returns the names of all the revisions the RolloutOrchestrator is rolling out from or to.
+func knownRevisions(ro *v1.RolloutOrchestrator) sets.Set[string] {
+	known := sets.New[string]()
+	for _, revs := range [][]v1.TargetRevision{ro.Spec.InitialRevisions, ro.Spec.TargetRevisions,
+		ro.Spec.StageTargetRevisions, ro.Status.StageRevisionStatus} {
+		for _, rev := range revs {
+			known.Insert(rev.RevisionName)
+		}
+	}
+	return known
+}
+
 func trafficEqual(origin, target []v1.TargetRevision) bool {
 	// Currently, we consider two TargetRevision arrays are the same, when the length of the TargetRevision array
 	// is the same, the order of the TargetRevisions is the same, and per the same revision, the traffic percentage
@@ -318,3 +602,25 @@ func trafficEqual(origin, target []v1.TargetRevision) bool {
 	}
 	return true
 }
+
+// TargetRevisionNames returns the comma-separated names of the target revisions of the RolloutOrchestrator, which
+// identify the rollout.
+func TargetRevisionNames(ro *v1.RolloutOrchestrator) string {
+	names := make([]string, 0, len(ro.Spec.TargetRevisions))
+	for _, rev := range ro.Spec.TargetRevisions {
+		names = append(names, rev.RevisionName)
+	}
+	return strings.Join(names, ",")
+}
+
+// IsRolloutAborted returns true, if the current rollout of the RolloutOrchestrator has been aborted.
+func IsRolloutAborted(ro *v1.RolloutOrchestrator) bool {
+	aborted, found := ro.Annotations[RolloutAborted]
+	return found && len(ro.Spec.TargetRevisions) != 0 && aborted == TargetRevisionNames(ro)
+}
+
+// IsRolloutReverted returns true, if the current rollout of the RolloutOrchestrator has been reverted.
+func IsRolloutReverted(ro *v1.RolloutOrchestrator) bool {
+	reverted, found := ro.Annotations[RolloutReverted]
+	return found && len(ro.Spec.TargetRevisions) != 0 && reverted == TargetRevisionNames(ro)
+}
@@ -2136,3 +2136,195 @@ func TestConsolidateTraffic(t *testing.T) {
 		})
 	}
 }
+
+func TestIsTrafficEdit(t *testing.T) {
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(100)},
+			}},
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(100)},
+			}},
+		},
+	}
+	tests := []struct {
+		name                   string
+		ultimateRevisionTarget []v1.TargetRevision
+		ExpectedResult         bool
+	}{{
+		name: "Test the same traffic",
+		ultimateRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(100)},
+		}},
+		ExpectedResult: false,
+	}, {
+		name: "Test the traffic edited among the known revisions",
+		ultimateRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(50)},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(50)},
+		}},
+		ExpectedResult: true,
+	}, {
+		name: "Test the new revision created by the template",
+		ultimateRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0003", Percent: ptr.Int64(100)},
+		}},
+		ExpectedResult: false,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := IsTrafficEdit(test.ultimateRevisionTarget, ro); r != test.ExpectedResult {
+				t.Fatalf("Result of IsTrafficEdit() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestIsNewRevision(t *testing.T) {
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(100)},
+			}},
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(100)},
+			}},
+		},
+	}
+	tests := []struct {
+		name                   string
+		ultimateRevisionTarget []v1.TargetRevision
+		ExpectedResult         bool
+	}{{
+		name: "Test the same traffic",
+		ultimateRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(100)},
+		}},
+		ExpectedResult: false,
+	}, {
+		name: "Test the traffic edited among the known revisions",
+		ultimateRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(50)},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(50)},
+		}},
+		ExpectedResult: false,
+	}, {
+		name: "Test the new revision created by the template",
+		ultimateRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0003", Percent: ptr.Int64(100)},
+		}},
+		ExpectedResult: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := IsNewRevision(test.ultimateRevisionTarget, ro); r != test.ExpectedResult {
+				t.Fatalf("Result of IsNewRevision() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestAdoptTrafficEdit(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(80)},
+		Direction:     v1.DirectionDown,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(20)},
+		Direction:     v1.DirectionUp,
+	}}
+	ultimateRevisionTarget := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(100)},
+	}}
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(100)},
+			}},
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(100)},
+			}},
+			StageTarget: v1.StageTarget{StageTargetRevisions: stage},
+		},
+	}
+	ro.Status.SetStageRevisionStatus(stage)
+
+	AdoptTrafficEdit(ultimateRevisionTarget, ro)
+	if !reflect.DeepEqual(ro.Spec.TargetRevisions, ultimateRevisionTarget) {
+		t.Fatalf("Result of AdoptTrafficEdit() TargetRevisions = %v, want %v", ro.Spec.TargetRevisions,
+			ultimateRevisionTarget)
+	}
+	if !reflect.DeepEqual(ro.Spec.InitialRevisions, stage) {
+		t.Fatalf("Result of AdoptTrafficEdit() InitialRevisions = %v, want %v", ro.Spec.InitialRevisions, stage)
+	}
+	if !reflect.DeepEqual(ro.Spec.StageTargetRevisions, stage) {
+		t.Fatalf("Result of AdoptTrafficEdit() StageTargetRevisions = %v, want %v", ro.Spec.StageTargetRevisions,
+			stage)
+	}
+}
+
+func TestGetUnknownRouteRevisions(t *testing.T) {
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0001", Percent: ptr.Int64(100)},
+			}},
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-0002", Percent: ptr.Int64(100)},
+			}},
+		},
+	}
+	service := &servingv1.Service{
+		Spec: servingv1.ServiceSpec{
+			RouteSpec: servingv1.RouteSpec{
+				Traffic: []servingv1.TrafficTarget{{RevisionName: "rev-0003", Percent: ptr.Int64(0)}},
+			},
+		},
+	}
+	tests := []struct {
+		name           string
+		route          *servingv1.Route
+		ExpectedResult []string
+	}{{
+		name:           "Test without the route",
+		route:          nil,
+		ExpectedResult: nil,
+	}, {
+		name: "Test the route with the traffic of the rollout and the service",
+		route: &servingv1.Route{
+			Spec: servingv1.RouteSpec{
+				Traffic: []servingv1.TrafficTarget{{
+					RevisionName: "rev-0001", Percent: ptr.Int64(60),
+				}, {
+					RevisionName: "rev-0003", Percent: ptr.Int64(40),
+				}, {
+					ConfigurationName: "svc", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(0),
+				}},
+			},
+		},
+		ExpectedResult: nil,
+	}, {
+		name: "Test the route with the traffic edited out of band",
+		route: &servingv1.Route{
+			Spec: servingv1.RouteSpec{
+				Traffic: []servingv1.TrafficTarget{{
+					RevisionName: "rev-0001", Percent: ptr.Int64(60),
+				}, {
+					RevisionName: "rev-0004", Percent: ptr.Int64(40),
+				}, {
+					RevisionName: "rev-0005", Percent: ptr.Int64(0),
+				}},
+			},
+		},
+		ExpectedResult: []string{"rev-0004"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := GetUnknownRouteRevisions(test.route, service, ro); !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of GetUnknownRouteRevisions() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// IsRouteRolloutEnabled returns true, if the Route has opted in to the progressive rollout. Only the standalone
+// Route, which is not controlled by a Knative Service, is rolled out on its own.
+func IsRouteRolloutEnabled(route *servingv1.Route) bool {
+	return route.Annotations[ProgressiveRolloutEnabled] == "true"
+}
+
+// GetRouteTargetTraffic returns the traffic defined by the user for the standalone Route. The spec of the Route
+// is the target, if it has not been rewritten for a stage yet, or it has been edited since the stage was written.
+func GetRouteTargetTraffic(route *servingv1.Route) []servingv1.TrafficTarget {
+	target := decodeTraffic(route.Annotations[RouteTargetTraffic])
+	stage := decodeTraffic(route.Annotations[RouteStageTraffic])
+	if target == nil || !equality.Semantic.DeepEqual(route.Spec.Traffic, stage) {
+		return route.Spec.Traffic
+	}
+	return target
+}
+
+// GetRouteConfigurationName returns the name of the Configuration the traffic follows. The name is empty, if the
+// traffic follows no Configuration, or more than one, which the rollout does not cover.
+func GetRouteConfigurationName(traffic []servingv1.TrafficTarget) string {
+	name := ""
+	for _, target := range traffic {
+		if target.ConfigurationName == "" || target.ConfigurationName == name {
+			continue
+		}
+		if name != "" {
+			return ""
+		}
+		name = target.ConfigurationName
+	}
+	return name
+}
+
+// MakeServiceFromRoute returns the Knative Service equivalent to the standalone Route and its Configuration, with
+// the target traffic, so that the rollout of the Route is calculated the same way as the rollout of a service.
+// The service is never created.
+func MakeServiceFromRoute(route *servingv1.Route, config *servingv1.Configuration,
+	traffic []servingv1.TrafficTarget) *servingv1.Service {
+	return &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        route.Name,
+			Namespace:   route.Namespace,
+			Annotations: route.Annotations,
+		},
+		Spec: servingv1.ServiceSpec{
+			ConfigurationSpec: *config.Spec.DeepCopy(),
+			RouteSpec:         servingv1.RouteSpec{Traffic: traffic},
+		},
+	}
+}
+
+// SetRouteStageTraffic writes the traffic of the stage into the spec of the standalone Route, and keeps the target
+// traffic and the traffic of the stage in the annotations. The traffic of the stage is defaulted the same way as the
+// webhook does, so that it can be compared with the spec read back.
+func SetRouteStageTraffic(ctx context.Context, route *servingv1.Route, target,
+	stage []servingv1.TrafficTarget) error {
+	for i := range stage {
+		// The URL is only allowed in the status of the Route.
+		stage[i].URL = nil
+		stage[i].SetDefaults(ctx)
+	}
+	targetValue, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	stageValue, err := json.Marshal(stage)
+	if err != nil {
+		return err
+	}
+	annotations := maps.Clone(route.Annotations)
+	if annotations == nil {
+		annotations = make(map[string]string, 2)
+	}
+	annotations[RouteTargetTraffic] = string(targetValue)
+	annotations[RouteStageTraffic] = string(stageValue)
+	route.Annotations = annotations
+	route.Spec.Traffic = stage
+	return nil
+}
+
+// ResetRouteTraffic writes the target traffic back into the spec of the standalone Route, and removes the
+// annotations of the rollout. It returns false, if the traffic of the Route has not been rewritten.
+func ResetRouteTraffic(route *servingv1.Route) bool {
+	if _, found := route.Annotations[RouteTargetTraffic]; !found {
+		return false
+	}
+	route.Spec.Traffic = GetRouteTargetTraffic(route)
+	annotations := maps.Clone(route.Annotations)
+	delete(annotations, RouteTargetTraffic)
+	delete(annotations, RouteStageTraffic)
+	route.Annotations = annotations
+	return true
+}
+
+func decodeTraffic(value string) []servingv1.TrafficTarget {
+	if value == "" {
+		return nil
+	}
+	var traffic []servingv1.TrafficTarget
+	if err := json.Unmarshal([]byte(value), &traffic); err != nil {
+		return nil
+	}
+	return traffic
+}
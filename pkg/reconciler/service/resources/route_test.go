@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/ptr"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestGetRouteConfigurationName(t *testing.T) {
+	tests := []struct {
+		name           string
+		traffic        []servingv1.TrafficTarget
+		ExpectedResult string
+	}{{
+		name:           "Test the traffic following one configuration",
+		traffic:        []servingv1.TrafficTarget{{ConfigurationName: "config", Percent: ptr.Int64(100)}},
+		ExpectedResult: "config",
+	}, {
+		name: "Test the traffic following one configuration and a revision",
+		traffic: []servingv1.TrafficTarget{{
+			ConfigurationName: "config", Percent: ptr.Int64(90),
+		}, {
+			RevisionName: "config-00001", Percent: ptr.Int64(10),
+		}},
+		ExpectedResult: "config",
+	}, {
+		name: "Test the traffic following two configurations",
+		traffic: []servingv1.TrafficTarget{{
+			ConfigurationName: "config", Percent: ptr.Int64(90),
+		}, {
+			ConfigurationName: "other", Percent: ptr.Int64(10),
+		}},
+		ExpectedResult: "",
+	}, {
+		name:           "Test the traffic following no configuration",
+		traffic:        []servingv1.TrafficTarget{{RevisionName: "config-00001", Percent: ptr.Int64(100)}},
+		ExpectedResult: "",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := GetRouteConfigurationName(test.traffic); r != test.ExpectedResult {
+				t.Fatalf("Result of GetRouteConfigurationName() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestRouteTraffic(t *testing.T) {
+	target := []servingv1.TrafficTarget{{ConfigurationName: "config", Percent: ptr.Int64(100)}}
+	stage := []servingv1.TrafficTarget{{
+		RevisionName: "config-00001", Percent: ptr.Int64(80),
+	}, {
+		ConfigurationName: "config", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(20),
+	}}
+	route := &servingv1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "default"},
+		Spec:       servingv1.RouteSpec{Traffic: target},
+	}
+
+	// The spec is the target, before it is rewritten.
+	if r := GetRouteTargetTraffic(route); !reflect.DeepEqual(r, target) {
+		t.Fatalf("Result of GetRouteTargetTraffic() = %v, want %v", r, target)
+	}
+
+	if err := SetRouteStageTraffic(context.Background(), route, target, stage); err != nil {
+		t.Fatalf("SetRouteStageTraffic() = %v", err)
+	}
+	defaulted := []servingv1.TrafficTarget{{
+		RevisionName: "config-00001", LatestRevision: ptr.Bool(false), Percent: ptr.Int64(80),
+	}, {
+		ConfigurationName: "config", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(20),
+	}}
+	if !reflect.DeepEqual(route.Spec.Traffic, defaulted) {
+		t.Fatalf("Result of SetRouteStageTraffic() traffic = %v, want %v", route.Spec.Traffic, defaulted)
+	}
+	if r := GetRouteTargetTraffic(route); !reflect.DeepEqual(r, target) {
+		t.Fatalf("Result of GetRouteTargetTraffic() = %v, want %v", r, target)
+	}
+
+	// The traffic edited by the user is the new target.
+	edited := []servingv1.TrafficTarget{{RevisionName: "config-00001", Percent: ptr.Int64(100)}}
+	route.Spec.Traffic = edited
+	if r := GetRouteTargetTraffic(route); !reflect.DeepEqual(r, edited) {
+		t.Fatalf("Result of GetRouteTargetTraffic() = %v, want %v", r, edited)
+	}
+
+	route.Spec.Traffic = defaulted
+	if !ResetRouteTraffic(route) {
+		t.Fatal("ResetRouteTraffic() = false, want true")
+	}
+	if !reflect.DeepEqual(route.Spec.Traffic, target) || len(route.Annotations) != 0 {
+		t.Fatalf("Result of ResetRouteTraffic() = %v, %v, want %v without annotations", route.Spec.Traffic,
+			route.Annotations, target)
+	}
+	if ResetRouteTraffic(route) {
+		t.Fatal("ResetRouteTraffic() = true, want false for the Route not rewritten")
+	}
+}
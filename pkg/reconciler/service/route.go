@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	routereconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/route"
+)
+
+// RouteReconciler rolls out the standalone Routes, which are not controlled by a Knative Service, and have opted in
+// to the progressive rollout with the annotation ProgressiveRolloutEnabled. The Route gets its own
+// RolloutOrchestrator, and the traffic following its Configuration is rewritten stage by stage, the same way
+// TransformService does for the service. The status of the Route is left to the Route reconciler of Knative Serving.
+type RouteReconciler struct {
+	reconciler *Reconciler
+}
+
+// Check that our RouteReconciler implements routereconciler.Interface
+var _ routereconciler.Interface = (*RouteReconciler)(nil)
+
+// NewRouteReconciler creates the RouteReconciler, which shares the rollout logic of the Reconciler.
+func NewRouteReconciler(reconciler *Reconciler) *RouteReconciler {
+	return &RouteReconciler{reconciler: reconciler}
+}
+
+// ReconcileKind implements Interface.ReconcileKind.
+func (r *RouteReconciler) ReconcileKind(ctx context.Context, route *servingv1.Route) pkgreconciler.Event {
+	if metav1.GetControllerOf(route) != nil {
+		// The Route of a Knative Service is rolled out with the service.
+		return nil
+	}
+	c := r.reconciler
+	if err := c.loadRolloutConfig(route.Annotations, route.Annotations); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pkgreconciler.DefaultTimeout)
+	defer cancel()
+
+	original := route.DeepCopy()
+	target := resources.GetRouteTargetTraffic(route)
+	configName := resources.GetRouteConfigurationName(target)
+	if !resources.IsRouteRolloutEnabled(route) || configName == "" {
+		// The Route follows its traffic directly, if it has not opted in, or its traffic does not follow exactly
+		// one Configuration.
+		return r.resetRoute(ctx, route)
+	}
+
+	config, err := c.configurationLister.Configurations(route.Namespace).Get(configName)
+	if apierrs.IsNotFound(err) {
+		// The Route is reconciled again, when the Configuration is created.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	service := resources.MakeServiceFromRoute(route, config, target)
+	ro, err := c.rolloutOrchestrator(ctx, route, service, config)
+	if err != nil {
+		return err
+	}
+
+	stage := target
+	if len(ro.Spec.TargetRevisions) > 0 && (len(target) <= 1 || len(ro.Spec.TargetRevisions) == 1) {
+		// The same as TransformService, the traffic among more than one revision is not covered.
+		stage = convertIntoTrafficTarget(config.Name, ro, c.rolloutConfig, c.spaLister.StagePodAutoscalers(route.Namespace))
+	}
+	if err = resources.SetRouteStageTraffic(ctx, route, target, stage); err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(original.Spec, route.Spec) ||
+		!equality.Semantic.DeepEqual(original.Annotations, route.Annotations) {
+		if _, err = c.servingclient.ServingV1().Routes(route.Namespace).Update(ctx, route,
+			metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return c.checkServiceOrchestratorsReady(ctx, ro, service)
+}
+
+// resetRoute deletes the RolloutOrchestrator of the Route, and writes the target traffic back into its spec.
+func (r *RouteReconciler) resetRoute(ctx context.Context, route *servingv1.Route) error {
+	c := r.reconciler
+	if err := c.deleteRolloutOrchestrator(ctx, route); err != nil {
+		return err
+	}
+	if !resources.ResetRouteTraffic(route) {
+		return nil
+	}
+	_, err := c.servingclient.ServingV1().Routes(route.Namespace).Update(ctx, route, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
+	_ "knative.dev/pkg/system/testing"
+	fakeclientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned/fake"
+	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	"knative.dev/serving/pkg/apis/serving"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	fakeservingclientset "knative.dev/serving/pkg/client/clientset/versioned/fake"
+	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
+)
+
+func newRouteReconciler(route *servingv1.Route, objs ...*servingv1.Configuration) (*RouteReconciler,
+	*fakeclientset.Clientset, *fakeservingclientset.Clientset) {
+	client := fakeclientset.NewSimpleClientset()
+	servingClient := fakeservingclientset.NewSimpleClientset(route)
+	routeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	routeIndexer.Add(route)
+	configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, obj := range objs {
+		configIndexer.Add(obj)
+	}
+	emptyIndexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	}
+	c := &Reconciler{
+		client:                    client,
+		servingclient:             servingClient,
+		configurationLister:       servinglisters.NewConfigurationLister(configIndexer),
+		routeLister:               servinglisters.NewRouteLister(routeIndexer),
+		revisionLister:            servinglisters.NewRevisionLister(emptyIndexer()),
+		rolloutOrchestratorLister: listers.NewRolloutOrchestratorLister(emptyIndexer()),
+		podAutoscalerLister:       palisters.NewPodAutoscalerLister(emptyIndexer()),
+		spaLister:                 listers.NewStagePodAutoscalerLister(emptyIndexer()),
+		configmapLister:           corev1listers.NewConfigMapLister(emptyIndexer()),
+		enqueueAfter:              func(interface{}, time.Duration) {},
+	}
+	return NewRouteReconciler(c), client, servingClient
+}
+
+func TestRouteReconcilerRollout(t *testing.T) {
+	config := &servingv1.Configuration{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default", Generation: 1},
+	}
+	route := &servingv1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "route",
+			Namespace:   "default",
+			UID:         "uid",
+			Annotations: map[string]string{resources.ProgressiveRolloutEnabled: "true"},
+		},
+		Spec: servingv1.RouteSpec{Traffic: []servingv1.TrafficTarget{{
+			ConfigurationName: "config", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(100),
+		}}},
+	}
+	r, client, servingClient := newRouteReconciler(route, config)
+	ctx := controller.WithEventRecorder(context.Background(), record.NewFakeRecorder(10))
+	if err := r.ReconcileKind(ctx, route.DeepCopy()); err != nil {
+		t.Fatalf("ReconcileKind() = %v", err)
+	}
+
+	ro, err := client.ServingV1().RolloutOrchestrators("default").Get(ctx, "route", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Result of ReconcileKind() RolloutOrchestrator error = %v", err)
+	}
+	if !metav1.IsControlledBy(ro, route) || ro.Labels[serving.RouteLabelKey] != "route" {
+		t.Fatalf("Result of ReconcileKind() RolloutOrchestrator = %v, want it owned by the route", ro.ObjectMeta)
+	}
+	if got, want := ro.Spec.TargetRevisions[0].RevisionName, "config-00001"; got != want {
+		t.Fatalf("Result of ReconcileKind() target revision = %v, want %v", got, want)
+	}
+
+	updated, err := servingClient.ServingV1().Routes("default").Get(ctx, "route", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Result of ReconcileKind() Route error = %v", err)
+	}
+	if _, found := updated.Annotations[resources.RouteTargetTraffic]; !found {
+		t.Fatalf("Result of ReconcileKind() Route annotations = %v, want %s", updated.Annotations,
+			resources.RouteTargetTraffic)
+	}
+	if r := resources.GetRouteTargetTraffic(updated); !reflect.DeepEqual(r, route.Spec.Traffic) {
+		t.Fatalf("Result of ReconcileKind() target traffic = %v, want %v", r, route.Spec.Traffic)
+	}
+}
+
+func TestRouteReconcilerReset(t *testing.T) {
+	target := []servingv1.TrafficTarget{{
+		ConfigurationName: "config", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(100),
+	}}
+	route := &servingv1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "default", UID: "uid"},
+		Spec:       servingv1.RouteSpec{Traffic: target},
+	}
+	if err := resources.SetRouteStageTraffic(context.Background(), route, target, []servingv1.TrafficTarget{{
+		RevisionName: "config-00001", Percent: ptr.Int64(50),
+	}, {
+		ConfigurationName: "config", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(50),
+	}}); err != nil {
+		t.Fatalf("SetRouteStageTraffic() = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		owned          bool
+		ExpectedResult []servingv1.TrafficTarget
+	}{{
+		name:           "Test the route opted out in the middle of the rollout",
+		ExpectedResult: target,
+	}, {
+		name:           "Test the route of a service",
+		owned:          true,
+		ExpectedResult: route.Spec.Traffic,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route := route.DeepCopy()
+			if test.owned {
+				route.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(&servingv1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "svc", UID: "svc-uid"},
+				})}
+			}
+			r, _, servingClient := newRouteReconciler(route)
+			ctx := controller.WithEventRecorder(context.Background(), record.NewFakeRecorder(10))
+			if err := r.ReconcileKind(ctx, route.DeepCopy()); err != nil {
+				t.Fatalf("ReconcileKind() = %v", err)
+			}
+			updated, err := servingClient.ServingV1().Routes("default").Get(ctx, "route", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Result of ReconcileKind() Route error = %v", err)
+			}
+			if !reflect.DeepEqual(updated.Spec.Traffic, test.ExpectedResult) {
+				t.Fatalf("Result of ReconcileKind() traffic = %v, want %v", updated.Spec.Traffic, test.ExpectedResult)
+			}
+		})
+	}
+}
@@ -19,21 +19,27 @@ package service
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math"
 	"reflect"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/ptr"
@@ -46,9 +52,12 @@ import (
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator/strategies"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	"knative.dev/serving-progressive-rollout/pkg/tracing"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	cfgmap "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
 	servingclientset "knative.dev/serving/pkg/client/clientset/versioned"
 	ksvcreconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/service"
 	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
@@ -74,11 +83,24 @@ type Reconciler struct {
 	enqueueAfter              func(interface{}, time.Duration)
 
 	rolloutConfig *RolloutConfig
+
+	// spans holds the spans of the rollouts and the stages in progress, until they finish.
+	spans *tracing.Spans
+
+	// analysis holds the Analyzer querying the metrics of the stages, until the rollouts finish.
+	analysis stageAnalysis
 }
 
 // Check that our Reconciler implements ksvcreconciler.Interface
 var _ ksvcreconciler.Interface = (*Reconciler)(nil)
 
+// rolloutOwner is the object owning the RolloutOrchestrator: the Knative Service, or the standalone Route rolled out
+// progressively. The Events about the rollout are recorded on it.
+type rolloutOwner interface {
+	kmeta.OwnerRefable
+	runtime.Object
+}
+
 // NewReconciler creates the reference to the Reconciler based on servingclientset.Interface,
 // servinglisters.ConfigurationLister, servinglisters.RevisionLister, servinglisters.RouteLister,
 // listers.RolloutOrchestratorLister and palisters.PodAutoscalerLister.
@@ -105,30 +127,21 @@ func NewReconciler(prclient clientset.Interface, client servingclientset.Interfa
 		spaLister:                 spaLister,
 		configmapLister:           configmapLister,
 		deploymentLister:          deploymentLister,
+		spans:                     tracing.NewSpans(),
 	}
 }
 
+// SetEnqueueAfter sets the function to reconcile the service again after a delay.
+func (c *Reconciler) SetEnqueueAfter(enqueueAfter func(interface{}, time.Duration)) {
+	c.enqueueAfter = enqueueAfter
+}
+
 // ReconcileKind implements Interface.ReconcileKind.
 func (c *Reconciler) ReconcileKind(ctx context.Context, service *servingv1.Service) pkgreconciler.Event {
-	// Read the configuration in the configMap config-rolloutorchestrator.
-	cm, err := c.configmapLister.ConfigMaps(system.Namespace()).Get(resources.ConfigMapName)
-	if err != nil && !apierrs.IsNotFound(err) {
-		return err
-	}
-
-	cmN, errN := c.configmapLister.ConfigMaps(system.Namespace()).Get(resources.ConfigMapNetworkName)
-	if errN != nil && !apierrs.IsNotFound(errN) {
-		return errN
-	}
-
-	// Load the configuration into the struct.
-	if c.rolloutConfig, err = NewConfigFromConfigMapFunc(cm, cmN); err != nil {
+	if err := c.loadRolloutConfig(service.Spec.Template.Annotations, service.Annotations); err != nil {
 		return err
 	}
 
-	// Check configuration in the service's annotation for possible overriding.
-	LoadConfigFromService(service.Spec.Template.Annotations, service.Annotations, c.rolloutConfig)
-
 	// Initialize the configuration first.
 	ctx, cancel := context.WithTimeout(ctx, pkgreconciler.DefaultTimeout)
 	defer cancel()
@@ -156,8 +169,17 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, service *servingv1.Servi
 		service.Status.PropagateConfigurationStatus(&config.Status)
 	}
 
+	if !c.rolloutConfig.ProgressiveRolloutEnabled && c.rolloutConfig.DeleteRolloutOnDisable {
+		// The progressive rollout is disabled, and its resources are not kept. The route follows the traffic of the
+		// service directly.
+		if err = c.deleteRolloutOrchestrator(ctx, service); err != nil {
+			return err
+		}
+		return c.baseReconciler.ReconcileKind(ctx, service)
+	}
+
 	// Based on the information in the CR service, we create or update the content of the CR RolloutOrchestrator.
-	rolloutOrchestrator, err := c.rolloutOrchestrator(ctx, service, config)
+	rolloutOrchestrator, err := c.rolloutOrchestrator(ctx, service, service, config)
 	if err != nil {
 		return err
 	}
@@ -170,6 +192,29 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, service *servingv1.Servi
 	return c.checkServiceOrchestratorsReady(ctx, rolloutOrchestrator, service)
 }
 
+// loadRolloutConfig reads the configuration in the configMap config-rolloutorchestrator, overridden by the
+// annotations.
+func (c *Reconciler) loadRolloutConfig(templateAnnotations, annotations map[string]string) error {
+	cm, err := c.configmapLister.ConfigMaps(system.Namespace()).Get(resources.ConfigMapName)
+	if err != nil && !apierrs.IsNotFound(err) {
+		return err
+	}
+
+	cmN, errN := c.configmapLister.ConfigMaps(system.Namespace()).Get(resources.ConfigMapNetworkName)
+	if errN != nil && !apierrs.IsNotFound(errN) {
+		return errN
+	}
+
+	// Load the configuration into the struct.
+	if c.rolloutConfig, err = NewConfigFromConfigMapFunc(cm, cmN); err != nil {
+		return err
+	}
+
+	// Check configuration in the annotations for possible overriding.
+	LoadConfigFromService(templateAnnotations, annotations, c.rolloutConfig)
+	return nil
+}
+
 func (c *Reconciler) config(ctx context.Context, service *servingv1.Service) (*servingv1.Configuration, error) {
 	recorder := controller.GetEventRecorder(ctx)
 	configName := resourcenames.Configuration(service)
@@ -240,8 +285,10 @@ func (c *Reconciler) reconcileConfiguration(ctx context.Context, service *servin
 	return c.servingclient.ServingV1().Configurations(service.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
 }
 
-// rolloutOrchestrator implements logic to create or update the CR RolloutOrchestrator.
-func (c *Reconciler) rolloutOrchestrator(ctx context.Context, service *servingv1.Service, config *servingv1.Configuration) (*v1.RolloutOrchestrator, error) {
+// rolloutOrchestrator implements logic to create or update the CR RolloutOrchestrator. The service describes the
+// rollout, and is the owner itself, unless a standalone Route is rolled out.
+func (c *Reconciler) rolloutOrchestrator(ctx context.Context, owner rolloutOwner, service *servingv1.Service,
+	config *servingv1.Configuration) (*v1.RolloutOrchestrator, error) {
 	recorder := controller.GetEventRecorder(ctx)
 	// The information in the CR Route is also leveraged as the input to the RolloutOrchestrator.
 	route, err := c.routeLister.Routes(service.Namespace).Get(resourcenames.Route(service))
@@ -254,48 +301,104 @@ func (c *Reconciler) rolloutOrchestrator(ctx context.Context, service *servingv1
 	rolloutOrchestrator, err := c.rolloutOrchestratorLister.RolloutOrchestrators(service.Namespace).Get(roName)
 	if apierrs.IsNotFound(err) {
 		// Create the CR RolloutOrchestrator.
-		rolloutOrchestrator, err = c.createRolloutOrchestrator(ctx, service, config, route)
+		rolloutOrchestrator, err = c.createRolloutOrchestrator(ctx, owner, service, config, route)
 		if err != nil {
-			recorder.Eventf(service, corev1.EventTypeWarning, "CreationFailed",
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CreationFailed",
 				"failed to create RolloutOrchestrator %q: %v", roName, err)
 			return nil, fmt.Errorf("failed to create RolloutOrchestrator: %w", err)
 		}
-		recorder.Eventf(service, corev1.EventTypeNormal, "Created",
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created",
 			"created RolloutOrchestrator %q", roName)
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to get RolloutOrchestrator: %w", err)
-	} else if !metav1.IsControlledBy(rolloutOrchestrator, service) {
+	} else if !metav1.IsControlledBy(rolloutOrchestrator, owner.GetObjectMeta()) {
 		// TODO Surface an error in the service's status, and return an error.
-		return nil, fmt.Errorf("service: %q does not own the RolloutOrchestrator: %q", service.Name, roName)
-	} else if err = c.reconcileRolloutOrchestrator(ctx, service, config, route,
-		rolloutOrchestrator, c.deploymentLister); err != nil {
-		return nil, fmt.Errorf("failed to reconcile RolloutOrchestrator: %w", err)
+		return nil, fmt.Errorf("%q does not own the RolloutOrchestrator: %q", service.Name, roName)
+	} else {
+		c.handleRouteTrafficEdit(ctx, owner, service, route, rolloutOrchestrator)
+		if err = c.reconcileRolloutOrchestrator(ctx, owner, service, config, route,
+			rolloutOrchestrator, c.deploymentLister); err != nil {
+			return nil, fmt.Errorf("failed to reconcile RolloutOrchestrator: %w", err)
+		}
 	}
 
 	return rolloutOrchestrator, nil
 }
 
+// deleteRolloutOrchestrator deletes the StagePodAutoscalers and the RolloutOrchestrator of the service, or of the
+// standalone Route.
+func (c *Reconciler) deleteRolloutOrchestrator(ctx context.Context, owner rolloutOwner) error {
+	recorder := controller.GetEventRecorder(ctx)
+	meta := owner.GetObjectMeta()
+	ro, err := c.rolloutOrchestratorLister.RolloutOrchestrators(meta.GetNamespace()).Get(meta.GetName())
+	if apierrs.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get RolloutOrchestrator: %w", err)
+	} else if !metav1.IsControlledBy(ro, meta) {
+		return fmt.Errorf("%q does not own the RolloutOrchestrator: %q", meta.GetName(), ro.Name)
+	}
+
+	// The StagePodAutoscalers are deleted before the RolloutOrchestrator, so that they stop limiting the revisions
+	// right away, instead of waiting for the garbage collection.
+	// The StagePodAutoscalers are labeled with the name of the RolloutOrchestrator.
+	spaList, err := c.spaLister.StagePodAutoscalers(ro.Namespace).List(labels.SelectorFromSet(labels.Set{
+		serving.ServiceLabelKey: ro.Name,
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to list StagePodAutoscalers: %w", err)
+	}
+	for _, spa := range spaList {
+		err = c.client.ServingV1().StagePodAutoscalers(ro.Namespace).Delete(ctx, spa.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete StagePodAutoscaler: %w", err)
+		}
+	}
+
+	err = c.client.ServingV1().RolloutOrchestrators(ro.Namespace).Delete(ctx, ro.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete RolloutOrchestrator: %w", err)
+	}
+	recorder.Eventf(owner, corev1.EventTypeNormal, "Deleted",
+		"deleted RolloutOrchestrator %q, because the progressive rollout is disabled", ro.Name)
+	return nil
+}
+
 // getRecordsFromRevs generates the map of RevisionRecord from all revisions for one knative service.
-func (c *Reconciler) getRecordsFromRevs(service *servingv1.Service) map[string]resources.RevisionRecord {
+func (c *Reconciler) getRecordsFromRevs(ctx context.Context, service *servingv1.Service,
+	config *servingv1.Configuration) map[string]resources.RevisionRecord {
 	records := map[string]resources.RevisionRecord{}
-	// Get the list of all the revisions for the knative service.
+	asConfig := cfgmap.FromContextOrDefaults(ctx).Autoscaler
+	// Get the list of all the revisions for the configuration of the knative service.
 	revList, err := c.revisionLister.Revisions(service.Namespace).List(labels.SelectorFromSet(labels.Set{
-		serving.ConfigurationLabelKey: service.Name,
-		serving.ServiceLabelKey:       service.Name,
+		serving.ConfigurationLabelKey: config.Name,
 	}))
 
 	if err == nil && len(revList) > 0 {
 		// Convert the list of revisions into a map of revision records, that keep the information of
-		// minScale & maxScale configured in the service, and the revision name.
-		records = CreateRevRecordsFromRevList(revList)
+		// minScale & maxScale configured in the service, the capacity of one replica, and the revision name.
+		records = CreateRevRecordsFromRevList(revList, asConfig)
+	}
+
+	// The latest revision may not have been created yet. Its record is generated from the template of the service,
+	// so that its capacity is known when the first stage is calculated.
+	lastRevName := kmeta.ChildName(config.Name, fmt.Sprintf("-%05d", config.Generation))
+	if _, found := records[lastRevName]; !found {
+		records[lastRevName] = resources.RevisionRecord{
+			Name:         lastRevName,
+			MinScale:     resources.ReadIntServiceAnnotation(service, autoscaling.MinScaleAnnotationKey),
+			MaxScale:     resources.ReadIntServiceAnnotation(service, autoscaling.MaxScaleAnnotationKey),
+			PodCapacity:  resources.GetPodCapacity(service.Spec.Template.Annotations, &service.Spec.Template.Spec, asConfig),
+			PodResources: resources.GetPodResources(&service.Spec.Template.Spec),
+		}
 	}
 	return records
 }
 
 // createRolloutOrchestrator creates the CR RolloutOrchestrator.
-func (c *Reconciler) createRolloutOrchestrator(ctx context.Context, service *servingv1.Service,
+func (c *Reconciler) createRolloutOrchestrator(ctx context.Context, owner rolloutOwner, service *servingv1.Service,
 	config *servingv1.Configuration, route *servingv1.Route) (*v1.RolloutOrchestrator, error) {
-	records := c.getRecordsFromRevs(service)
+	records := c.getRecordsFromRevs(ctx, service, config)
 	// Based on the knative service, the map of the revision records and the route, we can get the initial target
 	// revisions and the final target revisions. The initial target revisions define the start, and the final target
 	// revisions define the end for the upgrade.
@@ -310,7 +413,7 @@ func (c *Reconciler) createRolloutOrchestrator(ctx context.Context, service *ser
 
 	// Assign the RolloutOrchestrator with the initial target revision, and final target revision.
 	// StageTargetRevisions in the spec is nil.
-	ro := resources.NewInitialFinalTargetRev(initialRevisionStatus, ultimateRevisionTarget, service)
+	ro := resources.NewInitialFinalTargetRev(initialRevisionStatus, ultimateRevisionTarget, owner)
 
 	// updateRolloutOrchestrator updates the StageRevisionTarget as the new(next) target.
 	err := updateRolloutOrchestrator(ro, c.podAutoscalerLister.PodAutoscalers(ro.Namespace),
@@ -323,10 +426,10 @@ func (c *Reconciler) createRolloutOrchestrator(ctx context.Context, service *ser
 }
 
 // reconcileRolloutOrchestrator reconciles the CR RolloutOrchestrator.
-func (c *Reconciler) reconcileRolloutOrchestrator(ctx context.Context, service *servingv1.Service,
+func (c *Reconciler) reconcileRolloutOrchestrator(ctx context.Context, owner rolloutOwner, service *servingv1.Service,
 	config *servingv1.Configuration, route *servingv1.Route, ro *v1.RolloutOrchestrator,
 	deploymentLister appsv1listers.DeploymentLister) error {
-	records := c.getRecordsFromRevs(service)
+	records := c.getRecordsFromRevs(ctx, service, config)
 
 	// Based on the knative service, the map of the revision records and the route, we can get the final target
 	// revisions. The final target revisions define the end for the upgrade.
@@ -334,6 +437,12 @@ func (c *Reconciler) reconcileRolloutOrchestrator(ctx context.Context, service *
 
 	existingROSpec := ro.Spec.DeepCopy()
 
+	// If the traffic of the service has been edited in the middle of the rollout, handle it with the policy.
+	ultimateRevisionTarget = c.handleTrafficEdit(ctx, owner, service, ro, ultimateRevisionTarget)
+
+	// If a new revision has been created in the middle of the rollout, handle it with the superseding policy.
+	ultimateRevisionTarget, held := c.handleNewRevision(ctx, owner, service, config, ro, ultimateRevisionTarget)
+
 	// Assign the RolloutOrchestrator with the final target revision and reset StageTargetRevisions in the spec,
 	// if the final target revision is different from the existing final target revision.
 	resources.UpdateInitialFinalTargetRev(ultimateRevisionTarget, ro, route, deploymentLister)
@@ -344,24 +453,303 @@ func (c *Reconciler) reconcileRolloutOrchestrator(ctx context.Context, service *
 	if err != nil {
 		return err
 	}
+	if held {
+		// The traffic of the rollout in progress must not follow the new revision waiting for its rollout.
+		pinRevisions(ro)
+	}
+
+	// Every new stage is traced as a span of the trace of the rollout.
+	existingAnnotations := ro.Annotations
+	if !reflect.DeepEqual(existingROSpec.StageTargetRevisions, ro.Spec.StageTargetRevisions) {
+		newRollout := !reflect.DeepEqual(existingROSpec.TargetRevisions, ro.Spec.TargetRevisions)
+		if newRollout {
+			c.analysis.forget(ro.Namespace, ro.Name)
+		} else if ro.IsStageReady() {
+			// The ready stage has finished, and the next stage starts.
+			c.analyzeStage(ctx, owner, &v1.RolloutOrchestrator{ObjectMeta: ro.ObjectMeta, Spec: *existingROSpec})
+		}
+		traceStage(ctx, c.spans, ro, newRollout)
+	} else if ro.IsLastStageComplete() &&
+		rolloutorchestrator.LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions) {
+		// The last stage has finished, and so has the rollout.
+		c.spans.End(ro.Annotations[tracing.StageTraceParentAnnotation])
+		c.spans.End(ro.Annotations[tracing.TraceParentAnnotation])
+		c.analysis.forget(ro.Namespace, ro.Name)
+	}
 
 	// If the new ro.Spec is not equal to the existing ro.Spec, we update the RO.
 	if !reflect.DeepEqual(existingROSpec, ro.Spec) {
 		_, err = c.client.ServingV1().RolloutOrchestrators(service.Namespace).Update(ctx, ro, metav1.UpdateOptions{})
 		if err != nil {
+			// The spans started for the stage are not saved, so they end now. The stage is traced again on retry.
+			for _, key := range []string{tracing.StageTraceParentAnnotation, tracing.TraceParentAnnotation} {
+				if ro.Annotations[key] != existingAnnotations[key] {
+					c.spans.End(ro.Annotations[key])
+				}
+			}
 			return err
 		}
 	}
 	return nil
 }
 
+// traceStage starts the span of the new stage of the RolloutOrchestrator, and saves its trace context in the
+// annotations, so that the steps of the stage and the scaling decisions they cause are traced as its children.
+// The trace of a new rollout starts with the span of the rollout. The span of the previous stage, and of the
+// previous rollout for a new one, end now.
+func traceStage(ctx context.Context, spans *tracing.Spans, ro *v1.RolloutOrchestrator, newRollout bool) {
+	annotations := maps.Clone(ro.Annotations)
+	if annotations == nil {
+		annotations = make(map[string]string, 3)
+	}
+	spans.End(annotations[tracing.StageTraceParentAnnotation])
+	if _, found := annotations[tracing.TraceParentAnnotation]; newRollout || !found {
+		spans.End(annotations[tracing.TraceParentAnnotation])
+		rolloutCtx, span := tracing.Tracer().Start(ctx, "rollout", trace.WithNewRoot(), trace.WithAttributes(
+			attribute.String("namespace", ro.Namespace), attribute.String("name", ro.Name),
+			attribute.String("target", resources.TargetRevisionNames(ro))))
+		traceID := ""
+		if span.SpanContext().IsValid() {
+			traceID = span.SpanContext().TraceID().String()
+		}
+		tracing.SetAnnotation(annotations, tracing.TraceIDAnnotation, traceID)
+		tracing.SetAnnotation(annotations, tracing.TraceParentAnnotation, spans.Hold(rolloutCtx, span))
+	}
+
+	stage := make([]string, 0, len(ro.Spec.StageTargetRevisions))
+	for _, rev := range ro.Spec.StageTargetRevisions {
+		stage = append(stage, fmt.Sprintf("%s=%d%%", rev.RevisionName, ptr.Int64Value(rev.Percent)))
+	}
+	stageCtx, span := tracing.Start(ctx, annotations, tracing.TraceParentAnnotation, "stage",
+		trace.WithAttributes(attribute.StringSlice("stage", stage)))
+	tracing.SetAnnotation(annotations, tracing.StageTraceParentAnnotation, spans.Hold(stageCtx, span))
+	ro.Annotations = annotations
+}
+
+// handleTrafficEdit applies the TrafficEditPolicy, if the traffic of the service has been edited in the middle of
+// a rollout, and records an Event for it on the owner. It returns the target revisions the rollout goes on with.
+func (c *Reconciler) handleTrafficEdit(ctx context.Context, owner rolloutOwner, service *servingv1.Service,
+	ro *v1.RolloutOrchestrator, ultimateRevisionTarget []v1.TargetRevision) []v1.TargetRevision {
+	conditions := service.GetConditionSet().Manage(&service.Status)
+	inRollout := len(ro.Spec.StageTargetRevisions) != 0 &&
+		!rolloutorchestrator.LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions)
+	if !inRollout || !resources.IsTrafficEdit(ultimateRevisionTarget, ro) {
+		// There is no edited traffic pending, so nothing is rejected.
+		_ = conditions.ClearCondition(resources.TrafficEditAccepted)
+		return ultimateRevisionTarget
+	}
+
+	recorder := controller.GetEventRecorder(ctx)
+	switch strings.ToLower(c.rolloutConfig.TrafficEditPolicy) {
+	case resources.TrafficEditPolicyAdopt:
+		resources.AdoptTrafficEdit(ultimateRevisionTarget, ro)
+		recorder.Eventf(owner, corev1.EventTypeNormal, "TrafficEditAdopted",
+			"adopted the traffic edited in the middle of the rollout of RolloutOrchestrator %q as the new target",
+			ro.Name)
+	case resources.TrafficEditPolicyReject:
+		// The Event is only recorded the first time the edited traffic is rejected.
+		if cond := service.Status.GetCondition(resources.TrafficEditAccepted); cond == nil || !cond.IsFalse() {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "TrafficEditRejected",
+				"rejected the traffic edited in the middle of the rollout of RolloutOrchestrator %q", ro.Name)
+		}
+		conditions.MarkFalse(resources.TrafficEditAccepted, "RolloutInProgress",
+			"The traffic edited in the middle of the rollout is rolled out after the current rollout completes.")
+		return ro.Spec.TargetRevisions
+	default:
+		recorder.Eventf(owner, corev1.EventTypeNormal, "TrafficEditRestarted",
+			"restarted the rollout of RolloutOrchestrator %q from the current traffic split to the edited traffic",
+			ro.Name)
+	}
+	_ = conditions.ClearCondition(resources.TrafficEditAccepted)
+	return ultimateRevisionTarget
+}
+
+// handleRouteTrafficEdit records an Event on the service, when the traffic of its route is found edited out of band
+// to the revisions unknown to the service and the rollout. The route is owned by the service, so its traffic is set
+// back to the traffic of the rollout. The Event is only recorded the first time, until the route is reverted.
+func (c *Reconciler) handleRouteTrafficEdit(ctx context.Context, owner rolloutOwner, service *servingv1.Service,
+	route *servingv1.Route, ro *v1.RolloutOrchestrator) {
+	if _, ownedByService := owner.(*servingv1.Service); !ownedByService {
+		return
+	}
+	conditions := service.GetConditionSet().Manage(&service.Status)
+	unknown := resources.GetUnknownRouteRevisions(route, service, ro)
+	if len(unknown) == 0 {
+		_ = conditions.ClearCondition(resources.RouteTrafficInSync)
+		return
+	}
+	if cond := service.Status.GetCondition(resources.RouteTrafficInSync); cond == nil || !cond.IsFalse() {
+		controller.GetEventRecorder(ctx).Eventf(service, corev1.EventTypeWarning, "RouteTrafficEditReverted",
+			"reverted the traffic of the route edited out of band to the revisions %v", unknown)
+	}
+	conditions.MarkFalse(resources.RouteTrafficInSync, "RouteTrafficEditReverted",
+		"The traffic of the route edited out of band to the revisions %v is reverted.", unknown)
+}
+
+// handleNewRevision applies the SupersedingPolicy, if a new revision has been created in the middle of a rollout,
+// records the policy applied in the RolloutOrchestrator and an Event for it on the owner. It returns the target
+// revisions the rollout goes on with, and whether the new revision is held back.
+func (c *Reconciler) handleNewRevision(ctx context.Context, owner rolloutOwner, service *servingv1.Service,
+	config *servingv1.Configuration, ro *v1.RolloutOrchestrator,
+	ultimateRevisionTarget []v1.TargetRevision) ([]v1.TargetRevision, bool) {
+	_, groupRollback := ro.Annotations[resources.RolloutGroupRollback]
+	inRollout := len(ro.Spec.StageTargetRevisions) != 0 &&
+		!rolloutorchestrator.LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions) &&
+		!groupRollback && !resources.IsRolloutAborted(ro) && !resources.IsRolloutReverted(ro)
+	if !inRollout {
+		// The superseding only applies to the rollout in progress. The rollout going back to the initial
+		// revisions is always superseded.
+		ro.Spec.Superseding = nil
+		c.markLatestRevisionRollout(service, ro)
+		return ultimateRevisionTarget, false
+	}
+	newRevision := resources.GetNewRevisionName(ultimateRevisionTarget, ro)
+	if newRevision == "" {
+		// There is no new revision waiting for the rollout in progress.
+		c.markLatestRevisionRollout(service, ro)
+		return ultimateRevisionTarget, false
+	}
+
+	recorder := controller.GetEventRecorder(ctx)
+	previous := ro.Spec.Superseding
+	policy := strings.ToLower(c.rolloutConfig.SupersedingPolicy)
+	switch policy {
+	case resources.SupersedingPolicyQueue:
+		ro.Spec.Superseding = &v1.RolloutSuperseding{Policy: policy, Revision: newRevision}
+		// The Event is only recorded the first time the new revision is queued.
+		if !reflect.DeepEqual(previous, ro.Spec.Superseding) {
+			recorder.Eventf(owner, corev1.EventTypeNormal, "RolloutQueued",
+				"queued the rollout of the new revision after the rollout of RolloutOrchestrator %q", ro.Name)
+		}
+		c.markLatestRevisionRollout(service, ro)
+		return ro.Spec.TargetRevisions, true
+	case resources.SupersedingPolicyDebounce:
+		if wait := c.getDebounceWait(config.Namespace, newRevision, time.Now()); wait > 0 {
+			ro.Spec.Superseding = &v1.RolloutSuperseding{Policy: policy, Revision: newRevision}
+			if !reflect.DeepEqual(previous, ro.Spec.Superseding) {
+				recorder.Eventf(owner, corev1.EventTypeNormal, "RolloutDebounced",
+					"debounced the rollout of the new revision in the middle of the rollout of RolloutOrchestrator %q",
+					ro.Name)
+			}
+			c.markLatestRevisionRollout(service, ro)
+			// The service is reconciled again, when the debounce period is over.
+			c.enqueueAfter(service, wait)
+			return ro.Spec.TargetRevisions, true
+		}
+	default:
+		policy = resources.SupersedingPolicySupersede
+	}
+	// The revision the superseded rollout was going to is scaled down first, since it has received the least
+	// verification among the revisions serving the traffic.
+	superseded := ""
+	if len(ro.Spec.TargetRevisions) != 0 {
+		superseded = ro.Spec.TargetRevisions[0].RevisionName
+	}
+	ro.Spec.Superseding = &v1.RolloutSuperseding{Policy: policy, Revision: newRevision,
+		SupersededRevision: superseded}
+	recorder.Eventf(owner, corev1.EventTypeNormal, "RolloutSuperseded",
+		"superseded the rollout of RolloutOrchestrator %q with the new revision", ro.Name)
+	c.markLatestRevisionRollout(service, ro)
+	return ultimateRevisionTarget, false
+}
+
+// markLatestRevisionRollout sets the condition LatestRevisionRollout of the service to the superseding policy
+// recorded in the RolloutOrchestrator, and clears it, if there is none.
+func (c *Reconciler) markLatestRevisionRollout(service *servingv1.Service, ro *v1.RolloutOrchestrator) {
+	conditions := service.GetConditionSet().Manage(&service.Status)
+	superseding := ro.Spec.Superseding
+	switch {
+	case superseding == nil:
+		_ = conditions.ClearCondition(resources.LatestRevisionRollout)
+	case superseding.SupersededRevision != "":
+		conditions.MarkTrueWithReason(resources.LatestRevisionRollout, "Superseded",
+			"The new revision %q superseded the rollout of the revision %q, which is scaled down first.",
+			superseding.Revision, superseding.SupersededRevision)
+	case superseding.Policy == resources.SupersedingPolicyDebounce:
+		conditions.MarkFalse(resources.LatestRevisionRollout, "Debounced",
+			"The new revision is rolled out, once no further revision has been created for %d seconds.",
+			c.rolloutConfig.SupersedingDebounceSeconds)
+	default:
+		conditions.MarkFalse(resources.LatestRevisionRollout, "Queued",
+			"The new revision is rolled out after the current rollout completes.")
+	}
+}
+
+// drainSupersededFirst reorders the start revisions of the stage, so that the revision the superseded rollout was
+// going to is the next one to scale down. The revisions scale down from the end of the list, and the target
+// revision is kept last, so that the revision right before it scales down first.
+func drainSupersededFirst(ro *v1.RolloutOrchestrator, startRevisions []v1.TargetRevision) []v1.TargetRevision {
+	if ro.Spec.Superseding == nil || ro.Spec.Superseding.SupersededRevision == "" || len(ro.Spec.TargetRevisions) == 0 {
+		return startRevisions
+	}
+	supersededName := ro.Spec.Superseding.SupersededRevision
+	targetName := ro.Spec.TargetRevisions[0].RevisionName
+	var superseded, target *v1.TargetRevision
+	result := make([]v1.TargetRevision, 0, len(startRevisions))
+	for i := range startRevisions {
+		switch startRevisions[i].RevisionName {
+		case supersededName:
+			superseded = &startRevisions[i]
+		case targetName:
+			target = &startRevisions[i]
+		default:
+			result = append(result, startRevisions[i])
+		}
+	}
+	if superseded == nil {
+		// The superseded revision has already been scaled down.
+		return startRevisions
+	}
+	result = append(result, *superseded)
+	if target != nil {
+		result = append(result, *target)
+	}
+	return result
+}
+
+// pinRevisions refers to the target revisions and the revisions of the current stage of the RolloutOrchestrator by
+// their names, instead of following the latest revision of the configuration.
+func pinRevisions(ro *v1.RolloutOrchestrator) {
+	pin := func(revs []v1.TargetRevision) []v1.TargetRevision {
+		pinned := make([]v1.TargetRevision, 0, len(revs))
+		for _, rev := range revs {
+			rev = *rev.DeepCopy()
+			if rev.LatestRevision != nil && *rev.LatestRevision {
+				rev.LatestRevision = ptr.Bool(false)
+			}
+			pinned = append(pinned, rev)
+		}
+		return pinned
+	}
+	ro.Spec.TargetRevisions = pin(ro.Spec.TargetRevisions)
+	if ro.Spec.StageTargetRevisions != nil {
+		ro.Spec.StageTargetRevisions = pin(ro.Spec.StageTargetRevisions)
+	}
+}
+
+// getDebounceWait returns how long the new revision still waits for further revisions, before the debounce policy
+// rolls it out. The revision is looked up by its name, since the name may be chosen by the user. The revision not
+// in the lister yet waits for the whole debounce period.
+func (c *Reconciler) getDebounceWait(namespace, revisionName string, now time.Time) time.Duration {
+	created := now
+	if rev, err := c.revisionLister.Revisions(namespace).Get(revisionName); err == nil {
+		created = rev.CreationTimestamp.Time
+	}
+	release := created.Add(time.Duration(c.rolloutConfig.SupersedingDebounceSeconds) * time.Second)
+	return max(release.Sub(now), 0)
+}
+
 // CreateRevRecordsFromRevList converts the revision list into a map of revision records.
-func CreateRevRecordsFromRevList(revList []*servingv1.Revision) (records map[string]resources.RevisionRecord) {
+// The capacity of one replica is only calculated, if the autoscaler configuration is available.
+func CreateRevRecordsFromRevList(revList []*servingv1.Revision,
+	asConfig *autoscalerconfig.Config) (records map[string]resources.RevisionRecord) {
 	records = make(map[string]resources.RevisionRecord)
 	for _, revision := range revList {
 		record := resources.RevisionRecord{}
 		record.MinScale = resources.ReadIntAnnotation(revision, autoscaling.MinScaleAnnotationKey)
 		record.MaxScale = resources.ReadIntAnnotation(revision, autoscaling.MaxScaleAnnotationKey)
+		record.PodCapacity = resources.GetPodCapacity(revision.Annotations, &revision.Spec, asConfig)
+		record.PodResources = resources.GetPodResources(&revision.Spec)
 		record.Name = revision.Name
 		records[revision.Name] = record
 	}
@@ -374,6 +762,15 @@ func updateRolloutOrchestrator(ro *v1.RolloutOrchestrator,
 	podAutoscalerLister palisters.PodAutoscalerNamespaceLister, spaLister listers.StagePodAutoscalerNamespaceLister,
 	config *RolloutConfig) error {
 	ro.Spec.RolloutStrategy = strings.ToLower(config.ProgressiveRolloutStrategy)
+	ro.Spec.ProgressiveRolloutDisabled = !config.ProgressiveRolloutEnabled
+	ro.Spec.PodTerminationPolicy = &v1.PodTerminationPolicy{
+		Mode:                       strings.ToLower(config.PodTerminationPolicy),
+		MaxForcedDeletionsPerStage: int32(config.MaxForcedDeletionsPerStage),
+	}
+	ro.Spec.StageScaleUpTolerance = config.GetStageScaleUpTolerance()
+	updateStageWarmUp(ro, config, time.Now())
+	updatePostRolloutStandby(ro, config, time.Now())
+	acceptPromotion(ro, time.Now())
 	if ro.IsNotConvertToOneUpgrade() || !config.ProgressiveRolloutEnabled {
 		// The StageTargetRevisions is set directly to the final target revisions, because this is not a
 		// one-to-one revision upgrade or the rollout feature is disabled. We do not cover this use case
@@ -381,7 +778,46 @@ func updateRolloutOrchestrator(ro *v1.RolloutOrchestrator,
 		ro.Spec.StageTargetRevisions = append([]v1.TargetRevision{}, ro.Spec.TargetRevisions...)
 		return nil
 	}
-	if ro.Spec.StageTargetRevisions == nil || (ro.IsStageReady() && !ro.IsLastStageComplete()) {
+	_, groupRollback := ro.Annotations[resources.RolloutGroupRollback]
+	rollback := (groupRollback || resources.IsRolloutAborted(ro)) && !ro.IsLastStageComplete()
+	// The rollout can still be reverted after the last stage, as long as the initial revisions are on standby.
+	revert := resources.IsRolloutReverted(ro) && (!ro.IsLastStageComplete() || strategies.StandbyReplicas(ro) > 0)
+	if (rollback || revert) && ro.Spec.StageTargetRevisions != nil && len(ro.Spec.InitialRevisions) != 0 {
+		// The RolloutGroup of this service has failed, or the rollout has been aborted or reverted, and the traffic
+		// goes back to the initial revisions.
+		rollbackTarget := getRollbackStageTargetRevisions(ro)
+		if !reflect.DeepEqual(ro.Spec.StageTargetRevisions, rollbackTarget) {
+			ro.Spec.StageTargetRevisions = rollbackTarget
+			if ro.Spec.StageWarmUp != nil {
+				ro.Spec.StageWarmUp.Replicas = 0
+			}
+			ro.Spec.StageTarget.TargetFinishTime.Inner = metav1.NewTime(time.Now().Add(config.GetStageRolloutTimeout(ro)))
+		}
+		return nil
+	}
+	promotedToFinal := isPromotionPending(ro)
+	if ro.Spec.StageTargetRevisions == nil || (ro.IsStageReady() && !ro.IsLastStageComplete()) || promotedToFinal {
+		if ro.Spec.StageTargetRevisions != nil && (heldByRolloutGroup(ro) || isRolloutPaused(ro)) {
+			// The RolloutGroup of this service, or the pause of the rollout, does not allow it to move on to the
+			// next stage yet.
+			return nil
+		}
+		_, promoted := ro.Annotations[resources.RolloutPromoted]
+		if !promoted && !promotedToFinal && config.GetStageHold(ro, time.Now()) > 0 {
+			// The ready stage soaks, or waits for its deadline, before the next stage starts. The service is
+			// enqueued again, when the hold is over.
+			return nil
+		}
+		if promoted {
+			// The promotion only skips the hold of the current stage.
+			annotations := maps.Clone(ro.Annotations)
+			delete(annotations, resources.RolloutPromoted)
+			ro.Annotations = annotations
+		}
+		if promotedToFinal {
+			// The remaining stages are skipped, without waiting for the current stage to be ready.
+			return updatePromotedStageTargetRevisions(ro, config, podAutoscalerLister, spaLister)
+		}
 		// 1. If so.Spec.StageRevisionTarget is empty, we need to calculate the stage revision target as the new(next)
 		// target.
 		// 2. If IsStageReady == true means the current target has reached, but LastStageReady == false means upgrade has
@@ -391,6 +827,231 @@ func updateRolloutOrchestrator(ro *v1.RolloutOrchestrator,
 	return nil
 }
 
+// acceptPromotion records the promotion of the rollout to its final stage requested by the annotation, and removes
+// the annotation, so that it never applies to the next rollout. The promotion is dropped, if no rollout is in progress.
+func acceptPromotion(ro *v1.RolloutOrchestrator, now time.Time) {
+	promotedBy, found := ro.Annotations[resources.RolloutPromotedToFinal]
+	if !found {
+		return
+	}
+	annotations := maps.Clone(ro.Annotations)
+	delete(annotations, resources.RolloutPromotedToFinal)
+	ro.Annotations = annotations
+	if len(ro.Spec.TargetRevisions) == 0 ||
+		rolloutorchestrator.LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions) {
+		return
+	}
+	ro.Spec.Promotion = &v1.RolloutPromotion{
+		PromotedBy:   promotedBy,
+		PromotedTime: metav1.NewTime(now),
+	}
+}
+
+// isPromotionPending returns true, if the rollout has been promoted to its final stage, but the current stage is
+// not the final stage yet.
+func isPromotionPending(ro *v1.RolloutOrchestrator) bool {
+	return ro.Spec.Promotion != nil && len(ro.Spec.TargetRevisions) != 0 &&
+		!rolloutorchestrator.LastStageComplete(ro.Spec.StageTargetRevisions, ro.Spec.TargetRevisions) &&
+		!rolloutorchestrator.LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions)
+}
+
+// updatePromotedStageTargetRevisions sets the StageTargetRevisions to the final stage, in which the target revision
+// receives all the traffic. The start revisions stay in the stage as the revisions scaling down, so that the
+// target revision is verified to run the replicas for all the traffic, before the StagePodAutoscalers of the start
+// revisions are lowered.
+func updatePromotedStageTargetRevisions(ro *v1.RolloutOrchestrator, config *RolloutConfig,
+	podAutoscalerLister palisters.PodAutoscalerNamespaceLister, spaLister listers.StagePodAutoscalerNamespaceLister) error {
+	if ro.Spec.StageWarmUp != nil {
+		ro.Spec.StageWarmUp.Replicas = 0
+	}
+	stageRevisionTarget, err := getPromotedStageTargetRevisions(ro, podAutoscalerLister, spaLister)
+	if err != nil {
+		return err
+	}
+	ro.Spec.StageTargetRevisions = resolveTagConflicts(stageRevisionTarget, ro.Spec.TargetRevisions)
+	ro.Spec.StageTarget.TargetFinishTime.Inner = metav1.NewTime(time.Now().Add(config.GetStageRolloutTimeout(ro)))
+	return nil
+}
+
+// getPromotedStageTargetRevisions returns the final stage of the rollout promoted from the current traffic split. The
+// target replicas of the target revision are calculated for all the traffic from the gauge of the start revisions.
+func getPromotedStageTargetRevisions(ro *v1.RolloutOrchestrator,
+	podAutoscalerLister palisters.PodAutoscalerNamespaceLister,
+	spaLister listers.StagePodAutoscalerNamespaceLister) ([]v1.TargetRevision, error) {
+	startRevisions := getStartRevisions(ro)
+	if len(startRevisions) == 0 || len(ro.Spec.TargetRevisions) != 1 {
+		return append([]v1.TargetRevision{}, ro.Spec.TargetRevisions...), nil
+	}
+	currentReplicas, currentTraffic, _, gaugeCapacity, err := getGauge(startRevisions, podAutoscalerLister, spaLister)
+	if err != nil {
+		return nil, err
+	}
+	if currentReplicas == 0 || currentTraffic == 0 {
+		// The start revisions run with 0 replicas, so the target revision has nothing to take over.
+		return append([]v1.TargetRevision{}, ro.Spec.TargetRevisions...), nil
+	}
+
+	revUp := getInitialStageRevisionTarget(ro.Spec.TargetRevisions[0])
+	revUp.Percent = ptr.Int64(common.HundredPercent)
+	ratioUp := resources.CapacityRatio(gaugeCapacity, revUp.PodCapacity)
+	targetReplicas := int32(math.Ceil(float64(currentReplicas) * ratioUp * float64(common.HundredPercent) /
+		float64(currentTraffic)))
+	if revUp.MinScale != nil && *revUp.MinScale > targetReplicas {
+		targetReplicas = *revUp.MinScale
+	}
+	if revUp.MaxScale != nil && *revUp.MaxScale > 0 && *revUp.MaxScale < targetReplicas {
+		targetReplicas = *revUp.MaxScale
+	}
+	revUp.TargetReplicas = ptr.Int32(targetReplicas)
+
+	stageRevisionTarget := make([]v1.TargetRevision, 0, len(startRevisions)+1)
+	for _, rev := range startRevisions {
+		if rev.RevisionName == revUp.RevisionName {
+			continue
+		}
+		revDown := *rev.DeepCopy()
+		revDown.Percent = nil
+		revDown.TargetReplicas = ptr.Int32(0)
+		revDown.LatestRevision = ptr.Bool(false)
+		revDown.Direction = v1.DirectionDown
+		stageRevisionTarget = append(stageRevisionTarget, revDown)
+	}
+	return append(stageRevisionTarget, revUp), nil
+}
+
+// updateStageWarmUp keeps the warm-up of the RolloutOrchestrator in line with the configuration, and releases the
+// pre-scaled replicas of the revision scaling up, once the stage has been ready for the stabilization window.
+// The warm-up only applies to the availability strategy, in which the new revision scales up before the traffic moves.
+func updateStageWarmUp(ro *v1.RolloutOrchestrator, config *RolloutConfig, now time.Time) {
+	if !config.StageWarmUpEnabled || !strings.EqualFold(ro.Spec.RolloutStrategy, strategies.AvailabilityStrategy) {
+		ro.Spec.StageWarmUp = nil
+		return
+	}
+	if ro.Spec.StageWarmUp == nil {
+		ro.Spec.StageWarmUp = &v1.StageWarmUp{}
+	}
+	ro.Spec.StageWarmUp.StabilizationSeconds = int32(config.StageWarmUpStabilizationSeconds)
+	if release, found := getStageWarmUpRelease(ro, now); found && release == 0 {
+		ro.Spec.StageWarmUp.Replicas = 0
+	}
+}
+
+// getStageWarmUpRelease returns how long the pre-scaled replicas of the revision scaling up are still kept. The
+// boolean is false, if the revision is not pre-scaled, or the current stage is not ready yet.
+func getStageWarmUpRelease(ro *v1.RolloutOrchestrator, now time.Time) (time.Duration, bool) {
+	if strategies.StageWarmUpReplicas(ro) == 0 || !ro.IsStageReady() || !rolloutorchestrator.StageAccomplished(ro) {
+		return 0, false
+	}
+	cond := ro.Status.GetCondition(v1.SOStageReady)
+	release := cond.LastTransitionTime.Inner.Add(time.Duration(ro.Spec.StageWarmUp.StabilizationSeconds) * time.Second)
+	return max(release.Sub(now), 0), true
+}
+
+// updatePostRolloutStandby keeps the standby of the RolloutOrchestrator in line with the configuration, and releases
+// the replicas on standby of the initial revisions, once the last stage has been complete for the bake period.
+func updatePostRolloutStandby(ro *v1.RolloutOrchestrator, config *RolloutConfig, now time.Time) {
+	if config.PostRolloutStandbyReplicas <= 0 {
+		ro.Spec.PostRolloutStandby = nil
+		return
+	}
+	if ro.Spec.PostRolloutStandby == nil {
+		ro.Spec.PostRolloutStandby = &v1.PostRolloutStandby{}
+	}
+	ro.Spec.PostRolloutStandby.Replicas = int32(config.PostRolloutStandbyReplicas)
+	ro.Spec.PostRolloutStandby.BakeSeconds = int32(config.PostRolloutStandbySeconds)
+	if release, found := getPostRolloutStandbyRelease(ro, now); found && release == 0 {
+		ro.Spec.PostRolloutStandby.Replicas = 0
+	}
+}
+
+// getPostRolloutStandbyRelease returns how long the replicas on standby of the initial revisions are still kept. The
+// boolean is false, if the standby is disabled, or the last stage is not complete yet.
+func getPostRolloutStandbyRelease(ro *v1.RolloutOrchestrator, now time.Time) (time.Duration, bool) {
+	if ro.Spec.PostRolloutStandby == nil || !ro.IsLastStageComplete() || len(ro.Spec.TargetRevisions) == 0 ||
+		!rolloutorchestrator.LastStageComplete(ro.Spec.StageTargetRevisions, ro.Spec.TargetRevisions) {
+		return 0, false
+	}
+	cond := ro.Status.GetCondition(v1.SOLastStageComplete)
+	release := cond.LastTransitionTime.Inner.Add(time.Duration(ro.Spec.PostRolloutStandby.BakeSeconds) * time.Second)
+	return max(release.Sub(now), 0), true
+}
+
+// getStageWarmUpReplicas returns the number of replicas the revision scaling up needs to handle its traffic share
+// in the stage. The load is calculated from the desired scale of the start revisions, which the autoscaler has
+// decided for the traffic they currently receive. 0 is returned, if the desired scale is not available.
+func getStageWarmUpReplicas(startRevisions, stageRevisionTarget []v1.TargetRevision, revUpName string,
+	podAutoscalerLister palisters.PodAutoscalerNamespaceLister) int32 {
+	var revUp *v1.TargetRevision
+	for i := range stageRevisionTarget {
+		if stageRevisionTarget[i].RevisionName == revUpName {
+			revUp = &stageRevisionTarget[i]
+		}
+	}
+	if revUp == nil || revUp.Percent == nil || *revUp.Percent == 0 {
+		return 0
+	}
+
+	// The load is measured in the number of replicas of the revision scaling up.
+	load, traffic := 0.0, int64(0)
+	for _, rev := range startRevisions {
+		if rev.RevisionName == revUpName || rev.Percent == nil {
+			continue
+		}
+		pa, err := podAutoscalerLister.Get(rev.RevisionName)
+		if err != nil || pa.Status.DesiredScale == nil || *pa.Status.DesiredScale < 0 {
+			return 0
+		}
+		load += float64(*pa.Status.DesiredScale) * resources.CapacityRatio(rev.PodCapacity, revUp.PodCapacity)
+		traffic += *rev.Percent
+	}
+	if load == 0 || traffic == 0 {
+		return 0
+	}
+	replicas := int32(math.Ceil(load * float64(*revUp.Percent) / float64(traffic)))
+	if revUp.MaxScale != nil && *revUp.MaxScale > 0 {
+		replicas = min(replicas, *revUp.MaxScale)
+	}
+	return replicas
+}
+
+// heldByRolloutGroup returns true, if the RolloutGroup of the service keeps the RolloutOrchestrator at the current stage.
+func heldByRolloutGroup(ro *v1.RolloutOrchestrator) bool {
+	_, hold := ro.Annotations[resources.RolloutGroupHold]
+	_, rollback := ro.Annotations[resources.RolloutGroupRollback]
+	return hold || rollback
+}
+
+// isRolloutPaused returns true, if the rollout of the RolloutOrchestrator has been paused at the current stage.
+func isRolloutPaused(ro *v1.RolloutOrchestrator) bool {
+	_, paused := ro.Annotations[resources.RolloutPaused]
+	return paused
+}
+
+// getRollbackStageTargetRevisions returns the stage target, which scales the initial revisions back up to their
+// initial traffic, and scales all the other revisions of the current stage down to 0.
+func getRollbackStageTargetRevisions(ro *v1.RolloutOrchestrator) []v1.TargetRevision {
+	initial := make(map[string]bool, len(ro.Spec.InitialRevisions))
+	result := make([]v1.TargetRevision, 0, len(ro.Spec.InitialRevisions)+len(ro.Spec.StageTargetRevisions))
+	for _, rev := range ro.Spec.InitialRevisions {
+		initial[rev.RevisionName] = true
+		target := *rev.DeepCopy()
+		target.Direction = v1.DirectionUp
+		result = append(result, target)
+	}
+	for _, rev := range ro.Spec.StageTargetRevisions {
+		if initial[rev.RevisionName] {
+			continue
+		}
+		target := *rev.DeepCopy()
+		target.Direction = v1.DirectionDown
+		target.Percent = ptr.Int64(0)
+		target.TargetReplicas = ptr.Int32(0)
+		target.LatestRevision = ptr.Bool(false)
+		result = append(result, target)
+	}
+	return result
+}
+
 func getStartRevisions(ro *v1.RolloutOrchestrator) []v1.TargetRevision {
 	startRevisions := ro.Status.StageRevisionStatus
 	if startRevisions == nil || ro.Spec.StageTargetRevisions == nil {
@@ -401,7 +1062,7 @@ func getStartRevisions(ro *v1.RolloutOrchestrator) []v1.TargetRevision {
 		startRevisions = ro.Spec.InitialRevisions
 	}
 
-	return removeZeroTraffic(startRevisions)
+	return drainSupersededFirst(ro, removeZeroTraffic(startRevisions))
 }
 
 // If the percentage of traffic is set to 0, we need to remove it from the startRevisions, because it will lead to the
@@ -420,10 +1081,11 @@ func removeZeroTraffic(revs []v1.TargetRevision) []v1.TargetRevision {
 // getGauge returns the number of replicas and the traffic percentage it occupies, plus the minScale and maxScale
 // defined by the knative service.
 // These values are used to calculate the target number of replicas for the new and
-// the old revision.
+// the old revision. The returned PodCapacity is the capacity of one replica of the revision picked as the gauge,
+// so that the number of replicas can be converted for the revisions with a different capacity.
 func getGauge(targetRevs []v1.TargetRevision,
 	podAutoscalerLister palisters.PodAutoscalerNamespaceLister,
-	spaLister listers.StagePodAutoscalerNamespaceLister) (int32, int64, map[string]int32, error) {
+	spaLister listers.StagePodAutoscalerNamespaceLister) (int32, int64, map[string]int32, *v1.PodCapacity, error) {
 	replicasMap := make(map[string]int32)
 	startIndex := -1
 	for i := 0; i < len(targetRevs); i++ {
@@ -434,12 +1096,13 @@ func getGauge(targetRevs []v1.TargetRevision,
 	}
 
 	if startIndex == -1 {
-		return 0, 0, replicasMap, fmt.Errorf("there is no revision found to to scale up or down")
+		return 0, 0, replicasMap, nil, fmt.Errorf("there is no revision found to to scale up or down")
 	}
 	currentReplicas, currentTraffic, err := getGaugeWithIndex(targetRevs, startIndex, podAutoscalerLister,
 		spaLister)
+	currentCapacity := targetRevs[startIndex].PodCapacity
 	if err != nil {
-		return currentReplicas, currentTraffic, replicasMap, err
+		return currentReplicas, currentTraffic, replicasMap, currentCapacity, err
 	}
 	replicasMap[targetRevs[startIndex].RevisionName] = currentReplicas
 
@@ -451,25 +1114,43 @@ func getGauge(targetRevs []v1.TargetRevision,
 			currentReplicasN, currentTrafficN, errN := getGaugeWithIndex(targetRevs, i, podAutoscalerLister,
 				spaLister)
 			if errN != nil {
-				return currentReplicas, currentTraffic, replicasMap, errN
+				return currentReplicas, currentTraffic, replicasMap, currentCapacity, errN
 			}
 			replicasMap[targetRevs[i].RevisionName] = currentReplicasN
 			if spaLister != nil {
+				// The replicas of the revisions are compared in the unit of the capacity of the current gauge.
+				capacityN := targetRevs[i].PodCapacity
+				normalizedN := float64(currentReplicasN) * resources.CapacityRatio(capacityN, currentCapacity)
 				if _, err = spaLister.Get(targetRevs[i].RevisionName); err == nil {
 					// Normally we pick up the larger value of replicas and traffic as the gauge.
-					if int64(currentReplicasN)*currentTraffic > int64(currentReplicas)*currentTrafficN {
+					if normalizedN*float64(currentTraffic) > float64(currentReplicas)*float64(currentTrafficN) {
 						currentReplicas = currentReplicasN
 						currentTraffic = currentTrafficN
+						currentCapacity = capacityN
 					}
-				} else if int64(currentReplicasN)*currentTraffic < int64(currentReplicas)*currentTrafficN {
+				} else if normalizedN*float64(currentTraffic) < float64(currentReplicas)*float64(currentTrafficN) {
 					// If SPA does not exist, we pick up the smaller value of replicas and traffic as the gauge.
 					currentReplicas = currentReplicasN
 					currentTraffic = currentTrafficN
+					currentCapacity = capacityN
 				}
 			}
 		}
 	}
-	return currentReplicas, currentTraffic, replicasMap, nil
+	return currentReplicas, currentTraffic, replicasMap, currentCapacity, nil
+}
+
+// normalizeReplicas converts the number of replicas measured with the capacity of the gauge into the number of
+// replicas needed by the revision, based on the capacity of one replica for the revision.
+// The round function is only applied when the capacities are different, so the number of replicas stays
+// the same for the revisions with the same capacity.
+func normalizeReplicas(replicas int32, gauge *v1.PodCapacity, revision *v1.TargetRevision,
+	round func(float64) float64) int32 {
+	ratio := resources.CapacityRatio(gauge, revision.PodCapacity)
+	if ratio == 1 {
+		return replicas
+	}
+	return int32(round(float64(replicas) * ratio))
 }
 
 func getGaugeWithIndex(targetRevs []v1.TargetRevision, index int,
@@ -516,6 +1197,42 @@ func getDeltaReplicasTraffic(currentReplicas int32, currentTraffic int64, ratio
 	return int32(stageReplicas), int64(stageTrafficDelta)
 }
 
+// limitStageByResources reduces the number of replicas added in the stage, measured with the capacity of the gauge,
+// so that the cpu and memory requested by the replicas added to the revision scaling up stay within the budget.
+// The budget is the ratio of the resources requested by the replicas of the revisions, when the stage starts.
+// A resource is only limited, if it is requested by all the revisions. At least one replica is added in each stage.
+func limitStageByResources(deltaReplicas int32, ratio int, startRevisions []v1.TargetRevision,
+	replicasMap map[string]int32, revUp *v1.TargetRevision, gauge *v1.PodCapacity) int32 {
+	if revUp.PodResources == nil {
+		return deltaReplicas
+	}
+	var cpu, memory int64
+	cpuRequested, memoryRequested := revUp.PodResources.CPUMilliValue > 0, revUp.PodResources.MemoryValue > 0
+	for _, rev := range startRevisions {
+		replicas, found := replicasMap[rev.RevisionName]
+		if !found || replicas == 0 {
+			continue
+		}
+		if rev.PodResources == nil {
+			return deltaReplicas
+		}
+		cpu += int64(replicas) * rev.PodResources.CPUMilliValue
+		memory += int64(replicas) * rev.PodResources.MemoryValue
+		cpuRequested = cpuRequested && rev.PodResources.CPUMilliValue > 0
+		memoryRequested = memoryRequested && rev.PodResources.MemoryValue > 0
+	}
+	budgetCPU, budgetMemory := cpu*int64(ratio)/100, memory*int64(ratio)/100
+
+	for ; deltaReplicas > 1; deltaReplicas-- {
+		replicasUp := int64(normalizeReplicas(deltaReplicas, gauge, revUp, math.Ceil))
+		if (!cpuRequested || replicasUp*revUp.PodResources.CPUMilliValue <= budgetCPU) &&
+			(!memoryRequested || replicasUp*revUp.PodResources.MemoryValue <= budgetMemory) {
+			break
+		}
+	}
+	return deltaReplicas
+}
+
 func targetsCloneWithoutURL(revs []v1.TargetRevision) []v1.TargetRevision {
 	revsCopies := make([]v1.TargetRevision, len(revs))
 	for i := 0; i < len(revs); i++ {
@@ -539,6 +1256,10 @@ func updateStageTargetRevisions(ro *v1.RolloutOrchestrator, config *RolloutConfi
 	// The length of the TargetRevisions is always one here, meaning that there is
 	// only one revision as the target revision when the rollout is over.
 	var stageRevisionTarget []v1.TargetRevision
+	if ro.Spec.StageWarmUp != nil {
+		// The revision is only pre-scaled, if the load of the new stage is known.
+		ro.Spec.StageWarmUp.Replicas = 0
+	}
 	if !targetsEqual(ro.Spec.InitialRevisions, ro.Spec.TargetRevisions) {
 		startRevisions := getStartRevisions(ro)
 		if len(startRevisions) == 0 {
@@ -560,7 +1281,7 @@ func updateStageTargetRevisions(ro *v1.RolloutOrchestrator, config *RolloutConfi
 
 		// The currentReplicas and currentTraffic will be used as the standard values to calculate
 		// the further target number of replicas for each revision.
-		currentReplicas, currentTraffic, repMap, err := getGauge(startRevisions, podAutoscalerLister,
+		currentReplicas, currentTraffic, repMap, gaugeCapacity, err := getGauge(startRevisions, podAutoscalerLister,
 			spaLister)
 		if err != nil {
 			return err
@@ -569,7 +1290,19 @@ func updateStageTargetRevisions(ro *v1.RolloutOrchestrator, config *RolloutConfi
 		// The deltaReplicas will be the number of replicas the new revision will increase by. The deltaTrafficPercent
 		// will be the traffic percentage that will be shifted to the new revision.
 		// For the old revision, just do the opposite.
-		deltaReplicas, deltaTrafficPercent := getDeltaReplicasTraffic(currentReplicas, currentTraffic, config.OverConsumptionRatio)
+		// If the stages are accelerated, the ratio grows after the stages finished well before their deadlines.
+		deltaReplicas, deltaTrafficPercent := getDeltaReplicasTraffic(currentReplicas, currentTraffic,
+			config.GetStageRatio(ro))
+		if strings.EqualFold(config.OverConsumptionBasis, resources.OverConsumptionBasisResources) && currentReplicas > 0 {
+			// The budget is measured in the requested resources, so the stage is reduced, if the replicas of the
+			// new revision request more resources than the replicas of the old revisions.
+			if limited := limitStageByResources(deltaReplicas, config.GetStageRatio(ro), startRevisions, repMap,
+				&ro.Spec.TargetRevisions[0], gaugeCapacity); limited < deltaReplicas {
+				deltaReplicas = limited
+				deltaTrafficPercent = int64(math.Ceil(float64(deltaReplicas) * float64(currentTraffic) /
+					float64(currentReplicas)))
+			}
+		}
 
 		// Based on the min, max and currentReplicas, we can decide the number of replicas for the revisions
 		// are either traffic driven or non-traffic driven.
@@ -580,21 +1313,36 @@ func updateStageTargetRevisions(ro *v1.RolloutOrchestrator, config *RolloutConfi
 			stageRevisionTarget = append(stageRevisionTarget, ro.Spec.TargetRevisions...)
 		} else {
 			stageRevisionTarget = calculateStageTargetRevisions(repMap, startRevisions, ro,
-				deltaReplicas, deltaTrafficPercent, currentReplicas, currentTraffic)
+				deltaReplicas, deltaTrafficPercent, currentReplicas, currentTraffic, gaugeCapacity)
+			if ro.Spec.StageWarmUp != nil {
+				ro.Spec.StageWarmUp.Replicas = getStageWarmUpReplicas(startRevisions, stageRevisionTarget,
+					ro.Spec.TargetRevisions[0].RevisionName, podAutoscalerLister)
+			}
 		}
 	} else {
 		stageRevisionTarget = make([]v1.TargetRevision, 0, len(ro.Spec.TargetRevisions))
 		stageRevisionTarget = append(stageRevisionTarget, ro.Spec.TargetRevisions...)
 	}
-	ro.Spec.StageTargetRevisions = stageRevisionTarget
+	ro.Spec.StageTargetRevisions = resolveTagConflicts(stageRevisionTarget, ro.Spec.TargetRevisions)
 
 	// Set the target time when the current stage will be over.
-	ro.Spec.StageTarget.TargetFinishTime.Inner = metav1.NewTime(time.Now().Add(time.Duration(float64(time.Minute) * float64(config.StageRolloutTimeoutMinutes))))
+	ro.Spec.StageTarget.TargetFinishTime.Inner = metav1.NewTime(time.Now().Add(config.GetStageRolloutTimeout(ro)))
 	return nil
 }
 
 func (c *Reconciler) checkServiceOrchestratorsReady(ctx context.Context, so *v1.RolloutOrchestrator,
 	service *servingv1.Service) pkgreconciler.Event {
+	// The pre-scaled replicas of the revision scaling up are released, when the service is reconciled again after
+	// the stabilization window, even if this is the last stage.
+	if release, found := getStageWarmUpRelease(so, time.Now()); found && release > 0 {
+		c.enqueueAfter(service, release)
+	}
+	// The replicas on standby of the initial revisions are released, when the service is reconciled again after
+	// the bake period.
+	if release, found := getPostRolloutStandbyRelease(so, time.Now()); found && release > 0 {
+		c.enqueueAfter(service, release)
+	}
+
 	if so.IsReady() || rolloutorchestrator.LastStageComplete(so.Spec.StageTargetRevisions, so.Spec.TargetRevisions) ||
 		(so.Spec.TargetFinishTime == apis.VolatileTime{}) {
 		// Knative Service cannot reflect the status of the RolloutOrchestrator.
@@ -608,13 +1356,26 @@ func (c *Reconciler) checkServiceOrchestratorsReady(ctx context.Context, so *v1.
 	// TODO: figure out a way to reflect the status of the RolloutOrchestrator in the knative service.
 	now := metav1.NewTime(time.Now())
 
+	// The paused rollout does not time out. The service is reconciled again, when the pause is removed.
+	if isRolloutPaused(so) {
+		return nil
+	}
+
+	// The next stage starts as soon as the current stage is ready, because the RolloutOrchestrator enqueues
+	// the service when its status changes. If the ready stage is held, reconcile again when the hold is over,
+	// instead of treating the stage as timed out.
+	if hold := c.rolloutConfig.GetStageHold(so, now.Time); hold > 0 {
+		c.enqueueAfter(service, hold)
+		return nil
+	}
+
 	if so.Spec.TargetFinishTime.Inner.Before(&now) {
 		// Check if the stage target time has expired. If so, change the traffic split to the next stage.
 		var err error
 
 		// Check if the deployment for the revisions are in available status.
 		// If not, we consider the stage is unable to finish due to an error and return the error.
-		err = checkDeploymentsAvailable(so, c.deploymentLister)
+		err = rolloutorchestrator.CheckDeploymentsAvailable(so, c.deploymentLister)
 		if err != nil {
 			return err
 		}
@@ -625,33 +1386,14 @@ func (c *Reconciler) checkServiceOrchestratorsReady(ctx context.Context, so *v1.
 			return err
 		}
 		so.Spec.StageTarget.TargetFinishTime.Inner = metav1.NewTime(time.Now().Add(
-			time.Duration(float64(c.rolloutConfig.StageRolloutTimeoutMinutes) * float64(time.Minute))))
+			c.rolloutConfig.GetStageRolloutTimeout(so)))
 		_, err = c.client.ServingV1().RolloutOrchestrators(service.Namespace).Update(ctx, so, metav1.UpdateOptions{})
 		if err != nil {
 			return err
 		}
 	}
 
-	c.enqueueAfter(service, time.Duration(float64(c.rolloutConfig.StageRolloutTimeoutMinutes)*float64(time.Minute)))
-	return nil
-}
-
-func checkDeploymentsAvailable(ro *v1.RolloutOrchestrator, deploymentLister appsv1listers.DeploymentLister) error {
-	for _, rev := range ro.Spec.StageTargetRevisions {
-		selector := labels.SelectorFromSet(labels.Set{
-			serving.ServiceLabelKey:  ro.Name,
-			serving.RevisionLabelKey: rev.RevisionName,
-		})
-		deps, err := deploymentLister.Deployments(ro.Namespace).List(selector)
-		if apierrs.IsNotFound(err) {
-			return fmt.Errorf("error no deployment was not found for the revision %s", rev.RevisionName)
-		} else if err != nil {
-			return err
-		}
-		if len(deps) > 0 && !common.IsDeploymentAvailable(deps[0]) {
-			return fmt.Errorf("error the deployment for the revision %s was not ready, when the timeout limit hit", rev.RevisionName)
-		}
-	}
+	c.enqueueAfter(service, c.rolloutConfig.GetStageRolloutTimeout(so))
 	return nil
 }
 
@@ -661,7 +1403,7 @@ func shiftTrafficNextStage(revisionTarget []v1.TargetRevision, ratio float64,
 	// The TargetRevision at the index 0 will always be the revision that is about to scale down.
 	// We get the number of replicas and how much traffic dispatched to this revision, and use them as the gauge
 	// to calculate the number of replicas for any other traffic percentage.
-	currentReplicas, currentTraffic, _, err := getGauge(revisionTarget, podAutoscalerLister, spaLister)
+	currentReplicas, currentTraffic, _, gaugeCapacity, err := getGauge(revisionTarget, podAutoscalerLister, spaLister)
 	if err != nil {
 		return revisionTarget, err
 	}
@@ -692,7 +1434,10 @@ func shiftTrafficNextStage(revisionTarget []v1.TargetRevision, ratio float64,
 	}
 
 	// Calculate how much traffic percentage we need to reduce for the old revision to reach the target replicas.
-	stageTrafficDeltaInt := math.Ceil((float64(oldReplica) - float64(*revisionTarget[0].TargetReplicas)) * float64(currentTraffic) / float64(currentReplicas))
+	// The replicas of the old revision are converted with the capacity of the gauge.
+	ratio0 := resources.CapacityRatio(revisionTarget[0].PodCapacity, gaugeCapacity)
+	stageTrafficDeltaInt := math.Ceil((float64(oldReplica) - float64(*revisionTarget[0].TargetReplicas)) * ratio0 *
+		float64(currentTraffic) / float64(currentReplicas))
 
 	// We will choose the smaller value between the ratio and the stageTrafficDeltaInt as the traffic percentage
 	// to reduce.
@@ -721,8 +1466,11 @@ func getInitialStageRevisionTarget(ftr v1.TargetRevision) v1.TargetRevision {
 	targetNewRollout := v1.TargetRevision{}
 	targetNewRollout.RevisionName = ftr.RevisionName
 	targetNewRollout.LatestRevision = ptr.Bool(true)
+	targetNewRollout.Tag = ftr.Tag
 	targetNewRollout.MinScale = ftr.MinScale
 	targetNewRollout.MaxScale = ftr.MaxScale
+	targetNewRollout.PodCapacity = ftr.PodCapacity.DeepCopy()
+	targetNewRollout.PodResources = ftr.PodResources.DeepCopy()
 	targetNewRollout.Direction = v1.DirectionUp
 	targetNewRollout.TargetReplicas = ptr.Int32(0)
 	targetNewRollout.Percent = ptr.Int64(0)
@@ -730,20 +1478,28 @@ func getInitialStageRevisionTarget(ftr v1.TargetRevision) v1.TargetRevision {
 }
 
 func refreshStage(replicasMap map[string]int32, startRevisions []v1.TargetRevision, scaleUpIndex, scaleDownIndex int,
-	stageReplicasInt int32, stageTrafficDeltaInt int64, currentReplicas int32, currentTraffic int64) []v1.TargetRevision {
+	stageReplicasInt int32, stageTrafficDeltaInt int64, currentReplicas int32, currentTraffic int64,
+	gaugeCapacity *v1.PodCapacity) []v1.TargetRevision {
 	stageRevisionTarget := make([]v1.TargetRevision, len(startRevisions))
 	revUp := startRevisions[scaleUpIndex].DeepCopy()
 	revDown := startRevisions[scaleDownIndex].DeepCopy()
 
+	// The currentReplicas and the stageReplicasInt are measured with the capacity of the gauge. Convert them for
+	// the revisions scaling up and down, in case their replicas have different capacities.
+	ratioUp := resources.CapacityRatio(gaugeCapacity, revUp.PodCapacity)
+	ratioDown := resources.CapacityRatio(gaugeCapacity, revDown.PodCapacity)
+	stageReplicasUp := normalizeReplicas(stageReplicasInt, gaugeCapacity, revUp, math.Ceil)
+	stageReplicasDown := normalizeReplicas(stageReplicasInt, gaugeCapacity, revDown, math.Floor)
+
 	if *revDown.Percent <= stageTrafficDeltaInt {
 		// This means we need to reduce the traffic down to 0 for this revision to make the current stage's move.
 		// Adjust the target traffic percentage for this stage.
 		stageTrafficDelta := *revDown.Percent
 		revUp.Percent = ptr.Int64(stageTrafficDelta + *revUp.Percent)
-		revUp.TargetReplicas = ptr.Int32(replicasMap[revUp.RevisionName] + stageReplicasInt)
+		revUp.TargetReplicas = ptr.Int32(replicasMap[revUp.RevisionName] + stageReplicasUp)
 
 		// Calculate the adjusted number of delta for this stage.
-		adjustedReplicas := math.Floor(float64(currentReplicas) * float64(*revUp.Percent) / float64(currentTraffic))
+		adjustedReplicas := math.Floor(float64(currentReplicas) * ratioUp * float64(*revUp.Percent) / float64(currentTraffic))
 		if *revUp.TargetReplicas > int32(adjustedReplicas) {
 			revUp.TargetReplicas = ptr.Int32(int32(adjustedReplicas))
 		}
@@ -758,16 +1514,15 @@ func refreshStage(replicasMap map[string]int32, startRevisions []v1.TargetRevisi
 		revDown.Percent = nil
 		revDown.TargetReplicas = ptr.Int32(0)
 		revDown.Direction = v1.DirectionDown
-		revDown.Tag = ""
 		stageRevisionTarget[scaleDownIndex] = *revDown
 
 	} else {
 		stageTrafficDelta := stageTrafficDeltaInt
 		revUp.Percent = ptr.Int64(stageTrafficDelta + *revUp.Percent)
-		revUp.TargetReplicas = ptr.Int32(replicasMap[revUp.RevisionName] + stageReplicasInt)
+		revUp.TargetReplicas = ptr.Int32(replicasMap[revUp.RevisionName] + stageReplicasUp)
 
 		// Calculate the adjusted number of delta for this stage.
-		adjustedReplicas := math.Floor(float64(currentReplicas) * float64(*revUp.Percent) / float64(currentTraffic))
+		adjustedReplicas := math.Floor(float64(currentReplicas) * ratioUp * float64(*revUp.Percent) / float64(currentTraffic))
 		if *revUp.TargetReplicas > int32(adjustedReplicas) {
 			revUp.TargetReplicas = ptr.Int32(int32(adjustedReplicas))
 		}
@@ -776,14 +1531,13 @@ func refreshStage(replicasMap map[string]int32, startRevisions []v1.TargetRevisi
 
 		// Adjust the target traffic percentage for this stage.
 		revDown.Percent = ptr.Int64(*revDown.Percent - stageTrafficDelta)
-		revDown.TargetReplicas = ptr.Int32(replicasMap[revDown.RevisionName] - stageReplicasInt)
+		revDown.TargetReplicas = ptr.Int32(replicasMap[revDown.RevisionName] - stageReplicasDown)
 
-		adjustedReplicas = math.Ceil(float64(currentReplicas) * float64(*revDown.Percent) / float64(currentTraffic))
+		adjustedReplicas = math.Ceil(float64(currentReplicas) * ratioDown * float64(*revDown.Percent) / float64(currentTraffic))
 		if *revDown.TargetReplicas < int32(adjustedReplicas) {
 			revDown.TargetReplicas = ptr.Int32(int32(adjustedReplicas))
 		}
 		revDown.Direction = v1.DirectionDown
-		revDown.Tag = ""
 		stageRevisionTarget[scaleDownIndex] = *revDown
 	}
 
@@ -799,7 +1553,8 @@ func refreshStage(replicasMap map[string]int32, startRevisions []v1.TargetRevisi
 }
 
 func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions []v1.TargetRevision, ro *v1.RolloutOrchestrator,
-	stageReplicasInt int32, stageTrafficDeltaInt int64, currentReplicas int32, currentTraffic int64) []v1.TargetRevision {
+	stageReplicasInt int32, stageTrafficDeltaInt int64, currentReplicas int32, currentTraffic int64,
+	gaugeCapacity *v1.PodCapacity) []v1.TargetRevision {
 	// The length of startRevisions will be 1 or greater, if we can reach this function.
 	// First, we need to check if the revision in the finalTargetRevs exists in the startRevisions.
 	var stageRevisionTarget []v1.TargetRevision
@@ -817,7 +1572,7 @@ func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions
 			stageRevisionTarget = ro.Spec.StageTargetRevisions
 			if scaleDownIndex >= 0 {
 				stageRevisionTarget = refreshStage(replicasMap, startRevisions, scaleUpIndex, scaleDownIndex,
-					stageReplicasInt, stageTrafficDeltaInt, currentReplicas, currentTraffic)
+					stageReplicasInt, stageTrafficDeltaInt, currentReplicas, currentTraffic, gaugeCapacity)
 			}
 		} else {
 			// If the revision is not the last one, then the last revision is the one we scale down.
@@ -832,7 +1587,7 @@ func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions
 				}
 			}
 			stageRevisionTarget = refreshStage(replicasMap, startRevisions, scaleUpIndex, scaleDownIndex,
-				stageReplicasInt, stageTrafficDeltaInt, currentReplicas, currentTraffic)
+				stageReplicasInt, stageTrafficDeltaInt, currentReplicas, currentTraffic, gaugeCapacity)
 		}
 
 	} else {
@@ -842,6 +1597,11 @@ func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions
 
 		// Check the last one in the startRevisions to see if this revision have enough percentage to reduce.
 		lastRev := *startRevisions[len(startRevisions)-1].DeepCopy()
+
+		// The currentReplicas and the stageReplicasInt are measured with the capacity of the gauge. Convert them for
+		// the new revision and the revision scaling down, in case their replicas have different capacities.
+		ratioUp := resources.CapacityRatio(gaugeCapacity, tempTarget.PodCapacity)
+		ratioDown := resources.CapacityRatio(gaugeCapacity, lastRev.PodCapacity)
 		if *lastRev.Percent <= stageTrafficDeltaInt {
 			// This means we need to reduce the traffic down to 0 for this revision to make the current stage's move.
 			// Adjust the target traffic percentage for this stage.
@@ -849,7 +1609,7 @@ func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions
 			tempTarget.Percent = ptr.Int64(stageTrafficDelta)
 
 			// Calculate the adjusted number of delta for this stage.
-			adjustedDeltaReplicas := math.Floor(float64(currentReplicas) * float64(stageTrafficDelta) / float64(currentTraffic))
+			adjustedDeltaReplicas := math.Floor(float64(currentReplicas) * ratioUp * float64(stageTrafficDelta) / float64(currentTraffic))
 			tempTarget.TargetReplicas = ptr.Int32(int32(adjustedDeltaReplicas))
 
 			if *tempTarget.Percent == common.HundredPercent && tempTarget.MinScale != nil && *tempTarget.MinScale > *tempTarget.TargetReplicas {
@@ -864,27 +1624,24 @@ func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions
 			lastRev.TargetReplicas = ptr.Int32(0)
 			lastRev.LatestRevision = ptr.Bool(false)
 			lastRev.Direction = v1.DirectionDown
-			// reset the tag
-			lastRev.Tag = ""
 			stageRevisionTarget[len(stageRevisionTarget)-2] = lastRev
 
 		} else {
 			// Adjust the target traffic percentage for this stage.
 			stageTrafficDelta := stageTrafficDeltaInt
 			tempTarget.Percent = ptr.Int64(stageTrafficDelta)
-			tempTarget.TargetReplicas = ptr.Int32(stageReplicasInt)
+			tempTarget.TargetReplicas = ptr.Int32(normalizeReplicas(stageReplicasInt, gaugeCapacity, &tempTarget, math.Ceil))
 			// It is the first time that traffic starts to move on to the new revision.
 			stageRevisionTarget[len(stageRevisionTarget)-1] = tempTarget
 
 			// Keep one in the list for sure, but set the percentage to empty, target replicas into 0.
 			lastRev.Percent = ptr.Int64(*lastRev.Percent - stageTrafficDeltaInt)
-			lastRev.TargetReplicas = ptr.Int32(replicasMap[lastRev.RevisionName] - stageReplicasInt)
+			lastRev.TargetReplicas = ptr.Int32(replicasMap[lastRev.RevisionName] -
+				normalizeReplicas(stageReplicasInt, gaugeCapacity, &lastRev, math.Floor))
 			lastRev.LatestRevision = ptr.Bool(false)
 
 			lastRev.Direction = v1.DirectionDown
-			// reset the tag
-			lastRev.Tag = ""
-			adjustedDeltaReplicas := math.Ceil(float64(currentReplicas) * float64(*lastRev.Percent) / float64(currentTraffic))
+			adjustedDeltaReplicas := math.Ceil(float64(currentReplicas) * ratioDown * float64(*lastRev.Percent) / float64(currentTraffic))
 
 			if *lastRev.TargetReplicas < int32(adjustedDeltaReplicas) {
 				lastRev.TargetReplicas = ptr.Int32(int32(adjustedDeltaReplicas))
@@ -899,8 +1656,6 @@ func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions
 			clonedRev := *startRevisions[i].DeepCopy()
 			clonedRev.Direction = "stay"
 			clonedRev.LatestRevision = ptr.Bool(false)
-			// reset the tag
-			clonedRev.Tag = ""
 			if clonedRev.MinScale != nil && clonedRev.TargetReplicas != nil && *clonedRev.TargetReplicas < *clonedRev.MinScale {
 				clonedRev.TargetReplicas = nil
 			}
@@ -910,10 +1665,43 @@ func calculateStageTargetRevisions(replicasMap map[string]int32, startRevisions
 	return stageRevisionTarget
 }
 
+// resolveTagConflicts keeps each tag of the stage on one revision only, since the tags of the route must be unique.
+// The tags carried from the InitialRevisions and the TargetRevisions may collide, e.g. when the user moves a tag to
+// the new revision. A tag defined in the TargetRevisions stays with its revision in the TargetRevisions. Any other
+// tag stays with the first revision holding it in the stage, and is removed from the revisions after it.
+func resolveTagConflicts(stageRevisionTarget, finalTargetRevs []v1.TargetRevision) []v1.TargetRevision {
+	owners := make(map[string]string, len(finalTargetRevs))
+	for _, rev := range finalTargetRevs {
+		if _, found := owners[rev.Tag]; rev.Tag != "" && !found {
+			owners[rev.Tag] = rev.RevisionName
+		}
+	}
+	for i := range stageRevisionTarget {
+		tag := stageRevisionTarget[i].Tag
+		if tag == "" {
+			continue
+		}
+		if owner, found := owners[tag]; found && owner != stageRevisionTarget[i].RevisionName {
+			stageRevisionTarget[i].Tag = ""
+			continue
+		}
+		// The tag is claimed by the first revision holding it, so the duplicates after it lose it.
+		owners[tag] = stageRevisionTarget[i].RevisionName
+		for j := i + 1; j < len(stageRevisionTarget); j++ {
+			if stageRevisionTarget[j].Tag == tag {
+				stageRevisionTarget[j].Tag = ""
+			}
+		}
+	}
+	return stageRevisionTarget
+}
+
 func TransformService(service *servingv1.Service, ro *v1.RolloutOrchestrator, rc *RolloutConfig,
 	spaLister listers.StagePodAutoscalerNamespaceLister) *servingv1.Service {
-	// If Knative Service defines more than one traffic, this feature tentatively does not cover this case.
-	if len(service.Spec.Traffic) > 1 {
+	// If Knative Service defines more than one traffic, this feature tentatively does not cover this case, unless
+	// the edited traffic has been rejected, and the rollout goes on to its single target revision.
+	// Without any target revision, there is nothing to roll out, and the traffic is kept as it is.
+	if len(ro.Spec.TargetRevisions) == 0 || (len(service.Spec.Traffic) > 1 && len(ro.Spec.TargetRevisions) != 1) {
 		return service
 	}
 	service.Spec.RouteSpec = servingv1.RouteSpec{
@@ -922,6 +1710,8 @@ func TransformService(service *servingv1.Service, ro *v1.RolloutOrchestrator, rc
 	return service
 }
 
+// convertIntoTrafficTarget converts the stage of the RolloutOrchestrator into the traffic of the route. The
+// RolloutOrchestrator must have at least one target revision.
 func convertIntoTrafficTarget(name string, ro *v1.RolloutOrchestrator, rc *RolloutConfig,
 	spaLister listers.StagePodAutoscalerNamespaceLister) []servingv1.TrafficTarget {
 	revisionTarget := ro.Spec.StageTargetRevisions
@@ -972,11 +1762,19 @@ func convertIntoTrafficTarget(name string, ro *v1.RolloutOrchestrator, rc *Rollo
 		//	lastStage = true
 		//}
 
+		// The replicas missing within the scale up tolerance do not hold the traffic back.
+		var tolerated int32
+		if targetNumberReplicas != nil {
+			tolerated = strategies.ScaleUpTolerance(ro.Spec.StageScaleUpTolerance, *targetNumberReplicas)
+		}
+
 		spa, err := spaLister.Get(spaTargetRevName)
-		// Check the number of replicas has reached the target number of replicas for the revision scaling up
+		// Check the number of replicas has reached the target number of replicas for the revision scaling up.
+		// If the revision is pre-scaled, the traffic waits for the pre-scaled number of replicas as well.
 		if err != nil || spa.Status.ActualScale == nil || (err == nil && targetNumberReplicas != nil &&
 			spa.Status.ActualScale != nil && minScale != nil && *targetNumberReplicas <= *minScale &&
-			*spa.Status.ActualScale < *targetNumberReplicas) {
+			*spa.Status.ActualScale < *targetNumberReplicas-tolerated) ||
+			!strategies.IsStageWarmedUp(spa, &finalTargetRevs[0], strategies.StageWarmUpReplicas(ro)) {
 			// If we have issues getting the spa, or the number of the replicas has reached the target number of
 			// the revision to scale up, we set the revisionTarget to ro.Spec.StageTargetRevisions.
 
@@ -996,6 +1794,8 @@ func convertIntoTrafficTarget(name string, ro *v1.RolloutOrchestrator, rc *Rollo
 					for index := range revisionTarget {
 						if revisionTarget[index].RevisionName == spaTargetRevName {
 							found = true
+							// The target revision is pinned by its name, while a newer revision waits.
+							revisionTarget[index].LatestRevision = finalTargetRevs[0].LatestRevision
 							continue
 						}
 						revisionTarget[index].LatestRevision = ptr.Bool(false)
@@ -1059,5 +1859,60 @@ func convertIntoTrafficTarget(name string, ro *v1.RolloutOrchestrator, rc *Rollo
 		target.URL = revision.URL
 		trafficTarget = append(trafficTarget, target)
 	}
+
+	if rc != nil && rc.ProgressiveRolloutEnabled && !ro.IsNotConvertToOneUpgrade() && !ro.IsLastStageComplete() {
+		// The tags of the rollout are removed, when the last stage is complete.
+		trafficTarget = appendRolloutTags(trafficTarget, name, ro, rc)
+	}
 	return trafficTarget
 }
+
+// appendRolloutTags adds the CandidateTag to the revision scaling up, and the StableTag to the revision scaling
+// down, as the separate traffic targets with 0% of the traffic, so that the tags defined by the user are kept.
+// A tag is not added, if it is already used in the traffic.
+// The revision scaling up may not be ready yet, and the Route does not become ready until all the revisions named
+// in its traffic are. So the CandidateTag only names the revision, if the traffic names it already. Otherwise, it
+// follows the latest ready revision of the configuration, the same as the traffic of the latest revision.
+func appendRolloutTags(trafficTarget []servingv1.TrafficTarget, name string, ro *v1.RolloutOrchestrator,
+	rc *RolloutConfig) []servingv1.TrafficTarget {
+	used, named := sets.New[string](), sets.New[string]()
+	for _, target := range trafficTarget {
+		used.Insert(target.Tag)
+		named.Insert(target.RevisionName)
+	}
+	addTag := func(tag string, target servingv1.TrafficTarget) {
+		if tag == "" || (target.RevisionName == "" && target.ConfigurationName == "") || used.Has(tag) {
+			return
+		}
+		used.Insert(tag)
+		target.Tag, target.Percent = tag, ptr.Int64(0)
+		trafficTarget = append(trafficTarget, target)
+	}
+	candidate := ro.Spec.TargetRevisions[0]
+	if named.Has(candidate.RevisionName) {
+		addTag(rc.CandidateTag, servingv1.TrafficTarget{RevisionName: candidate.RevisionName,
+			LatestRevision: ptr.Bool(false)})
+	} else {
+		configurationName := candidate.ConfigurationName
+		if strings.TrimSpace(configurationName) == "" {
+			configurationName = name
+		}
+		addTag(rc.CandidateTag, servingv1.TrafficTarget{ConfigurationName: configurationName,
+			LatestRevision: ptr.Bool(true)})
+	}
+	addTag(rc.StableTag, servingv1.TrafficTarget{RevisionName: getStableRevisionName(ro),
+		LatestRevision: ptr.Bool(false)})
+	return trafficTarget
+}
+
+// getStableRevisionName returns the name of the initial revision with the most traffic, other than the revision
+// scaling up.
+func getStableRevisionName(ro *v1.RolloutOrchestrator) string {
+	name, percent := "", int64(-1)
+	for _, rev := range ro.Spec.InitialRevisions {
+		if rev.RevisionName != ro.Spec.TargetRevisions[0].RevisionName && ptr.Int64Value(rev.Percent) > percent {
+			name, percent = rev.RevisionName, ptr.Int64Value(rev.Percent)
+		}
+	}
+	return name
+}
@@ -17,22 +17,38 @@ limitations under the License.
 package service
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/ptr"
 	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	fakeclientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned/fake"
 	listers "knative.dev/serving-progressive-rollout/pkg/client/listers/serving/v1"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/common"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator/strategies"
 	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	"knative.dev/serving-progressive-rollout/pkg/tracing"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
@@ -81,7 +97,7 @@ func TestCreateRevRecordsFromRevList(t *testing.T) {
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			r := CreateRevRecordsFromRevList(test.revList)
+			r := CreateRevRecordsFromRevList(test.revList, nil)
 			if len(r) != len(test.ExpectedResult) {
 				t.Fatalf("Result of CreateRevRecordsFromRevList() = %v, want %v", r, test.ExpectedResult)
 			}
@@ -332,7 +348,7 @@ func TestGetGauge(t *testing.T) {
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			replicas, traffic, _, _ := getGauge(test.targetRevs, test.podAutoscalerLister, nil)
+			replicas, traffic, _, _, _ := getGauge(test.targetRevs, test.podAutoscalerLister, nil)
 			if !reflect.DeepEqual(replicas, test.ExpectedReplicas) {
 				t.Fatalf("Result of getGauge() = %v, want %v", replicas, test.ExpectedReplicas)
 			}
@@ -379,6 +395,612 @@ func TestGetDeltaReplicasTraffic(t *testing.T) {
 	}
 }
 
+func TestLimitStageByResources(t *testing.T) {
+	oldRev := v1.TargetRevision{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+		PodResources:  &v1.PodResources{CPUMilliValue: 500, MemoryValue: 256},
+	}
+	tests := []struct {
+		name           string
+		deltaReplicas  int32
+		startRevisions []v1.TargetRevision
+		revUp          *v1.TargetRevision
+		ExpectedResult int32
+	}{{
+		name:           "Test the new revision without requests",
+		deltaReplicas:  4,
+		startRevisions: []v1.TargetRevision{oldRev},
+		revUp:          &v1.TargetRevision{},
+		ExpectedResult: 4,
+	}, {
+		name:           "Test the new revision requesting the same resources",
+		deltaReplicas:  2,
+		startRevisions: []v1.TargetRevision{oldRev},
+		revUp:          &v1.TargetRevision{PodResources: &v1.PodResources{CPUMilliValue: 500, MemoryValue: 256}},
+		ExpectedResult: 2,
+	}, {
+		name:           "Test the new revision requesting twice the cpu",
+		deltaReplicas:  4,
+		startRevisions: []v1.TargetRevision{oldRev},
+		revUp:          &v1.TargetRevision{PodResources: &v1.PodResources{CPUMilliValue: 1000, MemoryValue: 256}},
+		ExpectedResult: 2,
+	}, {
+		name:           "Test the new revision requesting four times the memory",
+		deltaReplicas:  4,
+		startRevisions: []v1.TargetRevision{oldRev},
+		revUp:          &v1.TargetRevision{PodResources: &v1.PodResources{CPUMilliValue: 500, MemoryValue: 1024}},
+		ExpectedResult: 1,
+	}, {
+		name:           "Test the new revision requesting much more resources keeps one replica",
+		deltaReplicas:  2,
+		startRevisions: []v1.TargetRevision{oldRev},
+		revUp:          &v1.TargetRevision{PodResources: &v1.PodResources{CPUMilliValue: 10000}},
+		ExpectedResult: 1,
+	}, {
+		name:          "Test the old revision without requests",
+		deltaReplicas: 2,
+		startRevisions: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+		}},
+		revUp:          &v1.TargetRevision{PodResources: &v1.PodResources{CPUMilliValue: 10000}},
+		ExpectedResult: 2,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The old revision runs 20 replicas, and the budget is 20% of its resources, i.e. 4 replicas.
+			r := limitStageByResources(test.deltaReplicas, 20, test.startRevisions, map[string]int32{"rev-001": 20},
+				test.revUp, nil)
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of limitStageByResources() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestGetStageWarmUpReplicas(t *testing.T) {
+	tests := []struct {
+		name                string
+		startRevisions      []v1.TargetRevision
+		stageRevisionTarget []v1.TargetRevision
+		ExpectedResult      int32
+	}{{
+		name: "Test the new revision taking the first traffic share",
+		startRevisions: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+		}},
+		stageRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20)},
+		}},
+		ExpectedResult: 2,
+	}, {
+		name: "Test the new revision bounded by its maxScale",
+		startRevisions: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(50)},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(50)},
+		}},
+		stageRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(30)},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(70)},
+			MaxScale:      ptr.Int32(10),
+		}},
+		ExpectedResult: 10,
+	}, {
+		name: "Test the new revision with twice the capacity",
+		startRevisions: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+			PodCapacity:   &v1.PodCapacity{Metric: "concurrency", MilliValue: 100000},
+		}},
+		stageRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(50)},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(50)},
+			PodCapacity:   &v1.PodCapacity{Metric: "concurrency", MilliValue: 200000},
+		}},
+		ExpectedResult: 2,
+	}, {
+		name: "Test the new revision without traffic",
+		startRevisions: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+		}},
+		stageRevisionTarget: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(0)},
+		}},
+		ExpectedResult: 0,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := getStageWarmUpReplicas(test.startRevisions, test.stageRevisionTarget, "rev-002",
+				&MockPodAutoscalerDoubleRevs{})
+			if r != test.ExpectedResult {
+				t.Fatalf("Result of getStageWarmUpReplicas() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestUpdateStageWarmUp(t *testing.T) {
+	now := time.Now()
+	stage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+		Direction:     v1.DirectionDown,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20)},
+		Direction:     v1.DirectionUp,
+	}}
+	tests := []struct {
+		name           string
+		config         *RolloutConfig
+		strategy       string
+		stageReady     bool
+		readySince     time.Duration
+		statusStage    []v1.TargetRevision
+		ExpectedResult *v1.StageWarmUp
+	}{{
+		name:           "Test the warm-up disabled",
+		config:         &RolloutConfig{StageWarmUpStabilizationSeconds: 60},
+		strategy:       strategies.AvailabilityStrategy,
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the warm-up with the resourceUtil strategy",
+		config:         &RolloutConfig{StageWarmUpEnabled: true, StageWarmUpStabilizationSeconds: 60},
+		strategy:       strategies.ResourceUtilStrategy,
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the warm-up kept for the stage in progress",
+		config:         &RolloutConfig{StageWarmUpEnabled: true, StageWarmUpStabilizationSeconds: 60},
+		strategy:       strategies.AvailabilityStrategy,
+		ExpectedResult: &v1.StageWarmUp{Replicas: 3, StabilizationSeconds: 60},
+	}, {
+		name:           "Test the warm-up kept within the stabilization window",
+		config:         &RolloutConfig{StageWarmUpEnabled: true, StageWarmUpStabilizationSeconds: 60},
+		strategy:       strategies.AvailabilityStrategy,
+		stageReady:     true,
+		readySince:     10 * time.Second,
+		statusStage:    stage,
+		ExpectedResult: &v1.StageWarmUp{Replicas: 3, StabilizationSeconds: 60},
+	}, {
+		name:           "Test the warm-up released after the stabilization window",
+		config:         &RolloutConfig{StageWarmUpEnabled: true, StageWarmUpStabilizationSeconds: 60},
+		strategy:       strategies.AvailabilityStrategy,
+		stageReady:     true,
+		readySince:     2 * time.Minute,
+		statusStage:    stage,
+		ExpectedResult: &v1.StageWarmUp{Replicas: 0, StabilizationSeconds: 60},
+	}, {
+		name:           "Test the warm-up kept for the stage not accomplished yet",
+		config:         &RolloutConfig{StageWarmUpEnabled: true, StageWarmUpStabilizationSeconds: 60},
+		strategy:       strategies.AvailabilityStrategy,
+		stageReady:     true,
+		readySince:     2 * time.Minute,
+		statusStage:    stage[:1],
+		ExpectedResult: &v1.StageWarmUp{Replicas: 3, StabilizationSeconds: 60},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				Spec: v1.RolloutOrchestratorSpec{
+					StageTarget: v1.StageTarget{
+						StageTargetRevisions: stage,
+						RolloutStrategy:      test.strategy,
+						StageWarmUp:          &v1.StageWarmUp{Replicas: 3, StabilizationSeconds: 30},
+					},
+				},
+			}
+			ro.Status.InitializeConditions()
+			ro.Status.SetStageRevisionStatus(test.statusStage)
+			if test.stageReady {
+				ro.Status.MarkStageRevisionReady()
+				for i := range ro.Status.Conditions {
+					if ro.Status.Conditions[i].Type == v1.SOStageReady {
+						ro.Status.Conditions[i].LastTransitionTime = apis.VolatileTime{
+							Inner: metav1.NewTime(now.Add(-test.readySince))}
+					}
+				}
+			}
+			updateStageWarmUp(ro, test.config, now)
+			if !reflect.DeepEqual(ro.Spec.StageWarmUp, test.ExpectedResult) {
+				t.Fatalf("Result of updateStageWarmUp() = %v, want %v", ro.Spec.StageWarmUp, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestGetRollbackStageTargetRevisions(t *testing.T) {
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+				TargetReplicas: ptr.Int32(4),
+			}},
+			StageTarget: v1.StageTarget{
+				StageTargetRevisions: []v1.TargetRevision{{
+					TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+					Direction:      v1.DirectionDown,
+					TargetReplicas: ptr.Int32(3),
+				}, {
+					TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20),
+						LatestRevision: ptr.Bool(true)},
+					Direction:      v1.DirectionUp,
+					TargetReplicas: ptr.Int32(1),
+				}},
+			},
+		},
+	}
+	expected := []v1.TargetRevision{{
+		TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+		Direction:      v1.DirectionUp,
+		TargetReplicas: ptr.Int32(4),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(0),
+			LatestRevision: ptr.Bool(false)},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(0),
+	}}
+	if got := getRollbackStageTargetRevisions(ro); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Result of getRollbackStageTargetRevisions() = %v, want %v", got, expected)
+	}
+
+	// Rolling back the rolled back stage changes nothing.
+	ro.Spec.StageTargetRevisions = expected
+	if got := getRollbackStageTargetRevisions(ro); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Result of getRollbackStageTargetRevisions() = %v, want %v", got, expected)
+	}
+}
+
+func TestUpdateRolloutOrchestratorRolloutGroup(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(4),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20),
+			LatestRevision: ptr.Bool(true)},
+		Direction:      v1.DirectionUp,
+		TargetReplicas: ptr.Int32(1),
+	}}
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		expectedStage []v1.TargetRevision
+	}{{
+		name:          "held by the group",
+		annotations:   map[string]string{resources.RolloutGroupHold: "group"},
+		expectedStage: stage,
+	}, {
+		name:          "held by the pause",
+		annotations:   map[string]string{resources.RolloutPaused: "admin"},
+		expectedStage: stage,
+	}, {
+		name:          "promoted, but held by the pause",
+		annotations:   map[string]string{resources.RolloutPromoted: "admin", resources.RolloutPaused: "admin"},
+		expectedStage: stage,
+	}, {
+		name:          "aborted rollout of other revisions",
+		annotations:   map[string]string{resources.RolloutAborted: "rev-003", resources.RolloutPaused: "admin"},
+		expectedStage: stage,
+	}, {
+		name:        "aborted rollout",
+		annotations: map[string]string{resources.RolloutAborted: "rev-002"},
+		expectedStage: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+				LatestRevision: ptr.Bool(false)},
+			Direction: v1.DirectionUp,
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(0),
+				LatestRevision: ptr.Bool(false)},
+			Direction:      v1.DirectionDown,
+			TargetReplicas: ptr.Int32(0),
+		}},
+	}, {
+		name:        "rolled back by the group",
+		annotations: map[string]string{resources.RolloutGroupRollback: "group"},
+		expectedStage: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+				LatestRevision: ptr.Bool(false)},
+			Direction: v1.DirectionUp,
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(0),
+				LatestRevision: ptr.Bool(false)},
+			Direction:      v1.DirectionDown,
+			TargetReplicas: ptr.Int32(0),
+		}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(false)},
+					}},
+					TargetRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(true)},
+					}},
+					StageTarget: v1.StageTarget{StageTargetRevisions: stage},
+				},
+			}
+			ro.Status.InitializeConditions()
+			ro.Status.MarkStageRevisionReady()
+			ro.Status.MarkLastStageRevisionInComplete()
+			config := &RolloutConfig{ProgressiveRolloutEnabled: true, StageRolloutTimeoutMinutes: 2}
+			if err := updateRolloutOrchestrator(ro, nil, nil, config); err != nil {
+				t.Fatalf("updateRolloutOrchestrator() = %v", err)
+			}
+			if !reflect.DeepEqual(ro.Spec.StageTargetRevisions, test.expectedStage) {
+				t.Fatalf("Result of updateRolloutOrchestrator() = %v, want %v", ro.Spec.StageTargetRevisions,
+					test.expectedStage)
+			}
+		})
+	}
+}
+
+func TestUpdatePostRolloutStandby(t *testing.T) {
+	now := time.Now()
+	stage := []v1.TargetRevision{{
+		TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001"},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(0),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100)},
+		Direction:     v1.DirectionUp,
+	}}
+	tests := []struct {
+		name           string
+		config         *RolloutConfig
+		complete       bool
+		completeSince  time.Duration
+		ExpectedResult *v1.PostRolloutStandby
+	}{{
+		name:           "Test the standby disabled",
+		config:         &RolloutConfig{PostRolloutStandbySeconds: 600},
+		ExpectedResult: nil,
+	}, {
+		name:           "Test the standby kept for the rollout in progress",
+		config:         &RolloutConfig{PostRolloutStandbyReplicas: 2, PostRolloutStandbySeconds: 600},
+		ExpectedResult: &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+	}, {
+		name:           "Test the standby kept within the bake period",
+		config:         &RolloutConfig{PostRolloutStandbyReplicas: 2, PostRolloutStandbySeconds: 600},
+		complete:       true,
+		completeSince:  time.Minute,
+		ExpectedResult: &v1.PostRolloutStandby{Replicas: 2, BakeSeconds: 600},
+	}, {
+		name:           "Test the standby released after the bake period",
+		config:         &RolloutConfig{PostRolloutStandbyReplicas: 2, PostRolloutStandbySeconds: 600},
+		complete:       true,
+		completeSince:  20 * time.Minute,
+		ExpectedResult: &v1.PostRolloutStandby{Replicas: 0, BakeSeconds: 600},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				Spec: v1.RolloutOrchestratorSpec{
+					TargetRevisions: stage[1:],
+					StageTarget: v1.StageTarget{
+						StageTargetRevisions: stage,
+						PostRolloutStandby:   &v1.PostRolloutStandby{Replicas: 1, BakeSeconds: 60},
+					},
+				},
+			}
+			ro.Status.InitializeConditions()
+			if test.complete {
+				ro.Status.MarkLastStageRevisionComplete()
+				for i := range ro.Status.Conditions {
+					if ro.Status.Conditions[i].Type == v1.SOLastStageComplete {
+						ro.Status.Conditions[i].LastTransitionTime = apis.VolatileTime{
+							Inner: metav1.NewTime(now.Add(-test.completeSince))}
+					}
+				}
+			}
+			updatePostRolloutStandby(ro, test.config, now)
+			if !reflect.DeepEqual(ro.Spec.PostRolloutStandby, test.ExpectedResult) {
+				t.Fatalf("Result of updatePostRolloutStandby() = %v, want %v", ro.Spec.PostRolloutStandby,
+					test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestUpdateRolloutOrchestratorRevert(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001", LatestRevision: ptr.Bool(false)},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(0),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100),
+			LatestRevision: ptr.Bool(true)},
+		Direction: v1.DirectionUp,
+	}}
+	rollbackStage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+			LatestRevision: ptr.Bool(false)},
+		Direction: v1.DirectionUp,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(0),
+			LatestRevision: ptr.Bool(false)},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(0),
+	}}
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		standbyReplicas int
+		completeSince   time.Duration
+		expectedStage   []v1.TargetRevision
+	}{{
+		name:            "reverted on standby",
+		annotations:     map[string]string{resources.RolloutReverted: "rev-002"},
+		standbyReplicas: 2,
+		completeSince:   time.Minute,
+		expectedStage:   rollbackStage,
+	}, {
+		name:            "reverted after the bake period",
+		annotations:     map[string]string{resources.RolloutReverted: "rev-002"},
+		standbyReplicas: 2,
+		completeSince:   20 * time.Minute,
+		expectedStage:   stage,
+	}, {
+		name:          "reverted without standby",
+		annotations:   map[string]string{resources.RolloutReverted: "rev-002"},
+		completeSince: time.Minute,
+		expectedStage: stage,
+	}, {
+		name:            "reverted rollout of other revisions",
+		annotations:     map[string]string{resources.RolloutReverted: "rev-003"},
+		standbyReplicas: 2,
+		completeSince:   time.Minute,
+		expectedStage:   stage,
+	}, {
+		name:            "aborted after the last stage",
+		annotations:     map[string]string{resources.RolloutAborted: "rev-002"},
+		standbyReplicas: 2,
+		completeSince:   time.Minute,
+		expectedStage:   stage,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(false)},
+					}},
+					TargetRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(true)},
+					}},
+					StageTarget: v1.StageTarget{StageTargetRevisions: stage},
+				},
+			}
+			ro.Status.InitializeConditions()
+			ro.Status.MarkStageRevisionReady()
+			ro.Status.MarkLastStageRevisionComplete()
+			for i := range ro.Status.Conditions {
+				if ro.Status.Conditions[i].Type == v1.SOLastStageComplete {
+					ro.Status.Conditions[i].LastTransitionTime = apis.VolatileTime{
+						Inner: metav1.NewTime(time.Now().Add(-test.completeSince))}
+				}
+			}
+			config := &RolloutConfig{ProgressiveRolloutEnabled: true, StageRolloutTimeoutMinutes: 2,
+				PostRolloutStandbyReplicas: test.standbyReplicas, PostRolloutStandbySeconds: 600}
+			if err := updateRolloutOrchestrator(ro, nil, nil, config); err != nil {
+				t.Fatalf("updateRolloutOrchestrator() = %v", err)
+			}
+			if !reflect.DeepEqual(ro.Spec.StageTargetRevisions, test.expectedStage) {
+				t.Fatalf("Result of updateRolloutOrchestrator() = %v, want %v", ro.Spec.StageTargetRevisions,
+					test.expectedStage)
+			}
+		})
+	}
+}
+
+func TestUpdateRolloutOrchestratorPromotion(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80),
+			LatestRevision: ptr.Bool(false)},
+		Direction: v1.DirectionDown,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-003", Percent: ptr.Int64(20),
+			LatestRevision: ptr.Bool(true)},
+		Direction: v1.DirectionUp,
+	}}
+	target := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-003", Percent: ptr.Int64(100),
+			LatestRevision: ptr.Bool(true)},
+	}}
+	promotedStage := []v1.TargetRevision{{
+		TrafficTarget:  servingv1.TrafficTarget{RevisionName: "rev-001", LatestRevision: ptr.Bool(false)},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(0),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-003", Percent: ptr.Int64(100),
+			LatestRevision: ptr.Bool(true)},
+		Direction: v1.DirectionUp,
+		// The 4 replicas of rev-001 carry 80% of the traffic, so 5 replicas are needed for all the traffic.
+		TargetReplicas: ptr.Int32(5),
+	}}
+	tests := []struct {
+		name             string
+		annotations      map[string]string
+		promotion        *v1.RolloutPromotion
+		stage            []v1.TargetRevision
+		status           []v1.TargetRevision
+		expectedStage    []v1.TargetRevision
+		expectedPromoted bool
+	}{{
+		name:             "promoted in the middle of the stage",
+		annotations:      map[string]string{resources.RolloutPromotedToFinal: "admin"},
+		stage:            stage,
+		status:           stage,
+		expectedStage:    promotedStage,
+		expectedPromoted: true,
+	}, {
+		name: "promoted while paused",
+		annotations: map[string]string{resources.RolloutPromotedToFinal: "admin",
+			resources.RolloutPaused: "admin"},
+		stage:            stage,
+		status:           stage,
+		expectedStage:    stage,
+		expectedPromoted: true,
+	}, {
+		name:          "promoted after the last stage",
+		annotations:   map[string]string{resources.RolloutPromotedToFinal: "admin"},
+		stage:         target,
+		status:        target,
+		expectedStage: target,
+	}, {
+		name:             "promoted to the final stage in progress",
+		promotion:        &v1.RolloutPromotion{PromotedBy: "admin"},
+		stage:            promotedStage,
+		status:           stage,
+		expectedStage:    promotedStage,
+		expectedPromoted: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(false)},
+					}},
+					TargetRevisions: target,
+					StageTarget:     v1.StageTarget{StageTargetRevisions: test.stage, Promotion: test.promotion},
+				},
+			}
+			ro.Status.InitializeConditions()
+			ro.Status.SetStageRevisionStatus(test.status)
+			config := &RolloutConfig{ProgressiveRolloutEnabled: true, StageRolloutTimeoutMinutes: 2}
+			if err := updateRolloutOrchestrator(ro, &MockPodAutoscalerLister{}, nil, config); err != nil {
+				t.Fatalf("updateRolloutOrchestrator() = %v", err)
+			}
+			if !reflect.DeepEqual(ro.Spec.StageTargetRevisions, test.expectedStage) {
+				t.Fatalf("Result of updateRolloutOrchestrator() = %v, want %v", ro.Spec.StageTargetRevisions,
+					test.expectedStage)
+			}
+			if _, found := ro.Annotations[resources.RolloutPromotedToFinal]; found {
+				t.Fatalf("Result of updateRolloutOrchestrator() keeps the annotation %s",
+					resources.RolloutPromotedToFinal)
+			}
+			promoted := ro.Spec.Promotion != nil && ro.Spec.Promotion.PromotedBy == "admin"
+			if promoted != test.expectedPromoted {
+				t.Fatalf("Result of updateRolloutOrchestrator() Promotion = %v, want promoted %v", ro.Spec.Promotion,
+					test.expectedPromoted)
+			}
+		})
+	}
+}
+
 func TestUpdateStageTargetRevisions(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
@@ -776,6 +1398,170 @@ func TestUpdateStageTargetRevisions(t *testing.T) {
 	}
 }
 
+func TestCalculateStageTargetRevisionsWithCapacity(t *testing.T) {
+	oldCapacity := &v1.PodCapacity{Metric: autoscaling.Concurrency, MilliValue: 70000}
+	newCapacity := &v1.PodCapacity{Metric: autoscaling.Concurrency, MilliValue: 35000}
+	startRevisions := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{
+			RevisionName:   "rev-001",
+			LatestRevision: ptr.Bool(true),
+			Percent:        ptr.Int64(100),
+		},
+		PodCapacity: oldCapacity,
+	}}
+	tests := []struct {
+		name            string
+		targetCapacity  *v1.PodCapacity
+		ExpectedTargets []v1.TargetRevision
+	}{{
+		name:           "Test with the same capacity for both revisions",
+		targetCapacity: oldCapacity,
+		ExpectedTargets: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{
+				RevisionName:   "rev-001",
+				LatestRevision: ptr.Bool(false),
+				Percent:        ptr.Int64(80),
+			},
+			Direction:      "down",
+			TargetReplicas: ptr.Int32(8),
+			PodCapacity:    oldCapacity,
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{
+				RevisionName:   "rev-002",
+				LatestRevision: ptr.Bool(true),
+				Percent:        ptr.Int64(20),
+			},
+			Direction:      "up",
+			TargetReplicas: ptr.Int32(2),
+			PodCapacity:    oldCapacity,
+		}},
+	}, {
+		name:           "Test with the new revision handling half of the load per replica",
+		targetCapacity: newCapacity,
+		ExpectedTargets: []v1.TargetRevision{{
+			TrafficTarget: servingv1.TrafficTarget{
+				RevisionName:   "rev-001",
+				LatestRevision: ptr.Bool(false),
+				Percent:        ptr.Int64(80),
+			},
+			Direction:      "down",
+			TargetReplicas: ptr.Int32(8),
+			PodCapacity:    oldCapacity,
+		}, {
+			TrafficTarget: servingv1.TrafficTarget{
+				RevisionName:   "rev-002",
+				LatestRevision: ptr.Bool(true),
+				Percent:        ptr.Int64(20),
+			},
+			Direction:      "up",
+			TargetReplicas: ptr.Int32(4),
+			PodCapacity:    newCapacity,
+		}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ro := &v1.RolloutOrchestrator{
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: startRevisions,
+					TargetRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{
+							RevisionName:   "rev-002",
+							LatestRevision: ptr.Bool(true),
+							Percent:        ptr.Int64(100),
+						},
+						PodCapacity: test.targetCapacity,
+					}},
+				},
+			}
+			r := calculateStageTargetRevisions(map[string]int32{"rev-001": 10}, startRevisions, ro,
+				2, 20, 10, 100, oldCapacity)
+			if !reflect.DeepEqual(r, test.ExpectedTargets) {
+				t.Fatalf("Result of calculateStageTargetRevisions() = %v, want %v", r, test.ExpectedTargets)
+			}
+		})
+	}
+}
+
+func TestCalculateStageTargetRevisionsWithTags(t *testing.T) {
+	startRevisions := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{
+			RevisionName: "rev-001", Tag: "legacy", LatestRevision: ptr.Bool(false), Percent: ptr.Int64(10),
+		},
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{
+			RevisionName: "rev-002", Tag: "current", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(90),
+		},
+	}}
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: startRevisions,
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{
+					RevisionName: "rev-003", Tag: "next", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(100),
+				},
+			}},
+		},
+	}
+	expected := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{
+			RevisionName: "rev-001", Tag: "legacy", LatestRevision: ptr.Bool(false), Percent: ptr.Int64(10),
+		},
+		Direction: "stay",
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{
+			RevisionName: "rev-002", Tag: "current", LatestRevision: ptr.Bool(false), Percent: ptr.Int64(70),
+		},
+		Direction:      "down",
+		TargetReplicas: ptr.Int32(7),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{
+			RevisionName: "rev-003", Tag: "next", LatestRevision: ptr.Bool(true), Percent: ptr.Int64(20),
+		},
+		Direction:      "up",
+		TargetReplicas: ptr.Int32(2),
+	}}
+	r := calculateStageTargetRevisions(map[string]int32{"rev-001": 1, "rev-002": 9}, startRevisions, ro,
+		2, 20, 10, 100, nil)
+	if !reflect.DeepEqual(r, expected) {
+		t.Fatalf("Result of calculateStageTargetRevisions() = %v, want %v", r, expected)
+	}
+}
+
+func TestResolveTagConflicts(t *testing.T) {
+	target := func(name, tag string) v1.TargetRevision {
+		return v1.TargetRevision{TrafficTarget: servingv1.TrafficTarget{RevisionName: name, Tag: tag}}
+	}
+	tests := []struct {
+		name           string
+		stage          []v1.TargetRevision
+		final          []v1.TargetRevision
+		ExpectedResult []v1.TargetRevision
+	}{{
+		name:           "Test without conflicts",
+		stage:          []v1.TargetRevision{target("rev-001", "stable"), target("rev-002", "")},
+		final:          []v1.TargetRevision{target("rev-002", "")},
+		ExpectedResult: []v1.TargetRevision{target("rev-001", "stable"), target("rev-002", "")},
+	}, {
+		name:           "Test with the tag moved to the target revision",
+		stage:          []v1.TargetRevision{target("rev-001", "current"), target("rev-002", "current")},
+		final:          []v1.TargetRevision{target("rev-002", "current")},
+		ExpectedResult: []v1.TargetRevision{target("rev-001", ""), target("rev-002", "current")},
+	}, {
+		name:           "Test with the tag held by the revisions scaling down",
+		stage:          []v1.TargetRevision{target("rev-001", "old"), target("rev-002", "old"), target("rev-003", "")},
+		final:          []v1.TargetRevision{target("rev-003", "")},
+		ExpectedResult: []v1.TargetRevision{target("rev-001", "old"), target("rev-002", ""), target("rev-003", "")},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := resolveTagConflicts(test.stage, test.final)
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of resolveTagConflicts() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
 var (
 	MockRolloutOrchestrator = &v1.RolloutOrchestrator{
 		Spec: v1.RolloutOrchestratorSpec{
@@ -1155,6 +1941,56 @@ func TestTransformService(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "Test without the target revisions",
+		spaLister: MockSPALister{
+			ActualScale: ptr.Int32(2),
+		},
+		rc: &RolloutConfig{
+			ProgressiveRolloutEnabled:  true,
+			ProgressiveRolloutStrategy: strategies.AvailabilityStrategy,
+			CandidateTag:               "candidate",
+			StableTag:                  "stable",
+		},
+		service: &servingv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-name",
+				Namespace: "test-ns",
+			},
+			Spec: servingv1.ServiceSpec{
+				RouteSpec: servingv1.RouteSpec{
+					Traffic: []servingv1.TrafficTarget{{
+						ConfigurationName: "test-name",
+						Percent:           ptr.Int64(100),
+						LatestRevision:    ptr.Bool(true),
+					}},
+				},
+			},
+		},
+		ro: &v1.RolloutOrchestrator{
+			Spec: v1.RolloutOrchestratorSpec{
+				StageTarget: v1.StageTarget{
+					StageTargetRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "test-name-00001", Percent: ptr.Int64(100)},
+					}},
+				},
+			},
+		},
+		ExpectedService: &servingv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-name",
+				Namespace: "test-ns",
+			},
+			Spec: servingv1.ServiceSpec{
+				RouteSpec: servingv1.RouteSpec{
+					Traffic: []servingv1.TrafficTarget{{
+						ConfigurationName: "test-name",
+						Percent:           ptr.Int64(100),
+						LatestRevision:    ptr.Bool(true),
+					}},
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
@@ -1271,3 +2107,706 @@ func (routeLister MockRouteListerWithNewRev) Get(_ string) (*servingv1.Route, er
 		},
 	}, nil
 }
+
+func TestDeleteRolloutOrchestrator(t *testing.T) {
+	service := &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default", UID: "uid"},
+	}
+	ro := &v1.RolloutOrchestrator{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "svc",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(service)},
+		},
+	}
+	spa := &v1.StagePodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc-00001",
+			Namespace: "default",
+			Labels:    map[string]string{serving.ServiceLabelKey: "svc"},
+		},
+	}
+	client := fakeclientset.NewSimpleClientset(ro, spa)
+	roIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	roIndexer.Add(ro)
+	spaIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	spaIndexer.Add(spa)
+	c := &Reconciler{
+		client:                    client,
+		rolloutOrchestratorLister: listers.NewRolloutOrchestratorLister(roIndexer),
+		spaLister:                 listers.NewStagePodAutoscalerLister(spaIndexer),
+	}
+	ctx := controller.WithEventRecorder(context.Background(), record.NewFakeRecorder(10))
+
+	if err := c.deleteRolloutOrchestrator(ctx, service); err != nil {
+		t.Fatalf("deleteRolloutOrchestrator() = %v", err)
+	}
+	if _, err := client.ServingV1().RolloutOrchestrators("default").Get(ctx, "svc", metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Fatalf("Result of deleteRolloutOrchestrator() RolloutOrchestrator error = %v, want not found", err)
+	}
+	if _, err := client.ServingV1().StagePodAutoscalers("default").Get(ctx, "svc-00001", metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Fatalf("Result of deleteRolloutOrchestrator() StagePodAutoscaler error = %v, want not found", err)
+	}
+
+	// The RolloutOrchestrator not owned by the service is kept.
+	ro.OwnerReferences = nil
+	if err := c.deleteRolloutOrchestrator(ctx, service); err == nil {
+		t.Fatalf("deleteRolloutOrchestrator() = nil, want an error for the RolloutOrchestrator not owned by the service")
+	}
+}
+
+func TestHandleTrafficEdit(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+		Direction:     v1.DirectionDown,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20)},
+		Direction:     v1.DirectionUp,
+	}}
+	target := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100)},
+	}}
+	edited := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(50)},
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(50)},
+	}}
+	tests := []struct {
+		name              string
+		policy            string
+		stage             []v1.TargetRevision
+		rejected          bool
+		expectedTarget    []v1.TargetRevision
+		expectedInitial   []v1.TargetRevision
+		expectedEvent     string
+		expectedCondition bool
+	}{{
+		name:           "Test the traffic edited without a rollout",
+		policy:         resources.TrafficEditPolicyReject,
+		expectedTarget: edited,
+	}, {
+		name:           "Test the traffic edited with the restart policy",
+		policy:         resources.TrafficEditPolicyRestart,
+		stage:          stage,
+		expectedTarget: edited,
+		expectedEvent:  "Normal TrafficEditRestarted",
+	}, {
+		name:            "Test the traffic edited with the adopt policy",
+		policy:          resources.TrafficEditPolicyAdopt,
+		stage:           stage,
+		expectedTarget:  edited,
+		expectedInitial: stage,
+		expectedEvent:   "Normal TrafficEditAdopted",
+	}, {
+		name:              "Test the traffic edited with the reject policy",
+		policy:            resources.TrafficEditPolicyReject,
+		stage:             stage,
+		expectedTarget:    target,
+		expectedEvent:     "Warning TrafficEditRejected",
+		expectedCondition: true,
+	}, {
+		name:              "Test the traffic edited with the reject policy rejected before",
+		policy:            resources.TrafficEditPolicyReject,
+		stage:             stage,
+		rejected:          true,
+		expectedTarget:    target,
+		expectedCondition: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+			if test.rejected {
+				service.GetConditionSet().Manage(&service.Status).MarkFalse(resources.TrafficEditAccepted,
+					"RolloutInProgress", "")
+			}
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+					}},
+					TargetRevisions: target,
+					StageTarget:     v1.StageTarget{StageTargetRevisions: test.stage},
+				},
+			}
+			ro.Status.SetStageRevisionStatus(test.stage)
+			expectedInitial := ro.Spec.InitialRevisions
+			if test.expectedInitial != nil {
+				expectedInitial = test.expectedInitial
+			}
+			recorder := record.NewFakeRecorder(10)
+			c := &Reconciler{rolloutConfig: &RolloutConfig{TrafficEditPolicy: test.policy}}
+
+			r := c.handleTrafficEdit(controller.WithEventRecorder(context.Background(), recorder), service, service, ro,
+				edited)
+			if !reflect.DeepEqual(r, test.expectedTarget) {
+				t.Fatalf("Result of handleTrafficEdit() = %v, want %v", r, test.expectedTarget)
+			}
+			if !reflect.DeepEqual(ro.Spec.InitialRevisions, expectedInitial) {
+				t.Fatalf("Result of handleTrafficEdit() InitialRevisions = %v, want %v", ro.Spec.InitialRevisions,
+					expectedInitial)
+			}
+			event := ""
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if !strings.HasPrefix(event, test.expectedEvent) || (test.expectedEvent == "" && event != "") {
+				t.Fatalf("Result of handleTrafficEdit() event = %q, want %q", event, test.expectedEvent)
+			}
+			cond := service.Status.GetCondition(resources.TrafficEditAccepted)
+			if rejected := cond != nil && cond.IsFalse(); rejected != test.expectedCondition {
+				t.Fatalf("Result of handleTrafficEdit() rejected = %v, want %v", rejected, test.expectedCondition)
+			}
+		})
+	}
+}
+
+func TestHandleRouteTrafficEdit(t *testing.T) {
+	ro := &v1.RolloutOrchestrator{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100)},
+			}},
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100)},
+			}},
+		},
+	}
+	syncedRoute := &servingv1.Route{
+		Spec: servingv1.RouteSpec{
+			Traffic: []servingv1.TrafficTarget{{RevisionName: "rev-001", Percent: ptr.Int64(100)}},
+		},
+	}
+	editedRoute := &servingv1.Route{
+		Spec: servingv1.RouteSpec{
+			Traffic: []servingv1.TrafficTarget{{RevisionName: "rev-003", Percent: ptr.Int64(100)}},
+		},
+	}
+	tests := []struct {
+		name              string
+		route             *servingv1.Route
+		reverted          bool
+		expectedEvent     string
+		expectedCondition bool
+	}{{
+		name:  "Test the route in sync with the rollout",
+		route: syncedRoute,
+	}, {
+		name:              "Test the route edited out of band",
+		route:             editedRoute,
+		expectedEvent:     "Warning RouteTrafficEditReverted",
+		expectedCondition: true,
+	}, {
+		name:              "Test the route edited out of band reverted before",
+		route:             editedRoute,
+		reverted:          true,
+		expectedCondition: true,
+	}, {
+		name:     "Test the route reverted",
+		route:    syncedRoute,
+		reverted: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+			if test.reverted {
+				service.GetConditionSet().Manage(&service.Status).MarkFalse(resources.RouteTrafficInSync,
+					"RouteTrafficEditReverted", "")
+			}
+			recorder := record.NewFakeRecorder(10)
+			c := &Reconciler{}
+
+			c.handleRouteTrafficEdit(controller.WithEventRecorder(context.Background(), recorder), service, service,
+				test.route, ro)
+			event := ""
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if !strings.HasPrefix(event, test.expectedEvent) || (test.expectedEvent == "" && event != "") {
+				t.Fatalf("Result of handleRouteTrafficEdit() event = %q, want %q", event, test.expectedEvent)
+			}
+			cond := service.Status.GetCondition(resources.RouteTrafficInSync)
+			if reverting := cond != nil && cond.IsFalse(); reverting != test.expectedCondition {
+				t.Fatalf("Result of handleRouteTrafficEdit() condition = %v, want %v", cond, test.expectedCondition)
+			}
+		})
+	}
+}
+
+func TestHandleNewRevision(t *testing.T) {
+	stage := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "config-00001", Percent: ptr.Int64(80)},
+		Direction:     v1.DirectionDown,
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "config-00002", LatestRevision: ptr.Bool(true),
+			Percent: ptr.Int64(20)},
+		Direction: v1.DirectionUp,
+	}}
+	target := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "config-00002", LatestRevision: ptr.Bool(true),
+			Percent: ptr.Int64(100)},
+	}}
+	newTarget := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "config-00003", LatestRevision: ptr.Bool(true),
+			Percent: ptr.Int64(100)},
+	}}
+	namedTarget := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "config-blue", LatestRevision: ptr.Bool(true),
+			Percent: ptr.Int64(100)},
+	}}
+	tests := []struct {
+		name                string
+		policy              string
+		stage               []v1.TargetRevision
+		ultimate            []v1.TargetRevision
+		created             time.Duration
+		superseding         *v1.RolloutSuperseding
+		expectedTarget      []v1.TargetRevision
+		expectedHeld        bool
+		expectedEvent       string
+		expectedCondition   string
+		expectedEnqueue     bool
+		expectedSuperseding *v1.RolloutSuperseding
+	}{{
+		name:           "Test the new revision without a rollout",
+		policy:         resources.SupersedingPolicyQueue,
+		ultimate:       newTarget,
+		superseding:    &v1.RolloutSuperseding{Policy: resources.SupersedingPolicyQueue, Revision: "config-00002"},
+		expectedTarget: newTarget,
+	}, {
+		name:           "Test the rollout without a new revision",
+		policy:         resources.SupersedingPolicyQueue,
+		stage:          stage,
+		ultimate:       target,
+		expectedTarget: target,
+	}, {
+		name:     "Test the rollout of the revision superseding the previous rollout",
+		policy:   resources.SupersedingPolicySupersede,
+		stage:    stage,
+		ultimate: target,
+		superseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicySupersede,
+			Revision: "config-00002", SupersededRevision: "config-00001"},
+		expectedTarget:    target,
+		expectedCondition: "Superseded",
+		expectedSuperseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicySupersede,
+			Revision: "config-00002", SupersededRevision: "config-00001"},
+	}, {
+		name:              "Test the new revision with the supersede policy",
+		policy:            resources.SupersedingPolicySupersede,
+		stage:             stage,
+		ultimate:          newTarget,
+		expectedTarget:    newTarget,
+		expectedEvent:     "Normal RolloutSuperseded",
+		expectedCondition: "Superseded",
+		expectedSuperseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicySupersede,
+			Revision: "config-00003", SupersededRevision: "config-00002"},
+	}, {
+		name:              "Test the new revision with the queue policy",
+		policy:            resources.SupersedingPolicyQueue,
+		stage:             stage,
+		ultimate:          newTarget,
+		expectedTarget:    target,
+		expectedHeld:      true,
+		expectedEvent:     "Normal RolloutQueued",
+		expectedCondition: "Queued",
+		expectedSuperseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicyQueue,
+			Revision: "config-00003"},
+	}, {
+		name:              "Test the new revision already queued",
+		policy:            resources.SupersedingPolicyQueue,
+		stage:             stage,
+		ultimate:          newTarget,
+		superseding:       &v1.RolloutSuperseding{Policy: resources.SupersedingPolicyQueue, Revision: "config-00003"},
+		expectedTarget:    target,
+		expectedHeld:      true,
+		expectedCondition: "Queued",
+		expectedSuperseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicyQueue,
+			Revision: "config-00003"},
+	}, {
+		name:              "Test the new revision with the debounce policy in the debounce period",
+		policy:            resources.SupersedingPolicyDebounce,
+		stage:             stage,
+		ultimate:          newTarget,
+		created:           10 * time.Second,
+		expectedTarget:    target,
+		expectedHeld:      true,
+		expectedEvent:     "Normal RolloutDebounced",
+		expectedCondition: "Debounced",
+		expectedEnqueue:   true,
+		expectedSuperseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicyDebounce,
+			Revision: "config-00003"},
+	}, {
+		name:              "Test the new revision with the debounce policy after the debounce period",
+		policy:            resources.SupersedingPolicyDebounce,
+		stage:             stage,
+		ultimate:          newTarget,
+		created:           time.Minute,
+		expectedTarget:    newTarget,
+		expectedEvent:     "Normal RolloutSuperseded",
+		expectedCondition: "Superseded",
+		expectedSuperseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicyDebounce,
+			Revision: "config-00003", SupersededRevision: "config-00002"},
+	}, {
+		name:              "Test the new revision named by the user with the debounce policy after the debounce period",
+		policy:            resources.SupersedingPolicyDebounce,
+		stage:             stage,
+		ultimate:          namedTarget,
+		created:           time.Minute,
+		expectedTarget:    namedTarget,
+		expectedEvent:     "Normal RolloutSuperseded",
+		expectedCondition: "Superseded",
+		expectedSuperseding: &v1.RolloutSuperseding{Policy: resources.SupersedingPolicyDebounce,
+			Revision: "config-blue", SupersededRevision: "config-00002"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+			config := &servingv1.Configuration{
+				ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default", Generation: 3},
+			}
+			revIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, rev := range test.ultimate {
+				revIndexer.Add(&servingv1.Revision{ObjectMeta: metav1.ObjectMeta{Name: rev.RevisionName,
+					Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-test.created))}})
+			}
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "config-00001", Percent: ptr.Int64(100)},
+					}},
+					TargetRevisions: target,
+					StageTarget: v1.StageTarget{StageTargetRevisions: test.stage,
+						Superseding: test.superseding},
+				},
+			}
+			ro.Status.SetStageRevisionStatus(test.stage)
+			recorder := record.NewFakeRecorder(10)
+			enqueued := false
+			c := &Reconciler{
+				revisionLister: servinglisters.NewRevisionLister(revIndexer),
+				rolloutConfig: &RolloutConfig{SupersedingPolicy: test.policy,
+					SupersedingDebounceSeconds: resources.DefaultSupersedingDebounceSeconds},
+				enqueueAfter: func(interface{}, time.Duration) { enqueued = true },
+			}
+
+			r, held := c.handleNewRevision(controller.WithEventRecorder(context.Background(), recorder), service,
+				service, config, ro, test.ultimate)
+			if !reflect.DeepEqual(r, test.expectedTarget) {
+				t.Fatalf("Result of handleNewRevision() = %v, want %v", r, test.expectedTarget)
+			}
+			if held != test.expectedHeld {
+				t.Fatalf("Result of handleNewRevision() held = %v, want %v", held, test.expectedHeld)
+			}
+			event := ""
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if !strings.HasPrefix(event, test.expectedEvent) || (test.expectedEvent == "" && event != "") {
+				t.Fatalf("Result of handleNewRevision() event = %q, want %q", event, test.expectedEvent)
+			}
+			reason := ""
+			if cond := service.Status.GetCondition(resources.LatestRevisionRollout); cond != nil {
+				reason = cond.Reason
+			}
+			if reason != test.expectedCondition {
+				t.Fatalf("Result of handleNewRevision() reason = %q, want %q", reason, test.expectedCondition)
+			}
+			if enqueued != test.expectedEnqueue {
+				t.Fatalf("Result of handleNewRevision() enqueued = %v, want %v", enqueued, test.expectedEnqueue)
+			}
+			if !reflect.DeepEqual(ro.Spec.Superseding, test.expectedSuperseding) {
+				t.Fatalf("Result of handleNewRevision() Superseding = %v, want %v", ro.Spec.Superseding,
+					test.expectedSuperseding)
+			}
+		})
+	}
+}
+
+type MockPodAutoscalerReplicas map[string]int32
+
+func (lister MockPodAutoscalerReplicas) List(_ labels.Selector) (ret []*v1alpha1.PodAutoscaler, err error) {
+	return nil, nil
+}
+
+func (lister MockPodAutoscalerReplicas) Get(name string) (*v1alpha1.PodAutoscaler, error) {
+	return &v1alpha1.PodAutoscaler{
+		Status: v1alpha1.PodAutoscalerStatus{
+			DesiredScale: ptr.Int32(lister[name]),
+			ActualScale:  ptr.Int32(lister[name]),
+		},
+	}, nil
+}
+
+func TestSupersededRolloutStages(t *testing.T) {
+	// The rollout of rev-002 over rev-001 is in the middle, when rev-003 is created. The status lists rev-002
+	// before rev-001, so that rev-001 would be scaled down first, if the order of the status was followed.
+	status := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(40),
+			LatestRevision: ptr.Bool(true)},
+		Direction:      v1.DirectionUp,
+		TargetReplicas: ptr.Int32(4),
+	}, {
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(60),
+			LatestRevision: ptr.Bool(false)},
+		Direction:      v1.DirectionDown,
+		TargetReplicas: ptr.Int32(6),
+	}}
+	ultimate := []v1.TargetRevision{{
+		TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-003", Percent: ptr.Int64(100),
+			LatestRevision: ptr.Bool(true)},
+	}}
+	tests := []struct {
+		name           string
+		policy         string
+		ExpectedResult []string
+	}{{
+		name:   "Test the stages after the supersede policy",
+		policy: resources.SupersedingPolicySupersede,
+		ExpectedResult: []string{
+			"rev-001=60% rev-002=20% rev-003=20%",
+			"rev-001=60% rev-002=0% rev-003=40%",
+			"rev-001=40% rev-003=60%",
+			"rev-001=20% rev-003=80%",
+			"rev-001=0% rev-003=100%",
+		},
+	}, {
+		name:   "Test the stages after the debounce policy",
+		policy: resources.SupersedingPolicyDebounce,
+		ExpectedResult: []string{
+			"rev-001=60% rev-002=20% rev-003=20%",
+			"rev-001=60% rev-002=0% rev-003=40%",
+			"rev-001=40% rev-003=60%",
+			"rev-001=20% rev-003=80%",
+			"rev-001=0% rev-003=100%",
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+			config := &servingv1.Configuration{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"}}
+			ro := &v1.RolloutOrchestrator{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+				Spec: v1.RolloutOrchestratorSpec{
+					InitialRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(false)},
+					}},
+					TargetRevisions: []v1.TargetRevision{{
+						TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100),
+							LatestRevision: ptr.Bool(true)},
+					}},
+					StageTarget: v1.StageTarget{StageTargetRevisions: status},
+				},
+			}
+			ro.Status.InitializeConditions()
+			ro.Status.SetStageRevisionStatus(status)
+			ro.Status.MarkStageRevisionReady()
+			ro.Status.MarkLastStageRevisionInComplete()
+			replicas := MockPodAutoscalerReplicas{"rev-001": 6, "rev-002": 4}
+			c := &Reconciler{
+				revisionLister: servinglisters.NewRevisionLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+					cache.Indexers{})),
+				rolloutConfig: &RolloutConfig{ProgressiveRolloutEnabled: true, StageFastAdvanceEnabled: true,
+					OverConsumptionRatio: 20, StageRolloutTimeoutMinutes: 2, SupersedingPolicy: test.policy},
+				enqueueAfter: func(interface{}, time.Duration) {},
+			}
+			ctx := controller.WithEventRecorder(context.Background(), record.NewFakeRecorder(10))
+
+			target, _ := c.handleNewRevision(ctx, service, service, config, ro, ultimate)
+			resources.UpdateInitialFinalTargetRev(target, ro, nil, nil)
+			var stages []string
+			for i := 0; i < len(test.ExpectedResult)+1 && !ro.IsLastStageComplete(); i++ {
+				if err := updateRolloutOrchestrator(ro, replicas, nil, c.rolloutConfig); err != nil {
+					t.Fatalf("updateRolloutOrchestrator() = %v", err)
+				}
+				stage := make([]string, 0, len(ro.Spec.StageTargetRevisions))
+				for _, rev := range ro.Spec.StageTargetRevisions {
+					stage = append(stage, fmt.Sprintf("%s=%d%%", rev.RevisionName, ptr.Int64Value(rev.Percent)))
+					replicas[rev.RevisionName] = ptr.Int32Value(rev.TargetReplicas)
+				}
+				stages = append(stages, strings.Join(stage, " "))
+
+				// The stage is accomplished, as the RolloutOrchestrator reconciler does.
+				ro.Status.SetStageRevisionStatus(rolloutorchestrator.RemoveNonTrafficRev(ro.Spec.StageTargetRevisions))
+				ro.Status.MarkStageRevisionReady()
+				if rolloutorchestrator.LastStageComplete(ro.Status.StageRevisionStatus, ro.Spec.TargetRevisions) {
+					ro.Status.MarkLastStageRevisionComplete()
+				} else {
+					ro.Status.MarkLastStageRevisionInComplete()
+				}
+			}
+			if !reflect.DeepEqual(stages, test.ExpectedResult) {
+				t.Fatalf("Result of the stages = %v, want %v", stages, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestPinRevisions(t *testing.T) {
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", LatestRevision: ptr.Bool(true),
+					Percent: ptr.Int64(100)},
+			}},
+			StageTarget: v1.StageTarget{StageTargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", LatestRevision: ptr.Bool(false),
+					Percent: ptr.Int64(80)},
+			}, {
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", LatestRevision: ptr.Bool(true),
+					Percent: ptr.Int64(20)},
+			}}},
+		},
+	}
+	original := ro.Spec.TargetRevisions
+	pinRevisions(ro)
+	for _, revs := range [][]v1.TargetRevision{ro.Spec.TargetRevisions, ro.Spec.StageTargetRevisions} {
+		for _, rev := range revs {
+			if rev.LatestRevision == nil || *rev.LatestRevision {
+				t.Fatalf("Result of pinRevisions() LatestRevision of %s = %v, want false", rev.RevisionName,
+					rev.LatestRevision)
+			}
+		}
+	}
+	if !*original[0].LatestRevision {
+		t.Fatalf("Result of pinRevisions() changed the original target revisions")
+	}
+}
+
+func TestAppendRolloutTags(t *testing.T) {
+	ro := &v1.RolloutOrchestrator{
+		Spec: v1.RolloutOrchestratorSpec{
+			InitialRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(30)},
+			}, {
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(70)},
+			}},
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-003", Percent: ptr.Int64(100)},
+			}},
+		},
+	}
+	stageTargets := []servingv1.TrafficTarget{{
+		RevisionName: "rev-002", Percent: ptr.Int64(80),
+	}, {
+		RevisionName: "rev-003", Percent: ptr.Int64(20), LatestRevision: ptr.Bool(true),
+	}}
+	tests := []struct {
+		name           string
+		input          []servingv1.TrafficTarget
+		rc             *RolloutConfig
+		ExpectedResult []servingv1.TrafficTarget
+	}{{
+		name:           "Test without the rollout tags",
+		input:          stageTargets,
+		rc:             &RolloutConfig{},
+		ExpectedResult: stageTargets,
+	}, {
+		name:  "Test with the rollout tags",
+		input: stageTargets,
+		rc:    &RolloutConfig{CandidateTag: "candidate", StableTag: "stable"},
+		ExpectedResult: append(slices.Clone(stageTargets), servingv1.TrafficTarget{
+			Tag: "candidate", RevisionName: "rev-003", Percent: ptr.Int64(0), LatestRevision: ptr.Bool(false),
+		}, servingv1.TrafficTarget{
+			Tag: "stable", RevisionName: "rev-002", Percent: ptr.Int64(0), LatestRevision: ptr.Bool(false),
+		}),
+	}, {
+		name: "Test with the rollout tag used by the user",
+		input: []servingv1.TrafficTarget{{
+			Tag: "stable", RevisionName: "rev-001", Percent: ptr.Int64(0),
+		}, {
+			RevisionName: "rev-003", Percent: ptr.Int64(100), LatestRevision: ptr.Bool(true),
+		}},
+		rc: &RolloutConfig{CandidateTag: "candidate", StableTag: "stable"},
+		ExpectedResult: []servingv1.TrafficTarget{{
+			Tag: "stable", RevisionName: "rev-001", Percent: ptr.Int64(0),
+		}, {
+			RevisionName: "rev-003", Percent: ptr.Int64(100), LatestRevision: ptr.Bool(true),
+		}, {
+			Tag: "candidate", RevisionName: "rev-003", Percent: ptr.Int64(0), LatestRevision: ptr.Bool(false),
+		}},
+	}, {
+		name: "Test with the revision scaling up not in the traffic",
+		input: []servingv1.TrafficTarget{{
+			RevisionName: "rev-002", Percent: ptr.Int64(100),
+		}},
+		rc: &RolloutConfig{CandidateTag: "candidate", StableTag: "stable"},
+		ExpectedResult: []servingv1.TrafficTarget{{
+			RevisionName: "rev-002", Percent: ptr.Int64(100),
+		}, {
+			Tag: "candidate", ConfigurationName: "test", Percent: ptr.Int64(0), LatestRevision: ptr.Bool(true),
+		}, {
+			Tag: "stable", RevisionName: "rev-002", Percent: ptr.Int64(0), LatestRevision: ptr.Bool(false),
+		}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := appendRolloutTags(slices.Clone(test.input), "test", ro, test.rc)
+			if !reflect.DeepEqual(r, test.ExpectedResult) {
+				t.Fatalf("Result of appendRolloutTags() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestTraceStage(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ro := &v1.RolloutOrchestrator{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: v1.RolloutOrchestratorSpec{
+			TargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(100)},
+			}},
+			StageTarget: v1.StageTarget{StageTargetRevisions: []v1.TargetRevision{{
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-001", Percent: ptr.Int64(80)},
+			}, {
+				TrafficTarget: servingv1.TrafficTarget{RevisionName: "rev-002", Percent: ptr.Int64(20)},
+			}}},
+		},
+	}
+
+	spans := tracing.NewSpans()
+	traceStage(context.Background(), spans, ro, true)
+	traceID := ro.Annotations[tracing.TraceIDAnnotation]
+	if traceID == "" || ro.Annotations[tracing.StageTraceParentAnnotation] == "" {
+		t.Fatalf("Result of traceStage() = %v, want the trace annotations", ro.Annotations)
+	}
+	if len(recorder.Ended()) != 0 {
+		t.Fatalf("Number of the spans ended by traceStage() = %v, want %v", len(recorder.Ended()), 0)
+	}
+
+	// The next stage of the same rollout is traced in the same trace, and the span of the previous stage ends.
+	time.Sleep(time.Millisecond)
+	traceStage(context.Background(), spans, ro, false)
+	if ro.Annotations[tracing.TraceIDAnnotation] != traceID ||
+		!strings.Contains(ro.Annotations[tracing.StageTraceParentAnnotation], traceID) {
+		t.Fatalf("Result of traceStage() = %v, want the trace %v", ro.Annotations, traceID)
+	}
+
+	// A new rollout starts a new trace, and the spans of the previous rollout and its last stage end.
+	time.Sleep(time.Millisecond)
+	traceStage(context.Background(), spans, ro, true)
+	if ro.Annotations[tracing.TraceIDAnnotation] == traceID {
+		t.Fatalf("Result of traceStage() = %v, want a new trace", ro.Annotations)
+	}
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+		if !span.EndTime().After(span.StartTime()) {
+			t.Fatalf("Duration of the span %s of traceStage() = %v, want more than 0", span.Name(),
+				span.EndTime().Sub(span.StartTime()))
+		}
+	}
+	if expected := []string{"stage", "stage", "rollout"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Spans of traceStage() = %v, want %v", names, expected)
+	}
+}
@@ -49,11 +49,7 @@ func NewController(
 	configStore := cfgmap.NewStore(logger.Named(common.ConfigStoreName))
 	configStore.WatchConfigs(cmw)
 
-	c := &Reconciler{
-		client:              servingclient.Get(ctx),
-		podAutoscalerLister: paInformer.Lister(),
-		podsLister:          podsInformer.Lister(),
-	}
+	c := NewReconciler(servingclient.Get(ctx), paInformer.Lister(), podsInformer.Lister())
 	opts := func(*controller.Impl) controller.Options {
 		return controller.Options{ConfigStore: configStore}
 	}
@@ -19,16 +19,24 @@ package stagepodautoscaler
 import (
 	"context"
 	"fmt"
+	"math"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	cfgmap "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
 	resourceutil "knative.dev/serving/pkg/resources"
 
 	pkgreconciler "knative.dev/pkg/reconciler"
 	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
 	spareconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/stagepodautoscaler"
+	hparesources "knative.dev/serving-progressive-rollout/pkg/reconciler/autoscaling/hpa/resources"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/autoscaling/kpa"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
 	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
 )
@@ -43,8 +51,19 @@ type Reconciler struct {
 // Check that our Reconciler implements soreconciler.Interface
 var _ spareconciler.Interface = (*Reconciler)(nil)
 
+// NewReconciler creates the reference to the Reconciler based on clientset.Interface, palisters.PodAutoscalerLister
+// and corev1listers.PodLister.
+func NewReconciler(client clientset.Interface, podAutoscalerLister palisters.PodAutoscalerLister,
+	podsLister corev1listers.PodLister) *Reconciler {
+	return &Reconciler{
+		client:              client,
+		podAutoscalerLister: podAutoscalerLister,
+		podsLister:          podsLister,
+	}
+}
+
 // ReconcileKind implements Interface.ReconcileKind.
-func (c *Reconciler) ReconcileKind(_ context.Context, spa *v1.StagePodAutoscaler) pkgreconciler.Event {
+func (c *Reconciler) ReconcileKind(ctx context.Context, spa *v1.StagePodAutoscaler) pkgreconciler.Event {
 	pa, err := c.podAutoscalerLister.PodAutoscalers(spa.Namespace).Get(spa.Name)
 	if apierrs.IsNotFound(err) {
 		message := fmt.Sprintf("The PodAutoscaler %v/%v was not found.", spa.Namespace, spa.Name)
@@ -56,7 +75,7 @@ func (c *Reconciler) ReconcileKind(_ context.Context, spa *v1.StagePodAutoscaler
 	}
 
 	podCounter := resourceutil.NewPodAccessor(c.podsLister, pa.Namespace, pa.Labels[serving.RevisionLabelKey])
-	_, _, _, terminating, err := podCounter.PodCountsByState()
+	ready, notReady, pending, terminating, err := podCounter.PodCountsByState()
 	if err != nil {
 		return fmt.Errorf("error getting pod counts for the revision %s under the namespace %s: %w",
 			pa.Labels[serving.RevisionLabelKey], pa.Namespace, err)
@@ -65,6 +84,23 @@ func (c *Reconciler) ReconcileKind(_ context.Context, spa *v1.StagePodAutoscaler
 	if spa.Status.ReplicasTerminating == nil || *spa.Status.ReplicasTerminating != int32(terminating) {
 		spa.Status.ReplicasTerminating = ptr.Int32(int32(terminating))
 	}
+	spa.Status.ReplicasReady = ptr.Int32(int32(ready))
+	spa.Status.ReplicasNotReady = ptr.Int32(int32(notReady))
+	spa.Status.ReplicasPending = ptr.Int32(int32(pending))
+
+	lastPodReadyTime, err := getLastPodReadyTime(podCounter)
+	if err != nil {
+		return fmt.Errorf("error getting the ready time of the pods for the revision %s under the namespace %s: %w",
+			pa.Labels[serving.RevisionLabelKey], pa.Namespace, err)
+	}
+	// Keep the previous time, if all the pods are gone.
+	if lastPodReadyTime != nil {
+		spa.Status.LastPodReadyTime = lastPodReadyTime
+	}
+
+	minR, maxR := getEffectiveScaleBounds(ctx, pa, spa)
+	spa.Status.EffectiveMinScale = ptr.Int32(minR)
+	spa.Status.EffectiveMaxScale = ptr.Int32(maxR)
 
 	// As long as the PodAutoscaler with the same name as StagePodAutoscaler exists, and both of the DesiredScale
 	// the ActualScale are available, we propagate the values to StagePodAutoscaler.
@@ -84,3 +120,36 @@ func (c *Reconciler) ReconcileKind(_ context.Context, spa *v1.StagePodAutoscaler
 	}
 	return nil
 }
+
+// getLastPodReadyTime returns the latest time, when one of the running pods became ready.
+func getLastPodReadyTime(podCounter resourceutil.PodAccessor) (*metav1.Time, error) {
+	var lastPodReadyTime *metav1.Time
+	err := podCounter.ProcessPods(func(p *corev1.Pod) {
+		for _, cond := range p.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				if lastPodReadyTime == nil || lastPodReadyTime.Before(&cond.LastTransitionTime) {
+					lastPodReadyTime = cond.LastTransitionTime.DeepCopy()
+				}
+			}
+		}
+	}, func(p *corev1.Pod) bool {
+		return p.DeletionTimestamp == nil
+	})
+	return lastPodReadyTime, err
+}
+
+// getEffectiveScaleBounds returns the min and the max scales, which the autoscaler of the revision applies
+// for the current stage.
+func getEffectiveScaleBounds(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler,
+	spa *v1.StagePodAutoscaler) (int32, int32) {
+	asConfig := cfgmap.FromContextOrDefaults(ctx).Autoscaler
+	if pa.Class() == autoscaling.HPA {
+		minR, maxR := hparesources.GetScaleBounds(asConfig, pa, spa)
+		if maxR == math.MaxInt32 {
+			// Report no limit in the same way as the KPA.
+			maxR = 0
+		}
+		return minR, maxR
+	}
+	return kpa.GetScaleBounds(asConfig, pa, spa)
+}
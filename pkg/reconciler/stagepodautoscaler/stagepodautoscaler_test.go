@@ -0,0 +1,170 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stagepodautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	resourceutil "knative.dev/serving/pkg/resources"
+)
+
+func TestGetLastPodReadyTime(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	earlier := metav1.NewTime(now.Add(-time.Minute))
+	later := metav1.NewTime(now)
+	tests := []struct {
+		name           string
+		pods           []*corev1.Pod
+		ExpectedResult *metav1.Time
+	}{{
+		name:           "Test without pods",
+		ExpectedResult: nil,
+	}, {
+		name: "Test with the pods not ready",
+		pods: []*corev1.Pod{
+			makePod("pod-1", corev1.ConditionFalse, later, false),
+		},
+		ExpectedResult: nil,
+	}, {
+		name: "Test with multiple ready pods",
+		pods: []*corev1.Pod{
+			makePod("pod-1", corev1.ConditionTrue, earlier, false),
+			makePod("pod-2", corev1.ConditionTrue, later, false),
+		},
+		ExpectedResult: &later,
+	}, {
+		name: "Test with the latest ready pod terminating",
+		pods: []*corev1.Pod{
+			makePod("pod-1", corev1.ConditionTrue, earlier, false),
+			makePod("pod-2", corev1.ConditionTrue, later, true),
+		},
+		ExpectedResult: &earlier,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, pod := range test.pods {
+				indexer.Add(pod)
+			}
+			podCounter := resourceutil.NewPodAccessor(corev1listers.NewPodLister(indexer), "test-ns", "rev-001")
+			r, err := getLastPodReadyTime(podCounter)
+			if err != nil {
+				t.Fatalf("getLastPodReadyTime() returned error: %v", err)
+			}
+			if (r == nil) != (test.ExpectedResult == nil) || (r != nil && !r.Equal(test.ExpectedResult)) {
+				t.Fatalf("Result of getLastPodReadyTime() = %v, want %v", r, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestGetEffectiveScaleBounds(t *testing.T) {
+	spa := &v1.StagePodAutoscaler{
+		Spec: v1.StagePodAutoscalerSpec{
+			StageMinScale: ptr.Int32(1),
+			StageMaxScale: ptr.Int32(3),
+		},
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		spa         *v1.StagePodAutoscaler
+		ExpectedMin int32
+		ExpectedMax int32
+	}{{
+		name: "Test the KPA with the revision bounds only",
+		annotations: map[string]string{
+			autoscaling.MinScaleAnnotationKey: "2",
+			autoscaling.MaxScaleAnnotationKey: "5",
+		},
+		ExpectedMin: 2,
+		ExpectedMax: 5,
+	}, {
+		name: "Test the KPA with the stage bounds",
+		annotations: map[string]string{
+			autoscaling.MinScaleAnnotationKey: "2",
+			autoscaling.MaxScaleAnnotationKey: "5",
+		},
+		spa:         spa,
+		ExpectedMin: 1,
+		ExpectedMax: 3,
+	}, {
+		name: "Test the HPA without any limit",
+		annotations: map[string]string{
+			autoscaling.ClassAnnotationKey: autoscaling.HPA,
+		},
+		ExpectedMin: 0,
+		ExpectedMax: 0,
+	}, {
+		name: "Test the HPA with the stage bounds",
+		annotations: map[string]string{
+			autoscaling.ClassAnnotationKey: autoscaling.HPA,
+		},
+		spa:         spa,
+		ExpectedMin: 0,
+		ExpectedMax: 3,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pa := &autoscalingv1alpha1.PodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: test.annotations,
+				},
+			}
+			minR, maxR := getEffectiveScaleBounds(context.Background(), pa, test.spa)
+			if minR != test.ExpectedMin || maxR != test.ExpectedMax {
+				t.Fatalf("Result of getEffectiveScaleBounds() = %v, %v, want %v, %v", minR, maxR,
+					test.ExpectedMin, test.ExpectedMax)
+			}
+		})
+	}
+}
+
+func makePod(name string, ready corev1.ConditionStatus, transitionTime metav1.Time, terminating bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-ns",
+			Labels: map[string]string{
+				serving.RevisionLabelKey: "rev-001",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             ready,
+				LastTransitionTime: transitionTime,
+			}},
+		},
+	}
+	if terminating {
+		pod.DeletionTimestamp = &transitionTime
+	}
+	return pod
+}
@@ -20,11 +20,13 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 	cachingv1alpha1 "knative.dev/caching/pkg/apis/caching/v1alpha1"
 	fakecachingclientset "knative.dev/caching/pkg/client/clientset/versioned/fake"
@@ -162,6 +164,16 @@ func (l *Listers) GetStagePodAutoscalerLister() spalisters.StagePodAutoscalerLis
 	return spalisters.NewStagePodAutoscalerLister(l.IndexerFor(&sprv1.StagePodAutoscaler{}))
 }
 
+// GetRolloutOrchestratorLister returns a lister for the RolloutOrchestrator objects.
+func (l *Listers) GetRolloutOrchestratorLister() spalisters.RolloutOrchestratorLister {
+	return spalisters.NewRolloutOrchestratorLister(l.IndexerFor(&sprv1.RolloutOrchestrator{}))
+}
+
+// GetRolloutGroupLister returns a lister for the RolloutGroup objects.
+func (l *Listers) GetRolloutGroupLister() spalisters.RolloutGroupLister {
+	return spalisters.NewRolloutGroupLister(l.IndexerFor(&sprv1.RolloutGroup{}))
+}
+
 // GetHorizontalPodAutoscalerLister gets lister for HorizontalPodAutoscaler resources.
 func (l *Listers) GetHorizontalPodAutoscalerLister() autoscalingv2listers.HorizontalPodAutoscalerLister {
 	return autoscalingv2listers.NewHorizontalPodAutoscalerLister(l.IndexerFor(&autoscalingv2.HorizontalPodAutoscaler{}))
@@ -192,6 +204,11 @@ func (l *Listers) GetImageLister() cachinglisters.ImageLister {
 	return cachinglisters.NewImageLister(l.IndexerFor(&cachingv1alpha1.Image{}))
 }
 
+// GetConfigMapLister returns a lister for ConfigMap objects.
+func (l *Listers) GetConfigMapLister() corev1listers.ConfigMapLister {
+	return corev1listers.NewConfigMapLister(l.IndexerFor(&corev1.ConfigMap{}))
+}
+
 // GetDeploymentLister returns a lister for Deployment objects.
 func (l *Listers) GetDeploymentLister() appsv1listers.DeploymentLister {
 	return appsv1listers.NewDeploymentLister(l.IndexerFor(&appsv1.Deployment{}))
@@ -212,6 +229,11 @@ func (l *Listers) GetPodsLister() corev1listers.PodLister {
 	return corev1listers.NewPodLister(l.IndexerFor(&corev1.Pod{}))
 }
 
+// GetPodDisruptionBudgetLister gets lister for PodDisruptionBudget resource.
+func (l *Listers) GetPodDisruptionBudgetLister() policyv1listers.PodDisruptionBudgetLister {
+	return policyv1listers.NewPodDisruptionBudgetLister(l.IndexerFor(&policyv1.PodDisruptionBudget{}))
+}
+
 // GetNamespaceLister gets lister for Namespace resource.
 func (l *Listers) GetNamespaceLister() corev1listers.NamespaceLister {
 	return corev1listers.NewNamespaceLister(l.IndexerFor(&corev1.Namespace{}))
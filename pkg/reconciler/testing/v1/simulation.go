@@ -0,0 +1,926 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	sprv1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	fakesprclientset "knative.dev/serving-progressive-rollout/pkg/client/clientset/versioned/fake"
+	rgreconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/rolloutgroup"
+	roreconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/rolloutorchestrator"
+	spareconciler "knative.dev/serving-progressive-rollout/pkg/client/injection/reconciler/serving/v1/stagepodautoscaler"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	fakeservingclientset "knative.dev/serving/pkg/client/clientset/versioned/fake"
+	ksvcreconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/service"
+)
+
+const (
+	// simulationEventBufferSize is the max number of events buffered within one tick of the simulation.
+	simulationEventBufferSize = 1000
+	// defaultTerminationGracePeriod is the default grace period of the pods terminating in the simulation.
+	defaultTerminationGracePeriod = 30 * time.Second
+)
+
+// TrafficSnapshot records the traffic split of a route in the simulation.
+type TrafficSnapshot struct {
+	// Tick is the tick of the simulation, when the traffic split was applied.
+	Tick int
+	// Route is the name of the route.
+	Route string
+	// Traffic maps the name of every revision receiving traffic to its percentage of the traffic.
+	Traffic map[string]int64
+}
+
+// scaleChange records the desired scale of a revision, and the tick when it was requested.
+type scaleChange struct {
+	desired int32
+	since   int
+}
+
+// Simulation drives the progressive rollout end-to-end in-process. It runs the reconcilers of the service, the
+// RolloutOrchestrator, the StagePodAutoscaler and the RolloutGroup against fake clients, and simulates the controllers
+// they rely on: the Configuration and Route controllers of Knative Serving, and the PodAutoscalers, Deployments and
+// pods of the revisions. A simulated PodAutoscaler follows the scale bounds of its StagePodAutoscaler, and its pods
+// reach the desired scale after the configured delays.
+//
+// Every call to Tick reconciles all the resources once, so the reconcilers do not need to be enqueued.
+type Simulation struct {
+	KubeClient    *fakekubeclientset.Clientset
+	ServingClient *fakeservingclientset.Clientset
+	Client        *fakesprclientset.Clientset
+	// Listers are kept in sync with the fake clients before every reconciler runs.
+	Listers Listers
+
+	ServiceReconciler             ksvcreconciler.Interface
+	RolloutOrchestratorReconciler roreconciler.Interface
+	StagePodAutoscalerReconciler  spareconciler.Interface
+	RolloutGroupReconciler        rgreconciler.Interface
+
+	// Demand is the number of replicas needed to serve all the traffic of a service. Every revision demands
+	// its share of the replicas based on its percentage of the traffic, within its scale bounds.
+	Demand int32
+	// ScaleUpDelay is the number of ticks a revision takes to scale up to the desired scale.
+	ScaleUpDelay int
+	// ScaleDownDelay is the number of ticks a revision takes to scale down to the desired scale.
+	ScaleDownDelay int
+	// TerminationDelay is the number of ticks a pod keeps terminating before it is gone.
+	TerminationDelay int
+	// TerminationGracePeriod is the grace period of the terminating pods. With a negative value, the pods exceed
+	// their grace period as soon as they start terminating.
+	TerminationGracePeriod time.Duration
+
+	// Traffic keeps every change of the traffic split applied to the routes.
+	Traffic []TrafficSnapshot
+	// Events keeps the events recorded by the reconcilers.
+	Events []string
+
+	ctx         context.Context
+	recorder    *record.FakeRecorder
+	tick        int
+	scaling     map[string]*scaleChange
+	terminating map[string]int
+	podCount    map[string]int
+}
+
+// NewSimulation creates a Simulation with the objects loaded into the fake clients. The reconcilers need to be
+// set up with the clients and the listers of the Simulation before it runs.
+func NewSimulation(ctx context.Context, objs ...runtime.Object) *Simulation {
+	ls := NewListers(objs)
+	s := &Simulation{
+		KubeClient:             fakekubeclientset.NewSimpleClientset(ls.GetKubeObjects()...),
+		ServingClient:          fakeservingclientset.NewSimpleClientset(ls.GetServingObjects()...),
+		Client:                 fakesprclientset.NewSimpleClientset(ls.GetServingProgressiveRolloutObjects()...),
+		Listers:                ls,
+		Demand:                 1,
+		TerminationGracePeriod: defaultTerminationGracePeriod,
+		recorder:               record.NewFakeRecorder(simulationEventBufferSize),
+		scaling:                map[string]*scaleChange{},
+		terminating:            map[string]int{},
+		podCount:               map[string]int{},
+	}
+	s.ctx = controller.WithEventRecorder(ctx, s.recorder)
+
+	// The objects created or updated by the reconcilers are visible in the listers right away, so that the
+	// following reconcilers in the same tick do not act on stale objects.
+	s.KubeClient.PrependReactor("*", "*", s.listerReactor)
+	s.ServingClient.PrependReactor("*", "*", s.listerReactor)
+	s.Client.PrependReactor("*", "*", s.listerReactor)
+	// The API server bumps the generation of an object, when its spec changes.
+	s.ServingClient.PrependReactor("*", "*", generationReactor(s.ServingClient.Tracker()))
+	s.Client.PrependReactor("*", "*", generationReactor(s.Client.Tracker()))
+	return s
+}
+
+// listerReactor adds the created or updated object into the listers.
+func (s *Simulation) listerReactor(action ktesting.Action) (bool, runtime.Object, error) {
+	switch action.GetVerb() {
+	case "create":
+		obj := action.(ktesting.CreateAction).GetObject()
+		return false, nil, s.Listers.IndexerFor(obj).Add(obj.DeepCopyObject())
+	case "update":
+		obj := action.(ktesting.UpdateAction).GetObject()
+		return false, nil, s.Listers.IndexerFor(obj).Update(obj.DeepCopyObject())
+	}
+	return false, nil, nil
+}
+
+// generationReactor sets the generation of the created or updated object, like the API server does.
+func generationReactor(tracker ktesting.ObjectTracker) ktesting.ReactionFunc {
+	return func(action ktesting.Action) (bool, runtime.Object, error) {
+		switch action.GetVerb() {
+		case "create":
+			if obj, ok := action.(ktesting.CreateAction).GetObject().(metav1.Object); ok {
+				obj.SetGeneration(1)
+			}
+		case "update":
+			a := action.(ktesting.UpdateAction)
+			obj, ok := a.GetObject().(metav1.Object)
+			if !ok {
+				return false, nil, nil
+			}
+			existing, err := tracker.Get(a.GetResource(), a.GetNamespace(), obj.GetName())
+			if err != nil {
+				return false, nil, nil
+			}
+			generation := existing.(metav1.Object).GetGeneration()
+			if a.GetSubresource() == "" && !equality.Semantic.DeepEqual(specOf(existing), specOf(a.GetObject())) {
+				generation++
+			}
+			obj.SetGeneration(generation)
+		}
+		return false, nil, nil
+	}
+}
+
+// specOf returns the spec of the object.
+func specOf(obj runtime.Object) interface{} {
+	switch o := obj.(type) {
+	case *servingv1.Service:
+		return o.Spec
+	case *servingv1.Configuration:
+		return o.Spec
+	case *servingv1.Route:
+		return o.Spec
+	case *servingv1.Revision:
+		return o.Spec
+	case *autoscalingv1alpha1.PodAutoscaler:
+		return o.Spec
+	case *sprv1.RolloutOrchestrator:
+		return o.Spec
+	case *sprv1.StagePodAutoscaler:
+		return o.Spec
+	case *sprv1.RolloutGroup:
+		return o.Spec
+	}
+	return nil
+}
+
+// EnqueueAfter is the function to enqueue the resources for the reconcilers. It does nothing, because every tick
+// reconciles all the resources.
+func (s *Simulation) EnqueueAfter(interface{}, time.Duration) {}
+
+// CreateService creates the service with the defaults applied, like the webhook does.
+func (s *Simulation) CreateService(service *servingv1.Service) error {
+	service = service.DeepCopy()
+	service.SetDefaults(s.ctx)
+	_, err := s.ServingClient.ServingV1().Services(service.Namespace).Create(s.ctx, service, metav1.CreateOptions{})
+	return err
+}
+
+// UpdateService changes the service with the function, and updates it with the defaults applied.
+func (s *Simulation) UpdateService(namespace, name string, update func(*servingv1.Service)) error {
+	service, err := s.ServingClient.ServingV1().Services(namespace).Get(s.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	update(service)
+	service.SetDefaults(s.ctx)
+	_, err = s.ServingClient.ServingV1().Services(namespace).Update(s.ctx, service, metav1.UpdateOptions{})
+	return err
+}
+
+// RolloutOrchestrator returns the current RolloutOrchestrator.
+func (s *Simulation) RolloutOrchestrator(namespace, name string) (*sprv1.RolloutOrchestrator, error) {
+	return s.Client.ServingV1().RolloutOrchestrators(namespace).Get(s.ctx, name, metav1.GetOptions{})
+}
+
+// ExpireStage moves the finish time of the current stage of the RolloutOrchestrator into the past, so that the
+// stage times out.
+func (s *Simulation) ExpireStage(namespace, name string) error {
+	ro, err := s.RolloutOrchestrator(namespace, name)
+	if err != nil {
+		return err
+	}
+	ro.Spec.TargetFinishTime = apis.VolatileTime{Inner: metav1.NewTime(time.Now().Add(-time.Minute))}
+	_, err = s.Client.ServingV1().RolloutOrchestrators(namespace).Update(s.ctx, ro, metav1.UpdateOptions{})
+	return err
+}
+
+// Ticks returns the number of ticks the simulation has run.
+func (s *Simulation) Ticks() int {
+	return s.tick
+}
+
+// Run runs the simulation for the number of ticks.
+func (s *Simulation) Run(ticks int) error {
+	for i := 0; i < ticks; i++ {
+		if err := s.Tick(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunUntil runs the simulation until the condition is met. It returns an error, if the condition is not met
+// within the max number of ticks.
+func (s *Simulation) RunUntil(done func() bool, maxTicks int) error {
+	for i := 0; i < maxTicks; i++ {
+		if err := s.Tick(); err != nil {
+			return err
+		}
+		if done() {
+			return nil
+		}
+	}
+	return fmt.Errorf("the condition was not met within %d ticks", maxTicks)
+}
+
+// Tick runs every reconciler and every simulated controller once.
+func (s *Simulation) Tick() error {
+	s.tick++
+	steps := []func() error{
+		s.reconcileServices,
+		s.simulateConfigurations,
+		s.simulateRoutes,
+		s.simulateAutoscalers,
+		s.reconcileStagePodAutoscalers,
+		s.reconcileRolloutOrchestrators,
+		s.reconcileRolloutGroups,
+	}
+	for _, step := range steps {
+		if err := s.sync(); err != nil {
+			return err
+		}
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	s.collectEvents()
+	return nil
+}
+
+// collectEvents moves the events recorded in this tick into Events.
+func (s *Simulation) collectEvents() {
+	for {
+		select {
+		case event := <-s.recorder.Events:
+			s.Events = append(s.Events, event)
+		default:
+			return
+		}
+	}
+}
+
+// sync replaces the content of the listers with the objects in the fake clients.
+func (s *Simulation) sync() error {
+	services, err := s.ServingClient.ServingV1().Services("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	configs, err := s.ServingClient.ServingV1().Configurations("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	revisions, err := s.ServingClient.ServingV1().Revisions("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	routes, err := s.ServingClient.ServingV1().Routes("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pas, err := s.ServingClient.AutoscalingV1alpha1().PodAutoscalers("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	ros, err := s.Client.ServingV1().RolloutOrchestrators("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	spas, err := s.Client.ServingV1().StagePodAutoscalers("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	groups, err := s.Client.ServingV1().RolloutGroups("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	deployments, err := s.KubeClient.AppsV1().Deployments("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := s.KubeClient.CoreV1().Pods("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	configMaps, err := s.KubeClient.CoreV1().ConfigMaps("").List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range []struct {
+		obj  runtime.Object
+		objs []interface{}
+	}{
+		{&servingv1.Service{}, toInterfaces(len(services.Items), func(i int) interface{} { return &services.Items[i] })},
+		{&servingv1.Configuration{}, toInterfaces(len(configs.Items), func(i int) interface{} { return &configs.Items[i] })},
+		{&servingv1.Revision{}, toInterfaces(len(revisions.Items), func(i int) interface{} { return &revisions.Items[i] })},
+		{&servingv1.Route{}, toInterfaces(len(routes.Items), func(i int) interface{} { return &routes.Items[i] })},
+		{&autoscalingv1alpha1.PodAutoscaler{}, toInterfaces(len(pas.Items), func(i int) interface{} { return &pas.Items[i] })},
+		{&sprv1.RolloutOrchestrator{}, toInterfaces(len(ros.Items), func(i int) interface{} { return &ros.Items[i] })},
+		{&sprv1.StagePodAutoscaler{}, toInterfaces(len(spas.Items), func(i int) interface{} { return &spas.Items[i] })},
+		{&sprv1.RolloutGroup{}, toInterfaces(len(groups.Items), func(i int) interface{} { return &groups.Items[i] })},
+		{&appsv1.Deployment{}, toInterfaces(len(deployments.Items), func(i int) interface{} { return &deployments.Items[i] })},
+		{&corev1.Pod{}, toInterfaces(len(pods.Items), func(i int) interface{} { return &pods.Items[i] })},
+		{&corev1.ConfigMap{}, toInterfaces(len(configMaps.Items), func(i int) interface{} { return &configMaps.Items[i] })},
+	} {
+		if err = s.Listers.IndexerFor(r.obj).Replace(r.objs, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toInterfaces converts the items of a list into a slice for the indexer.
+func toInterfaces(size int, item func(int) interface{}) []interface{} {
+	objs := make([]interface{}, 0, size)
+	for i := 0; i < size; i++ {
+		objs = append(objs, item(i))
+	}
+	return objs
+}
+
+// reconcilable is the resource that can be reconciled by a generated reconciler.
+type reconcilable interface {
+	duckv1.KRShaped
+	runtime.Object
+}
+
+// reconcile runs the reconciliation of the resource in the same way as the generated reconcilers. The error
+// returned by the reconciler is recorded as an event, and the resource is reconciled again in the next tick.
+func (s *Simulation) reconcile(resource, original reconcilable, reconcileKind func() pkgreconciler.Event) {
+	pkgreconciler.PreProcessReconcile(s.ctx, resource)
+	err := reconcileKind()
+	pkgreconciler.PostProcessReconcile(s.ctx, resource, original)
+	if err == nil {
+		return
+	}
+	var event *pkgreconciler.ReconcilerEvent
+	if pkgreconciler.EventAs(err, &event) {
+		s.recorder.Eventf(resource, event.EventType, event.Reason, event.Format, event.Args...)
+		return
+	}
+	s.recorder.Event(resource, corev1.EventTypeWarning, "InternalError", err.Error())
+}
+
+// reconcileServices runs the reconciler of the services.
+func (s *Simulation) reconcileServices() error {
+	if s.ServiceReconciler == nil {
+		return nil
+	}
+	services, err := s.Listers.GetServiceLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, original := range services {
+		service := original.DeepCopy()
+		s.reconcile(service, original, func() pkgreconciler.Event {
+			return s.ServiceReconciler.ReconcileKind(s.ctx, service)
+		})
+		if equality.Semantic.DeepEqual(original.Status, service.Status) {
+			continue
+		}
+		latest, err := s.ServingClient.ServingV1().Services(service.Namespace).Get(s.ctx, service.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest.Status = service.Status
+		if _, err = s.ServingClient.ServingV1().Services(service.Namespace).UpdateStatus(s.ctx, latest,
+			metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileRolloutOrchestrators runs the reconciler of the RolloutOrchestrators.
+func (s *Simulation) reconcileRolloutOrchestrators() error {
+	if s.RolloutOrchestratorReconciler == nil {
+		return nil
+	}
+	ros, err := s.Listers.GetRolloutOrchestratorLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, original := range ros {
+		ro := original.DeepCopy()
+		s.reconcile(ro, original, func() pkgreconciler.Event {
+			return s.RolloutOrchestratorReconciler.ReconcileKind(s.ctx, ro)
+		})
+		if equality.Semantic.DeepEqual(original.Status, ro.Status) {
+			continue
+		}
+		latest, err := s.Client.ServingV1().RolloutOrchestrators(ro.Namespace).Get(s.ctx, ro.Name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		latest.Status = ro.Status
+		if _, err = s.Client.ServingV1().RolloutOrchestrators(ro.Namespace).UpdateStatus(s.ctx, latest,
+			metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileRolloutGroups runs the reconciler of the RolloutGroups.
+func (s *Simulation) reconcileRolloutGroups() error {
+	if s.RolloutGroupReconciler == nil {
+		return nil
+	}
+	groups, err := s.Listers.GetRolloutGroupLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, original := range groups {
+		rg := original.DeepCopy()
+		s.reconcile(rg, original, func() pkgreconciler.Event {
+			return s.RolloutGroupReconciler.ReconcileKind(s.ctx, rg)
+		})
+		if equality.Semantic.DeepEqual(original.Status, rg.Status) {
+			continue
+		}
+		latest, err := s.Client.ServingV1().RolloutGroups(rg.Namespace).Get(s.ctx, rg.Name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		latest.Status = rg.Status
+		if _, err = s.Client.ServingV1().RolloutGroups(rg.Namespace).UpdateStatus(s.ctx, latest,
+			metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileStagePodAutoscalers runs the reconciler of the StagePodAutoscalers.
+func (s *Simulation) reconcileStagePodAutoscalers() error {
+	if s.StagePodAutoscalerReconciler == nil {
+		return nil
+	}
+	spas, err := s.Listers.GetStagePodAutoscalerLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, original := range spas {
+		spa := original.DeepCopy()
+		s.reconcile(spa, original, func() pkgreconciler.Event {
+			return s.StagePodAutoscalerReconciler.ReconcileKind(s.ctx, spa)
+		})
+		if equality.Semantic.DeepEqual(original.Status, spa.Status) {
+			continue
+		}
+		latest, err := s.Client.ServingV1().StagePodAutoscalers(spa.Namespace).Get(s.ctx, spa.Name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		latest.Status = spa.Status
+		if _, err = s.Client.ServingV1().StagePodAutoscalers(spa.Namespace).UpdateStatus(s.ctx, latest,
+			metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// simulateConfigurations simulates the Configuration controller. A ready revision is created for every generation
+// of the configuration.
+func (s *Simulation) simulateConfigurations() error {
+	configs, err := s.Listers.GetConfigurationLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		revName := kmeta.ChildName(config.Name, fmt.Sprintf("-%05d", config.Generation))
+		_, err = s.Listers.GetRevisionLister().Revisions(config.Namespace).Get(revName)
+		if apierrs.IsNotFound(err) {
+			if _, err = s.ServingClient.ServingV1().Revisions(config.Namespace).Create(s.ctx,
+				makeSimulatedRevision(config, revName), metav1.CreateOptions{}); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if config.Status.ObservedGeneration == config.Generation && config.Status.LatestReadyRevisionName == revName {
+			continue
+		}
+		config = config.DeepCopy()
+		config.Status.InitializeConditions()
+		config.Status.SetLatestCreatedRevisionName(revName)
+		config.Status.SetLatestReadyRevisionName(revName)
+		config.Status.ObservedGeneration = config.Generation
+		if _, err = s.ServingClient.ServingV1().Configurations(config.Namespace).UpdateStatus(s.ctx, config,
+			metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeSimulatedRevision creates the ready revision for the current generation of the configuration.
+func makeSimulatedRevision(config *servingv1.Configuration, revName string) *servingv1.Revision {
+	rev := &servingv1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revName,
+			Namespace: config.Namespace,
+			Labels: kmeta.UnionMaps(config.Spec.Template.Labels, map[string]string{
+				serving.ConfigurationLabelKey:           config.Name,
+				serving.ServiceLabelKey:                 config.Labels[serving.ServiceLabelKey],
+				serving.ConfigurationGenerationLabelKey: strconv.FormatInt(config.Generation, 10),
+			}),
+			Annotations:     kmeta.CopyMap(config.Spec.Template.Annotations),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(config)},
+		},
+		Spec: *config.Spec.Template.Spec.DeepCopy(),
+	}
+	rev.Status.InitializeConditions()
+	rev.Status.MarkResourcesAvailableTrue()
+	rev.Status.MarkContainerHealthyTrue()
+	rev.Status.MarkActiveTrue()
+	return rev
+}
+
+// simulateRoutes simulates the Route controller. The traffic in the spec of the route is applied to its status
+// right away, with the latest ready revisions resolved.
+func (s *Simulation) simulateRoutes() error {
+	routes, err := s.Listers.GetRouteLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, route := range routes {
+		traffic, ready := s.resolveTraffic(route)
+		if !ready {
+			continue
+		}
+		if route.Status.ObservedGeneration != route.Generation || !equality.Semantic.DeepEqual(route.Status.Traffic, traffic) {
+			route = route.DeepCopy()
+			route.Status.InitializeConditions()
+			route.Status.MarkTrafficAssigned()
+			route.Status.Traffic = traffic
+			route.Status.ObservedGeneration = route.Generation
+			if _, err = s.ServingClient.ServingV1().Routes(route.Namespace).UpdateStatus(s.ctx, route,
+				metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+		s.recordTraffic(route)
+	}
+	return nil
+}
+
+// resolveTraffic resolves the traffic targets of the route into revisions. It returns false, if the latest ready
+// revision of a configuration is not available yet.
+func (s *Simulation) resolveTraffic(route *servingv1.Route) ([]servingv1.TrafficTarget, bool) {
+	traffic := make([]servingv1.TrafficTarget, 0, len(route.Spec.Traffic))
+	for _, target := range route.Spec.Traffic {
+		target := *target.DeepCopy()
+		if target.RevisionName == "" {
+			config, err := s.Listers.GetConfigurationLister().Configurations(route.Namespace).Get(target.ConfigurationName)
+			if err != nil || config.Status.LatestReadyRevisionName == "" {
+				return nil, false
+			}
+			target.RevisionName = config.Status.LatestReadyRevisionName
+			target.ConfigurationName = ""
+			target.LatestRevision = ptr.Bool(true)
+		}
+		traffic = append(traffic, target)
+	}
+	return traffic, true
+}
+
+// recordTraffic keeps the traffic split of the route, if it has changed.
+func (s *Simulation) recordTraffic(route *servingv1.Route) {
+	split := map[string]int64{}
+	for _, target := range route.Status.Traffic {
+		if target.Percent != nil && *target.Percent > 0 {
+			split[target.RevisionName] += *target.Percent
+		}
+	}
+	for i := len(s.Traffic) - 1; i >= 0; i-- {
+		if s.Traffic[i].Route == route.Name {
+			if equality.Semantic.DeepEqual(s.Traffic[i].Traffic, split) {
+				return
+			}
+			break
+		}
+	}
+	s.Traffic = append(s.Traffic, TrafficSnapshot{Tick: s.tick, Route: route.Name, Traffic: split})
+}
+
+// TrafficSplits returns the sequence of the traffic splits applied to the route.
+func (s *Simulation) TrafficSplits(route string) []map[string]int64 {
+	var splits []map[string]int64
+	for _, snapshot := range s.Traffic {
+		if snapshot.Route == route {
+			splits = append(splits, snapshot.Traffic)
+		}
+	}
+	return splits
+}
+
+// simulateAutoscalers simulates the PodAutoscalers, the Deployments and the pods of all the revisions.
+func (s *Simulation) simulateAutoscalers() error {
+	revisions, err := s.Listers.GetRevisionLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Name < revisions[j].Name
+	})
+	for _, rev := range revisions {
+		if err = s.simulateAutoscaler(rev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// simulateAutoscaler scales the pods of the revision to the desired scale, once the scale up or the scale down
+// delay has passed. The terminating pods are gone after the termination delay.
+func (s *Simulation) simulateAutoscaler(rev *servingv1.Revision) error {
+	key := rev.Namespace + "/" + rev.Name
+	desired := s.desiredScale(rev)
+	change, found := s.scaling[key]
+	if !found || change.desired != desired {
+		change = &scaleChange{desired: desired, since: s.tick}
+		s.scaling[key] = change
+	}
+
+	pods, err := s.Listers.GetPodsLister().Pods(rev.Namespace).List(labels.SelectorFromSet(labels.Set{
+		serving.RevisionLabelKey: rev.Name,
+	}))
+	if err != nil {
+		return err
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	running := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil {
+			running = append(running, pod)
+			continue
+		}
+		podKey := pod.Namespace + "/" + pod.Name
+		if s.tick-s.terminating[podKey] >= s.TerminationDelay {
+			if err = s.KubeClient.CoreV1().Pods(pod.Namespace).Delete(s.ctx, pod.Name,
+				metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+				return err
+			}
+			delete(s.terminating, podKey)
+		}
+	}
+
+	actual := int32(len(running))
+	delay := s.ScaleUpDelay
+	if desired < actual {
+		delay = s.ScaleDownDelay
+	}
+	if actual != desired && s.tick-change.since >= delay {
+		for ; actual < desired; actual++ {
+			if err = s.createPod(rev); err != nil {
+				return err
+			}
+		}
+		for i := len(running) - 1; actual > desired; i-- {
+			if err = s.terminatePod(running[i]); err != nil {
+				return err
+			}
+			actual--
+		}
+	}
+
+	if err = s.updatePodAutoscaler(rev, desired, actual); err != nil {
+		return err
+	}
+	return s.updateDeployment(rev, desired, actual)
+}
+
+// desiredScale returns the number of replicas the revision needs for its share of the traffic, within the scale
+// bounds applied by the autoscaler. The revision not receiving traffic scales down to 0.
+func (s *Simulation) desiredScale(rev *servingv1.Revision) int32 {
+	routed, percent := false, int64(0)
+	routes, err := s.Listers.GetRouteLister().Routes(rev.Namespace).List(labels.Everything())
+	if err != nil {
+		return 0
+	}
+	for _, route := range routes {
+		for _, target := range route.Status.Traffic {
+			if target.RevisionName == rev.Name && target.Percent != nil {
+				routed = true
+				percent += *target.Percent
+			}
+		}
+	}
+	if !routed {
+		return 0
+	}
+
+	minScale, maxScale := readScaleAnnotation(rev, autoscaling.MinScaleAnnotationKey),
+		readScaleAnnotation(rev, autoscaling.MaxScaleAnnotationKey)
+	spa, err := s.Listers.GetStagePodAutoscalerLister().StagePodAutoscalers(rev.Namespace).Get(rev.Name)
+	if err == nil && spa.Status.EffectiveMinScale != nil && spa.Status.EffectiveMaxScale != nil {
+		// The StagePodAutoscaler reports the scale bounds the autoscaler applies for the current stage.
+		minScale, maxScale = *spa.Status.EffectiveMinScale, *spa.Status.EffectiveMaxScale
+	}
+
+	desired := int32((int64(s.Demand)*percent + 99) / 100)
+	if desired < minScale {
+		desired = minScale
+	}
+	if maxScale > 0 && desired > maxScale {
+		desired = maxScale
+	}
+	return desired
+}
+
+// readScaleAnnotation returns the value of the scale annotation of the revision, or 0 if it is not set.
+func readScaleAnnotation(rev *servingv1.Revision, key string) int32 {
+	value, err := strconv.ParseInt(rev.Annotations[key], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(value)
+}
+
+// simulatedLabels returns the labels of the resources created for the revision.
+func simulatedLabels(rev *servingv1.Revision) map[string]string {
+	return map[string]string{
+		serving.RevisionLabelKey:      rev.Name,
+		serving.ServiceLabelKey:       rev.Labels[serving.ServiceLabelKey],
+		serving.ConfigurationLabelKey: rev.Labels[serving.ConfigurationLabelKey],
+	}
+}
+
+// createPod creates a ready pod for the revision.
+func (s *Simulation) createPod(rev *servingv1.Revision) error {
+	key := rev.Namespace + "/" + rev.Name
+	s.podCount[key]++
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-deployment-%d", rev.Name, s.podCount[key]),
+			Namespace: rev.Namespace,
+			Labels:    simulatedLabels(rev),
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+			}},
+		},
+	}
+	_, err := s.KubeClient.CoreV1().Pods(rev.Namespace).Create(s.ctx, pod, metav1.CreateOptions{})
+	return err
+}
+
+// terminatePod starts the termination of the pod with the grace period of the simulation.
+func (s *Simulation) terminatePod(pod *corev1.Pod) error {
+	pod = pod.DeepCopy()
+	pod.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(s.TerminationGracePeriod)}
+	pod.DeletionGracePeriodSeconds = ptr.Int64(max(int64(s.TerminationGracePeriod/time.Second), 0))
+	if _, err := s.KubeClient.CoreV1().Pods(pod.Namespace).Update(s.ctx, pod, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	s.terminating[pod.Namespace+"/"+pod.Name] = s.tick
+	return nil
+}
+
+// updatePodAutoscaler creates or updates the PodAutoscaler of the revision with the desired and actual scales.
+func (s *Simulation) updatePodAutoscaler(rev *servingv1.Revision, desired, actual int32) error {
+	pa, err := s.Listers.GetPodAutoscalerLister().PodAutoscalers(rev.Namespace).Get(rev.Name)
+	if apierrs.IsNotFound(err) {
+		pa, err = s.ServingClient.AutoscalingV1alpha1().PodAutoscalers(rev.Namespace).Create(s.ctx,
+			&autoscalingv1alpha1.PodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            rev.Name,
+					Namespace:       rev.Namespace,
+					Labels:          simulatedLabels(rev),
+					Annotations:     kmeta.CopyMap(rev.Annotations),
+					OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
+				},
+				Spec: autoscalingv1alpha1.PodAutoscalerSpec{
+					ScaleTargetRef: corev1.ObjectReference{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       kmeta.ChildName(rev.Name, "-deployment"),
+					},
+				},
+			}, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	if pa.Status.DesiredScale != nil && *pa.Status.DesiredScale == desired &&
+		pa.Status.ActualScale != nil && *pa.Status.ActualScale == actual {
+		return nil
+	}
+	pa = pa.DeepCopy()
+	pa.Status.DesiredScale, pa.Status.ActualScale = ptr.Int32(desired), ptr.Int32(actual)
+	_, err = s.ServingClient.AutoscalingV1alpha1().PodAutoscalers(rev.Namespace).UpdateStatus(s.ctx, pa,
+		metav1.UpdateOptions{})
+	return err
+}
+
+// updateDeployment creates or updates the Deployment of the revision with the desired and actual scales.
+func (s *Simulation) updateDeployment(rev *servingv1.Revision, desired, actual int32) error {
+	name := kmeta.ChildName(rev.Name, "-deployment")
+	deployment, err := s.Listers.GetDeploymentLister().Deployments(rev.Namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: rev.Namespace,
+				Labels:    simulatedLabels(rev),
+			},
+		}
+	} else if err != nil {
+		return err
+	} else {
+		deployment = deployment.DeepCopy()
+	}
+	existing := deployment.DeepCopy()
+	deployment.Spec.Replicas = ptr.Int32(desired)
+	deployment.Status.Replicas = actual
+	deployment.Status.ReadyReplicas = actual
+	deployment.Status.AvailableReplicas = actual
+	deployment.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type:   appsv1.DeploymentAvailable,
+		Status: corev1.ConditionTrue,
+	}}
+	if apierrs.IsNotFound(err) {
+		_, err = s.KubeClient.AppsV1().Deployments(rev.Namespace).Create(s.ctx, deployment, metav1.CreateOptions{})
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing, deployment) {
+		return nil
+	}
+	_, err = s.KubeClient.AppsV1().Deployments(rev.Namespace).Update(s.ctx, deployment, metav1.UpdateOptions{})
+	return err
+}
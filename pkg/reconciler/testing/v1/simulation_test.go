@@ -0,0 +1,391 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+	logtesting "knative.dev/pkg/logging/testing"
+	_ "knative.dev/pkg/system/testing"
+	sprv1 "knative.dev/serving-progressive-rollout/pkg/apis/serving/v1"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutgroup"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/rolloutorchestrator"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/service/resources"
+	"knative.dev/serving-progressive-rollout/pkg/reconciler/stagepodautoscaler"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+const (
+	testNamespace   = "default"
+	testServiceName = "test"
+)
+
+// fullRollout is the sequence of the traffic splits, when the service with 5 replicas rolls out the new revision.
+var fullRollout = []map[string]int64{
+	{"test-00001": 100},
+	{"test-00001": 80, "test-00002": 20},
+	{"test-00001": 60, "test-00002": 40},
+	{"test-00001": 40, "test-00002": 60},
+	{"test-00001": 20, "test-00002": 80},
+	{"test-00002": 100},
+}
+
+func newTestSimulation(t *testing.T) *Simulation {
+	s := NewSimulation(logtesting.TestContextWithLogger(t))
+	serviceReconciler := service.NewReconciler(s.Client, s.ServingClient, s.Listers.GetConfigurationLister(),
+		s.Listers.GetRevisionLister(), s.Listers.GetRouteLister(), s.Listers.GetRolloutOrchestratorLister(),
+		s.Listers.GetPodAutoscalerLister(), s.Listers.GetStagePodAutoscalerLister(), s.Listers.GetConfigMapLister(),
+		s.Listers.GetDeploymentLister())
+	serviceReconciler.SetEnqueueAfter(s.EnqueueAfter)
+	roReconciler := rolloutorchestrator.NewReconciler(s.Client, s.KubeClient, s.Listers.GetStagePodAutoscalerLister(),
+		s.Listers.GetDeploymentLister(), s.Listers.GetRevisionLister(), s.Listers.GetPodDisruptionBudgetLister())
+	roReconciler.SetEnqueueAfter(s.EnqueueAfter)
+	s.ServiceReconciler = serviceReconciler
+	s.RolloutOrchestratorReconciler = roReconciler
+	s.StagePodAutoscalerReconciler = stagepodautoscaler.NewReconciler(s.ServingClient,
+		s.Listers.GetPodAutoscalerLister(), s.Listers.GetPodsLister())
+	s.Demand = 5
+	return s
+}
+
+// newTestService creates the service with 5 replicas, and the rollout configured by the annotations of the template.
+func newTestService(annotations map[string]string) *servingv1.Service {
+	return &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Spec: servingv1.ServiceSpec{
+			ConfigurationSpec: servingv1.ConfigurationSpec{
+				Template: servingv1.RevisionTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: kmeta.UnionMaps(annotations, map[string]string{
+							autoscaling.MinScaleAnnotationKey: "5",
+							autoscaling.MaxScaleAnnotationKey: "5",
+						}),
+					},
+					Spec: servingv1.RevisionSpec{
+						PodSpec: corev1.PodSpec{
+							Containers: []corev1.Container{{Image: "image:1"}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// startRollout creates the service, waits for its first revision to be ready, and changes the image of the service
+// to roll out the second revision.
+func startRollout(t *testing.T, s *Simulation, annotations map[string]string) {
+	if err := s.CreateService(newTestService(annotations)); err != nil {
+		t.Fatalf("CreateService() returned error: %v", err)
+	}
+	if err := s.RunUntil(rolloutComplete(s, "test-00001"), 20); err != nil {
+		t.Fatalf("The first revision was not ready: %v", err)
+	}
+	if err := s.UpdateService(testNamespace, testServiceName, func(service *servingv1.Service) {
+		service.Spec.Template.Spec.Containers[0].Image = "image:2"
+	}); err != nil {
+		t.Fatalf("UpdateService() returned error: %v", err)
+	}
+}
+
+// rolloutComplete returns the condition that the rollout of the revision is complete.
+func rolloutComplete(s *Simulation, revName string) func() bool {
+	return func() bool {
+		ro, err := s.RolloutOrchestrator(testNamespace, testServiceName)
+		return err == nil && ro.IsReady() && len(ro.Spec.TargetRevisions) == 1 &&
+			ro.Spec.TargetRevisions[0].RevisionName == revName
+	}
+}
+
+// countEvents returns the number of the events with the reason, and the messages of the events.
+func countEvents(events []string, reason string) (int, []string) {
+	var messages []string
+	for _, event := range events {
+		if fields := strings.Fields(event); len(fields) > 2 && fields[1] == reason {
+			messages = append(messages, strings.Join(fields[2:], " "))
+		}
+	}
+	return len(messages), messages
+}
+
+func TestSimulationRollout(t *testing.T) {
+	tests := []struct {
+		name                    string
+		annotations             map[string]string
+		scaleUpDelay            int
+		scaleDownDelay          int
+		terminationDelay        int
+		terminationGracePeriod  time.Duration
+		ExpectedTraffic         []map[string]int64
+		ExpectedForcedDeletions int
+	}{{
+		name:                   "Test the availability strategy",
+		scaleUpDelay:           2,
+		scaleDownDelay:         1,
+		terminationDelay:       1,
+		terminationGracePeriod: 30 * time.Second,
+		ExpectedTraffic:        fullRollout,
+	}, {
+		name:                   "Test the resourceUtil strategy",
+		annotations:            map[string]string{resources.ProgressiveRolloutStrategy: "resourceUtil"},
+		scaleUpDelay:           2,
+		scaleDownDelay:         1,
+		terminationDelay:       1,
+		terminationGracePeriod: 30 * time.Second,
+		ExpectedTraffic:        fullRollout,
+		// The terminating pod of the old revision is force-deleted in the first stage of the resourceUtil strategy.
+		ExpectedForcedDeletions: 1,
+	}, {
+		name:                    "Test the terminating pods exceeding the grace period are force-deleted",
+		terminationDelay:        100,
+		terminationGracePeriod:  -time.Second,
+		ExpectedTraffic:         fullRollout,
+		ExpectedForcedDeletions: 5,
+	}, {
+		name:                   "Test the graceful pod termination policy waits for the terminating pods",
+		annotations:            map[string]string{resources.PodTerminationPolicy: "graceful"},
+		terminationDelay:       3,
+		terminationGracePeriod: -time.Second,
+		ExpectedTraffic:        fullRollout,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestSimulation(t)
+			startRollout(t, s, test.annotations)
+			s.ScaleUpDelay, s.ScaleDownDelay = test.scaleUpDelay, test.scaleDownDelay
+			s.TerminationDelay, s.TerminationGracePeriod = test.terminationDelay, test.terminationGracePeriod
+			if err := s.RunUntil(rolloutComplete(s, "test-00002"), 200); err != nil {
+				t.Fatalf("The rollout was not complete: %v, traffic splits %v", err, s.TrafficSplits(testServiceName))
+			}
+			if splits := s.TrafficSplits(testServiceName); !reflect.DeepEqual(splits, test.ExpectedTraffic) {
+				t.Fatalf("Result of TrafficSplits() = %v, want %v", splits, test.ExpectedTraffic)
+			}
+			if count, _ := countEvents(s.Events, "PodForceDeleted"); count != test.ExpectedForcedDeletions {
+				t.Fatalf("Result of the forced deletions = %v, want %v", count, test.ExpectedForcedDeletions)
+			}
+		})
+	}
+}
+
+func TestSimulationStageTimeout(t *testing.T) {
+	tests := []struct {
+		name                 string
+		annotations          map[string]string
+		scaleUpDelay         int
+		terminationDelay     int
+		ExpectedTraffic      []map[string]int64
+		ExpectedScaleUpReady bool
+		ExpectedStageFailed  bool
+		ExpectedError        string
+	}{{
+		name:                 "Test the new revision unable to scale up before the timeout",
+		scaleUpDelay:         1000,
+		ExpectedTraffic:      fullRollout[:1],
+		ExpectedScaleUpReady: false,
+		ExpectedStageFailed:  true,
+		ExpectedError:        "the deployment for the revision test-00002 was not ready, when the timeout limit hit",
+	}, {
+		name:                 "Test the old revision stuck on terminating pods before the timeout",
+		annotations:          map[string]string{resources.PodTerminationPolicy: "graceful"},
+		terminationDelay:     1000,
+		ExpectedTraffic:      fullRollout[:2],
+		ExpectedScaleUpReady: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestSimulation(t)
+			startRollout(t, s, test.annotations)
+			s.ScaleUpDelay, s.TerminationDelay = test.scaleUpDelay, test.terminationDelay
+			if err := s.Run(10); err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+			if err := s.ExpireStage(testNamespace, testServiceName); err != nil {
+				t.Fatalf("ExpireStage() returned error: %v", err)
+			}
+			if err := s.Run(10); err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+
+			if splits := s.TrafficSplits(testServiceName); !reflect.DeepEqual(splits, test.ExpectedTraffic) {
+				t.Fatalf("Result of TrafficSplits() = %v, want %v", splits, test.ExpectedTraffic)
+			}
+			ro, err := s.RolloutOrchestrator(testNamespace, testServiceName)
+			if err != nil {
+				t.Fatalf("RolloutOrchestrator() returned error: %v", err)
+			}
+			if ro.IsStageReady() || ro.IsReady() {
+				t.Fatalf("Result of IsStageReady() = %v, want false", ro.IsStageReady())
+			}
+			if ready := ro.IsStageScaleUpReady(); ready != test.ExpectedScaleUpReady {
+				t.Fatalf("Result of IsStageScaleUpReady() = %v, want %v", ready, test.ExpectedScaleUpReady)
+			}
+			if failed := ro.IsStageFailed(); failed != test.ExpectedStageFailed {
+				t.Fatalf("Result of IsStageFailed() = %v, want %v", failed, test.ExpectedStageFailed)
+			}
+			_, messages := countEvents(s.Events, "InternalError")
+			if test.ExpectedError == "" && len(messages) != 0 {
+				t.Fatalf("Result of the errors = %v, want none", messages)
+			}
+			if test.ExpectedError != "" && (len(messages) == 0 || !strings.Contains(messages[0], test.ExpectedError)) {
+				t.Fatalf("Result of the errors = %v, want %v", messages, test.ExpectedError)
+			}
+			if now := metav1.Now(); ro.Spec.TargetFinishTime.Inner.Before(&now) && test.ExpectedError == "" {
+				t.Fatalf("Result of TargetFinishTime = %v, want a time after %v", ro.Spec.TargetFinishTime.Inner, now)
+			}
+		})
+	}
+}
+
+func TestSimulationStageHold(t *testing.T) {
+	tests := []struct {
+		name                   string
+		annotations            map[string]string
+		ExpectedTraffic        []map[string]int64
+		ExpectedExpiredTraffic []map[string]int64
+	}{{
+		name:                   "Test the ready stage held until its deadline without the fast advance",
+		annotations:            map[string]string{resources.StageFastAdvanceEnabled: "false"},
+		ExpectedTraffic:        fullRollout[:2],
+		ExpectedExpiredTraffic: fullRollout[:3],
+	}, {
+		name:                   "Test the ready stage held by the minimum soak",
+		annotations:            map[string]string{resources.StageMinSoakSeconds: "3600"},
+		ExpectedTraffic:        fullRollout[:2],
+		ExpectedExpiredTraffic: fullRollout[:2],
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestSimulation(t)
+			startRollout(t, s, test.annotations)
+			if err := s.Run(20); err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+			if splits := s.TrafficSplits(testServiceName); !reflect.DeepEqual(splits, test.ExpectedTraffic) {
+				t.Fatalf("Result of TrafficSplits() = %v, want %v", splits, test.ExpectedTraffic)
+			}
+			ro, err := s.RolloutOrchestrator(testNamespace, testServiceName)
+			if err != nil {
+				t.Fatalf("RolloutOrchestrator() returned error: %v", err)
+			}
+			if !ro.IsStageReady() {
+				t.Fatalf("Result of IsStageReady() = %v, want true", ro.IsStageReady())
+			}
+
+			if err := s.ExpireStage(testNamespace, testServiceName); err != nil {
+				t.Fatalf("ExpireStage() returned error: %v", err)
+			}
+			if err := s.Run(20); err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+			if splits := s.TrafficSplits(testServiceName); !reflect.DeepEqual(splits, test.ExpectedExpiredTraffic) {
+				t.Fatalf("Result of TrafficSplits() = %v, want %v", splits, test.ExpectedExpiredTraffic)
+			}
+		})
+	}
+}
+
+func TestSimulationRolloutGroupFailure(t *testing.T) {
+	tests := []struct {
+		name               string
+		policy             string
+		ExpectedAnnotation string
+		ExpectedPhases     map[string]string
+	}{{
+		name:               "Test the member timing out holds the group",
+		policy:             sprv1.RolloutGroupFailurePolicyHold,
+		ExpectedAnnotation: resources.RolloutGroupHold,
+		ExpectedPhases: map[string]string{
+			testServiceName: sprv1.RolloutGroupMemberFailed,
+			"other":         sprv1.RolloutGroupMemberHeld,
+		},
+	}, {
+		name:               "Test the member timing out rolls back the group",
+		policy:             sprv1.RolloutGroupFailurePolicyRollback,
+		ExpectedAnnotation: resources.RolloutGroupRollback,
+		ExpectedPhases: map[string]string{
+			testServiceName: sprv1.RolloutGroupMemberFailed,
+			"other":         sprv1.RolloutGroupMemberRollingBack,
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestSimulation(t)
+			s.RolloutGroupReconciler = rolloutgroup.NewReconciler(s.Client, s.Listers.GetRolloutOrchestratorLister(),
+				s.Listers.GetConfigurationLister())
+			other := newTestService(nil)
+			other.Name = "other"
+			if err := s.CreateService(other); err != nil {
+				t.Fatalf("CreateService() returned error: %v", err)
+			}
+			if _, err := s.Client.ServingV1().RolloutGroups(testNamespace).Create(s.ctx, &sprv1.RolloutGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: testNamespace},
+				Spec: sprv1.RolloutGroupSpec{
+					Members:       []sprv1.RolloutGroupMember{{ServiceName: testServiceName}, {ServiceName: "other"}},
+					FailurePolicy: test.policy,
+				},
+			}, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("Create() returned error: %v", err)
+			}
+			startRollout(t, s, nil)
+			if err := s.UpdateService(testNamespace, "other", func(service *servingv1.Service) {
+				service.Spec.Template.Spec.Containers[0].Image = "image:2"
+			}); err != nil {
+				t.Fatalf("UpdateService() returned error: %v", err)
+			}
+			s.ScaleUpDelay = 1000
+			if err := s.Run(10); err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+			if err := s.ExpireStage(testNamespace, testServiceName); err != nil {
+				t.Fatalf("ExpireStage() returned error: %v", err)
+			}
+			if err := s.Run(10); err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+
+			rg, err := s.Client.ServingV1().RolloutGroups(testNamespace).Get(s.ctx, "group", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Get() returned error: %v", err)
+			}
+			phases := make(map[string]string, len(rg.Status.MemberStatuses))
+			for _, status := range rg.Status.MemberStatuses {
+				phases[status.ServiceName] = status.Phase
+			}
+			if !reflect.DeepEqual(phases, test.ExpectedPhases) {
+				t.Fatalf("Result of the member phases = %v, want %v", phases, test.ExpectedPhases)
+			}
+			ro, err := s.RolloutOrchestrator(testNamespace, "other")
+			if err != nil {
+				t.Fatalf("RolloutOrchestrator() returned error: %v", err)
+			}
+			if got := ro.Annotations[test.ExpectedAnnotation]; got != "group" {
+				t.Fatalf("Result of the annotation %s = %q, want %q", test.ExpectedAnnotation, got, "group")
+			}
+		})
+	}
+}
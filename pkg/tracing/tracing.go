@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing traces a whole rollout with OpenTelemetry across the reconcilers in the controller and the
+// autoscaler. The trace context is kept in the annotations of the RolloutOrchestrator and the
+// StagePodAutoscalers, because a rollout spans many reconciliations in two binaries.
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TracerName is the name of the tracer of the rollouts.
+	TracerName = "knative.dev/serving-progressive-rollout"
+
+	// TraceIDAnnotation is the annotation key of the RolloutOrchestrator, that saves the ID of the trace of the
+	// current rollout.
+	TraceIDAnnotation = "rollout.knative.dev/trace-id"
+
+	// TraceParentAnnotation is the annotation key of the RolloutOrchestrator, that saves the W3C traceparent of
+	// the span of the current rollout.
+	TraceParentAnnotation = "rollout.knative.dev/trace-parent"
+
+	// StageTraceParentAnnotation is the annotation key of the RolloutOrchestrator and the StagePodAutoscalers,
+	// that saves the W3C traceparent of the span of the current stage.
+	StageTraceParentAnnotation = "rollout.knative.dev/stage-trace-parent"
+
+	traceParentKey = "traceparent"
+
+	// maxSpanAge is the age, after which a span held by Spans is ended, even if its rollout or stage has not
+	// finished, e.g. because the RolloutOrchestrator has been deleted in the middle of the rollout.
+	maxSpanAge = 24 * time.Hour
+)
+
+// Tracer returns the tracer of the rollouts from the global TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start starts the span with the span saved in the annotation key as the parent. If the annotation is not set,
+// the span is not recorded, so that only the rollouts being traced produce the spans.
+func Start(ctx context.Context, annotations map[string]string, key, name string,
+	opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	traceParent, found := annotations[key]
+	if !found {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	ctx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{traceParentKey: traceParent})
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// TraceParent returns the W3C traceparent of the span in the context. It is empty, if the span is not valid,
+// e.g. the tracing is disabled.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier[traceParentKey]
+}
+
+// SetAnnotation sets the annotation key to the value, or removes it, if the value is empty.
+func SetAnnotation(annotations map[string]string, key, value string) {
+	if value == "" {
+		delete(annotations, key)
+		return
+	}
+	annotations[key] = value
+}
+
+// Spans holds the spans of the rollouts and the stages in progress by their W3C traceparent, so that they end
+// when the rollout or the stage finishes, rather than when they start. The spans held are lost, when the
+// controller restarts or loses the leadership.
+type Spans struct {
+	mu    sync.Mutex
+	spans map[string]heldSpan
+}
+
+type heldSpan struct {
+	span  trace.Span
+	start time.Time
+}
+
+// NewSpans creates an empty Spans.
+func NewSpans() *Spans {
+	return &Spans{spans: make(map[string]heldSpan)}
+}
+
+// Hold holds the span started in the context until End is called with its traceparent, which is returned. The
+// spans held for longer than maxSpanAge are ended.
+func (s *Spans) Hold(ctx context.Context, span trace.Span) string {
+	traceParent := TraceParent(ctx)
+	if s == nil || traceParent == "" || !span.IsRecording() {
+		span.End()
+		return traceParent
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, held := range s.spans {
+		if now.Sub(held.start) > maxSpanAge {
+			held.span.End()
+			delete(s.spans, key)
+		}
+	}
+	s.spans[traceParent] = heldSpan{span: span, start: now}
+	return traceParent
+}
+
+// End ends the span held with the traceparent. It does nothing, if no such span is held.
+func (s *Spans) End(traceParent string) {
+	if s == nil || traceParent == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if held, found := s.spans[traceParent]; found {
+		held.span.End()
+		delete(s.spans, traceParent)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestStart(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	parentCtx, parent := Tracer().Start(context.Background(), "rollout")
+	parent.End()
+	annotations := map[string]string{TraceParentAnnotation: TraceParent(parentCtx)}
+
+	_, span := Start(context.Background(), annotations, TraceParentAnnotation, "stage")
+	span.End()
+	if span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Fatalf("Trace ID of Start() = %v, want %v", span.SpanContext().TraceID(), parent.SpanContext().TraceID())
+	}
+
+	// The span is not recorded without the annotation.
+	_, span = Start(context.Background(), nil, StageTraceParentAnnotation, "step")
+	span.End()
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Fatal("Start() without the annotation returned a recorded span")
+	}
+	if len(recorder.Ended()) != 2 {
+		t.Fatalf("Number of the spans ended = %v, want %v", len(recorder.Ended()), 2)
+	}
+}
+
+func TestSetAnnotation(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		ExpectedResult map[string]string
+	}{{
+		name:  "Test the value set",
+		value: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		ExpectedResult: map[string]string{
+			"other":               "value",
+			TraceParentAnnotation: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+	}, {
+		name:           "Test the empty value",
+		ExpectedResult: map[string]string{"other": "value"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			annotations := map[string]string{"other": "value", TraceParentAnnotation: "previous"}
+			SetAnnotation(annotations, TraceParentAnnotation, test.value)
+			if !reflect.DeepEqual(annotations, test.ExpectedResult) {
+				t.Fatalf("Result of SetAnnotation() = %v, want %v", annotations, test.ExpectedResult)
+			}
+		})
+	}
+}
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	spans := NewSpans()
+	ctx, span := Tracer().Start(context.Background(), "stage")
+	traceParent := spans.Hold(ctx, span)
+	if traceParent != TraceParent(ctx) {
+		t.Fatalf("Result of Hold() = %v, want %v", traceParent, TraceParent(ctx))
+	}
+	if len(recorder.Ended()) != 0 {
+		t.Fatalf("Number of the spans ended after Hold() = %v, want %v", len(recorder.Ended()), 0)
+	}
+
+	spans.End(traceParent)
+	spans.End(traceParent)
+	if len(recorder.Ended()) != 1 {
+		t.Fatalf("Number of the spans ended after End() = %v, want %v", len(recorder.Ended()), 1)
+	}
+
+	// The spans held for too long end, when another span is held.
+	_, stale := Tracer().Start(context.Background(), "stale")
+	spans.spans["stale"] = heldSpan{span: stale, start: time.Now().Add(-maxSpanAge - time.Minute)}
+	ctx, span = Tracer().Start(context.Background(), "next")
+	spans.Hold(ctx, span)
+	if len(recorder.Ended()) != 2 || recorder.Ended()[1].Name() != "stale" {
+		t.Fatalf("Number of the spans ended after Hold() = %v, want %v", len(recorder.Ended()), 2)
+	}
+}